@@ -0,0 +1,18 @@
+//go:build !windows
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/hectorgimenez/koolo/internal/cli"
+)
+
+// serve needs the game running, only the offline commands are available on other platforms
+func serve(serveOptions) {
+	fmt.Fprintln(os.Stderr, "koolo can only run the bots on Windows, the other commands work on any platform:")
+	fmt.Fprintln(os.Stderr)
+	cli.Usage(os.Stderr)
+	os.Exit(1)
+}
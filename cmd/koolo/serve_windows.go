@@ -0,0 +1,251 @@
+//go:build windows
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"log/slog"
+	_ "net/http/pprof"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime/debug"
+	"syscall"
+	"time"
+
+	sloggger "github.com/hectorgimenez/koolo/cmd/koolo/log"
+	"github.com/hectorgimenez/koolo/internal/bot"
+	"github.com/hectorgimenez/koolo/internal/config"
+	"github.com/hectorgimenez/koolo/internal/event"
+	"github.com/hectorgimenez/koolo/internal/remote/discord"
+	"github.com/hectorgimenez/koolo/internal/remote/droplog"
+	"github.com/hectorgimenez/koolo/internal/remote/journal"
+	"github.com/hectorgimenez/koolo/internal/remote/metrics"
+	"github.com/hectorgimenez/koolo/internal/remote/stashindex"
+	"github.com/hectorgimenez/koolo/internal/remote/statslog"
+	"github.com/hectorgimenez/koolo/internal/remote/telegram"
+	"github.com/hectorgimenez/koolo/internal/remote/webhook"
+	"github.com/hectorgimenez/koolo/internal/server"
+	"github.com/hectorgimenez/koolo/internal/utils"
+	"github.com/hectorgimenez/koolo/internal/utils/winproc"
+	"github.com/inkeliz/gowebview"
+	"golang.org/x/sync/errgroup"
+)
+
+// notifierOptions queues events for remote notifiers, dropping the oldest ones when the remote service is too slow
+// instead of slowing down the bots
+func notifierOptions(name string) event.SubscriberOptions {
+	return event.SubscriberOptions{Name: name, BufferSize: 64, Policy: event.DropOldest, Timeout: 30 * time.Second}
+}
+
+// wrapWithRecover wraps a function with panic recovery logic
+func wrapWithRecover(logger *slog.Logger, f func() error) func() error {
+	return func() error {
+		defer func() {
+			if r := recover(); r != nil {
+				stackTrace := debug.Stack()
+				errMsg := fmt.Sprintf("panic recovered: %v\nStacktrace: %s", r, stackTrace)
+				logger.Error(errMsg)
+				sloggger.FlushLog()
+			}
+		}()
+		return f()
+	}
+}
+
+// serve runs the bots and the local HTTP server, with the UI window unless running headless
+func serve(opts serveOptions) {
+	err := config.Load()
+	if err != nil {
+		if !opts.headless {
+			utils.ShowDialog("Error loading configuration", err.Error())
+		}
+		log.Fatalf("Error loading configuration: %s", err.Error())
+		return
+	}
+
+	logger, err := sloggger.NewLogger(config.Koolo.Debug.Log, config.Koolo.LogSaveDirectory, "")
+	if err != nil {
+		log.Fatalf("Error starting logger: %s", err.Error())
+	}
+	defer sloggger.FlushAndClose()
+
+	for name, cfg := range config.GetCharacters() {
+		for _, verr := range cfg.Runtime.ValidationErrors.Errors() {
+			logger.Warn("Invalid character config", slog.String("supervisor", name), slog.String("field", verr.Field), slog.String("error", verr.Message))
+		}
+		for _, verr := range cfg.Runtime.ValidationErrors.Warnings() {
+			logger.Warn("Character config warning", slog.String("supervisor", name), slog.String("field", verr.Field), slog.String("warning", verr.Message))
+		}
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("fatal error detected, Koolo will close with the following error: %v\n Stacktrace: %s", r, debug.Stack())
+			logger.Error(err.Error())
+			sloggger.FlushAndClose()
+			if opts.headless {
+				return
+			}
+			utils.ShowDialog("Koolo error :(", fmt.Sprintf("Koolo will close due to an expected error, please check the latest log file for more info!\n %s", err.Error()))
+		}
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	g, ctx := errgroup.WithContext(ctx)
+
+	winproc.SetProcessDpiAware.Call() // Set DPI awareness to be able to read the correct scale and show the window correctly
+
+	eventListener := event.NewListener(logger)
+
+	// Centralized droplog writer registration
+	dropBase := config.Koolo.LogSaveDirectory
+	if dropBase == "" {
+		dropBase = "logs"
+	}
+	dropDir := filepath.Join(dropBase, "droplogs")
+	dropWriter := droplog.NewWriter(dropDir, logger)
+	eventListener.RegisterWith(dropWriter.Handle, event.SubscriberOptions{Name: "droplog"})
+
+	// Game and run history, persisted so stats survive restarts
+	statsWriter := statslog.NewWriter(statslog.Dir(), logger)
+	eventListener.RegisterWith(statsWriter.Handle, event.SubscriberOptions{Name: "statslog"})
+
+	// Last stash content of every character, searchable from the local server
+	stashWriter := stashindex.NewWriter(stashindex.Dir(), logger)
+	eventListener.RegisterWith(stashWriter.Handle, event.SubscriberOptions{Name: "stashindex"})
+
+	// Every event, so the logs above can be rebuilt and notifications reproduced with "koolo journal replay"
+	journalWriter := journal.NewWriter(journal.Dir(), journal.Options{
+		Screenshots:   config.Koolo.Journal.Screenshots,
+		RetentionDays: config.Koolo.Journal.RetentionDays,
+	}, logger)
+	eventListener.RegisterWith(journalWriter.Handle, event.SubscriberOptions{Name: "journal"})
+
+	// Prometheus counters, exposed by the local server at /metrics
+	metricsCollector := metrics.NewCollector()
+	eventListener.RegisterWith(metricsCollector.Handle, event.SubscriberOptions{Name: "metrics"})
+	manager := bot.NewSupervisorManager(logger, eventListener)
+	scheduler := bot.NewScheduler(manager, logger)
+	go scheduler.Start()
+	srv, err := server.New(logger, manager, metricsCollector)
+	if err != nil {
+		log.Fatalf("Error starting local server: %s", err.Error())
+	}
+
+	// Use wrapWithRecover for all goroutines to handle panics
+	if opts.headless {
+		logger.Info(fmt.Sprintf("Running headless, the UI is available at http://localhost:%d", opts.port))
+	} else {
+		g.Go(wrapWithRecover(logger, func() error {
+			defer cancel()
+			displayScale := config.GetCurrentDisplayScale()
+			w, err := gowebview.New(&gowebview.Config{URL: fmt.Sprintf("http://localhost:%d", opts.port), WindowConfig: &gowebview.WindowConfig{
+				Title: "Koolo",
+				Size: &gowebview.Point{
+					X: int64(1040 * displayScale),
+					Y: int64(720 * displayScale),
+				},
+			}})
+			if err != nil {
+				w.Destroy()
+				return fmt.Errorf("error creating webview: %w", err)
+			}
+
+			w.SetSize(&gowebview.Point{
+				X: int64(1040 * displayScale),
+				Y: int64(720 * displayScale),
+			}, gowebview.HintFixed)
+
+			defer w.Destroy()
+			w.Run()
+
+			return nil
+		}))
+	}
+
+	// Discord Bot initialization
+	if config.Koolo.Discord.Enabled {
+		discordBot, err := discord.NewBot(config.Koolo.Discord.Token, config.Koolo.Discord.ChannelID, manager)
+		if err != nil {
+			logger.Error("Discord could not been initialized", slog.Any("error", err))
+			return
+		}
+
+		eventListener.RegisterWith(discordBot.Handle, notifierOptions("discord"))
+		g.Go(wrapWithRecover(logger, func() error {
+			return discordBot.Start(ctx)
+		}))
+	}
+
+	// Telegram Bot initialization
+	if config.Koolo.Telegram.Enabled {
+		telegramBot, err := telegram.NewBot(config.Koolo.Telegram.Token, config.Koolo.Telegram.ChatID, logger)
+		if err != nil {
+			logger.Error("Telegram could not been initialized", slog.Any("error", err))
+			return
+		}
+
+		eventListener.RegisterWith(telegramBot.Handle, notifierOptions("telegram"))
+		g.Go(wrapWithRecover(logger, func() error {
+			return telegramBot.Start(ctx)
+		}))
+	}
+
+	// Generic webhooks initialization
+	for _, wh := range config.Koolo.Webhooks {
+		if !wh.Enabled {
+			continue
+		}
+
+		notifier, err := webhook.NewNotifier(wh, logger)
+		if err != nil {
+			logger.Error("Webhook could not been initialized", slog.String("webhook", wh.Name), slog.Any("error", err))
+			continue
+		}
+
+		eventListener.RegisterWith(notifier.Handle, notifierOptions("webhook-"+wh.Name))
+		g.Go(wrapWithRecover(logger, func() error {
+			return notifier.Start(ctx)
+		}))
+	}
+
+	g.Go(wrapWithRecover(logger, func() error {
+		defer cancel()
+		return srv.Listen(opts.port)
+	}))
+
+	g.Go(wrapWithRecover(logger, func() error {
+		defer cancel()
+		return eventListener.Listen(ctx)
+	}))
+
+	g.Go(wrapWithRecover(logger, func() error {
+		<-ctx.Done()
+		logger.Info("Koolo shutting down...")
+		cancel()
+		manager.StopAll()
+		scheduler.Stop()
+		err = srv.Stop()
+		if err != nil {
+			logger.Error("error stopping local server", slog.Any("error", err))
+		}
+
+		return err
+	}))
+
+	err = g.Wait()
+	if err != nil {
+		cancel()
+		logger.Error("Error running Koolo", slog.Any("error", err))
+		return
+	}
+
+	sloggger.FlushAndClose()
+}
@@ -29,9 +29,23 @@ func ClearCurrentLevel(openChests bool, filter data.MonsterFilter) error {
 	ctx := context.Get()
 	ctx.SetLastAction("ClearCurrentLevel")
 
+	return clearRooms(ctx.PathFinder.OptimizeRoomsTraverseOrder(), openChests, filter)
+}
+
+// ClearCurrentLevelTo clears the level like ClearCurrentLevel, visiting the rooms in an order that ends close to the
+// given position (the exit or the waypoint used next).
+func ClearCurrentLevelTo(openChests bool, filter data.MonsterFilter, end data.Position) error {
+	ctx := context.Get()
+	ctx.SetLastAction("ClearCurrentLevelTo")
+
+	return clearRooms(ctx.PathFinder.OptimizeRoomsTraverseOrderTo(end), openChests, filter)
+}
+
+func clearRooms(rooms []data.Room, openChests bool, filter data.MonsterFilter) error {
+	ctx := context.Get()
+
 	// We can make this configurable later, but 20 is a good starting radius.
 	const pickupRadius = 20
-	rooms := ctx.PathFinder.OptimizeRoomsTraverseOrder()
 	for _, r := range rooms {
 		if errDeath := checkPlayerDeath(ctx); errDeath != nil {
 			return errDeath
@@ -13,7 +13,6 @@ import (
 	"github.com/hectorgimenez/koolo/internal/game"
 	"github.com/hectorgimenez/koolo/internal/ui"
 	"github.com/hectorgimenez/koolo/internal/utils"
-	"github.com/lxn/win"
 )
 
 func CubeAddItems(items ...data.Item) error {
@@ -140,7 +139,7 @@ func ensureCubeIsEmpty() error {
 		}
 	}
 
-	ctx.HID.PressKey(win.VK_ESCAPE)
+	ctx.HID.PressKey(game.KeyEscape)
 	utils.Sleep(300)
 
 	stashInventory(true)
@@ -15,7 +15,6 @@ import (
 	"github.com/hectorgimenez/koolo/internal/town"
 	"github.com/hectorgimenez/koolo/internal/ui"
 	"github.com/hectorgimenez/koolo/internal/utils"
-	"github.com/lxn/win"
 )
 
 func IdentifyAll(skipIdentify bool) error {
@@ -119,7 +118,7 @@ func CainIdentify() error {
 	}
 
 	// Select identify option
-	ctx.HID.KeySequence(win.VK_HOME, win.VK_DOWN, win.VK_RETURN)
+	ctx.HID.KeySequence(game.KeyHome, game.KeyArrowDown, game.KeyReturn)
 	utils.Sleep(800)
 
 	// Close menu if still open
@@ -11,7 +11,6 @@ import (
 	"github.com/hectorgimenez/koolo/internal/town"
 	"github.com/hectorgimenez/koolo/internal/ui"
 	"github.com/hectorgimenez/koolo/internal/utils"
-	"github.com/lxn/win"
 
 	"github.com/hectorgimenez/d2go/pkg/data"
 	"github.com/hectorgimenez/d2go/pkg/data/area"
@@ -20,7 +19,6 @@ import (
 	"github.com/hectorgimenez/d2go/pkg/data/npc"
 	"github.com/hectorgimenez/d2go/pkg/data/skill"
 	"github.com/hectorgimenez/d2go/pkg/data/stat"
-	"github.com/hectorgimenez/d2go/pkg/memory"
 )
 
 var uiStatButtonPosition = map[stat.ID]data.Position{
@@ -259,7 +257,7 @@ func ResetBindings() error {
 
 	// Loop for F1 through F8
 	for i := 0; i < 8; i++ {
-		fKey := byte(win.VK_F1 + i)                            // win.VK_F1 is 0x70, win.VK_F2 is 0x71, and so on.
+		fKey := byte(game.KeyF1 + i)                           // game.KeyF1 is 0x70, game.KeyF2 is 0x71, and so on.
 		fKeyBinding := data.KeyBinding{Key1: [2]byte{fKey, 0}} // Assuming 0 for no modifier key
 		ctx.Logger.Info(fmt.Sprintf("Attempting to bind TomeOfTownPortal to F%d", i+1))
 
@@ -328,12 +326,12 @@ func HireMerc() error {
 				return err
 			}
 
-			ctx.HID.KeySequence(win.VK_HOME, win.VK_DOWN, win.VK_RETURN)
+			ctx.HID.KeySequence(game.KeyHome, game.KeyArrowDown, game.KeyReturn)
 			utils.Sleep(2000)
 
 			mercList := ctx.GameReader.GetMercList()
 
-			var mercToHire *memory.MercOption
+			var mercToHire *game.MercOption
 			for i := range mercList {
 				if mercList[i].Skill.ID == skill.Prayer { // Targeting the Prayer skill ID
 					mercToHire = &mercList[i]
@@ -343,11 +341,11 @@ func HireMerc() error {
 
 			if mercToHire != nil {
 				ctx.Logger.Info(fmt.Sprintf("Hiring merc: %s with skill %s", mercToHire.Name, mercToHire.Skill.Name))
-				keySequence := []byte{win.VK_HOME}
+				keySequence := []byte{game.KeyHome}
 				for i := 0; i < mercToHire.Index; i++ {
-					keySequence = append(keySequence, win.VK_DOWN)
+					keySequence = append(keySequence, game.KeyArrowDown)
 				}
-				keySequence = append(keySequence, win.VK_RETURN, win.VK_UP, win.VK_RETURN)
+				keySequence = append(keySequence, game.KeyReturn, game.KeyArrowUp, game.KeyReturn)
 				ctx.HID.KeySequence(keySequence...)
 				utils.Sleep(1000)
 			} else {
@@ -440,9 +438,9 @@ func ResetStats() error {
 
 		// 3. Interact with Akara for the reset
 		InteractNPC(npc.Akara)
-		ctx.HID.KeySequence(win.VK_HOME, win.VK_DOWN, win.VK_DOWN, win.VK_RETURN)
+		ctx.HID.KeySequence(game.KeyHome, game.KeyArrowDown, game.KeyArrowDown, game.KeyReturn)
 		utils.Sleep(1000)
-		ctx.HID.KeySequence(win.VK_HOME, win.VK_RETURN)
+		ctx.HID.KeySequence(game.KeyHome, game.KeyReturn)
 		utils.Sleep(1000)
 
 		// 4. Now, drop any remaining items directly in the inventory
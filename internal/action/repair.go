@@ -14,7 +14,6 @@ import (
 	"github.com/hectorgimenez/koolo/internal/town"
 	"github.com/hectorgimenez/koolo/internal/ui"
 	"github.com/hectorgimenez/koolo/internal/utils"
-	"github.com/lxn/win"
 )
 
 func Repair() error {
@@ -85,9 +84,9 @@ func Repair() error {
 			}
 
 			if repairNPC != npc.Halbu {
-				ctx.HID.KeySequence(win.VK_HOME, win.VK_DOWN, win.VK_RETURN)
+				ctx.HID.KeySequence(game.KeyHome, game.KeyArrowDown, game.KeyReturn)
 			} else {
-				ctx.HID.KeySequence(win.VK_HOME, win.VK_RETURN)
+				ctx.HID.KeySequence(game.KeyHome, game.KeyReturn)
 			}
 
 			utils.Sleep(100)
@@ -3,8 +3,8 @@ package action
 import (
 	"github.com/hectorgimenez/d2go/pkg/data/npc"
 	botCtx "github.com/hectorgimenez/koolo/internal/context" // ALIAS THIS IMPORT
+	"github.com/hectorgimenez/koolo/internal/game"
 	"github.com/hectorgimenez/koolo/internal/town"
-	"github.com/lxn/win"
 	"github.com/hectorgimenez/d2go/pkg/data/item"
 	"github.com/hectorgimenez/d2go/pkg/data/stat"
 )
@@ -25,9 +25,9 @@ func ReviveMerc() {
 		InteractNPC(mercNPC)
 
 		if mercNPC == npc.Tyrael2 {
-			status.HID.KeySequence(win.VK_END, win.VK_UP, win.VK_RETURN, win.VK_ESCAPE)
+			status.HID.KeySequence(game.KeyEnd, game.KeyArrowUp, game.KeyReturn, game.KeyEscape)
 		} else {
-			status.HID.KeySequence(win.VK_HOME, win.VK_DOWN, win.VK_RETURN, win.VK_ESCAPE)
+			status.HID.KeySequence(game.KeyHome, game.KeyArrowDown, game.KeyReturn, game.KeyEscape)
 		}
 	}
 }
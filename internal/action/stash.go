@@ -14,12 +14,12 @@ import (
 	"github.com/hectorgimenez/d2go/pkg/data/object"
 	"github.com/hectorgimenez/d2go/pkg/nip"
 	"github.com/hectorgimenez/koolo/internal/action/step"
+	"github.com/hectorgimenez/koolo/internal/config"
 	"github.com/hectorgimenez/koolo/internal/context"
 	"github.com/hectorgimenez/koolo/internal/event"
 	"github.com/hectorgimenez/koolo/internal/game"
 	"github.com/hectorgimenez/koolo/internal/ui"
 	"github.com/hectorgimenez/koolo/internal/utils"
-	"github.com/lxn/win"
 )
 
 const (
@@ -60,6 +60,7 @@ func Stash(forceStash bool) error {
 	stashInventory(forceStash)
 	// Add call to dropExcessItems after stashing
 	dropExcessItems()
+	snapshotStash()
 	step.CloseAllMenus()
 
 	return nil
@@ -179,6 +180,7 @@ func stashInventory(firstRun bool) {
 			blacklistItem(i)
 			utils.Sleep(500)
 			DropItem(i)
+			notifyItemDropped(i, matchedRule, ruleFile)
 			utils.Sleep(500)
 			step.CloseAllMenus()
 			continue
@@ -324,11 +326,6 @@ func shouldKeepRecipeItem(i data.Item) bool {
 	ctx := context.Get()
 	ctx.SetLastStep("shouldKeepRecipeItem")
 
-	// No items with quality higher than magic can be part of a recipe
-	if i.Quality > item.QualityMagic {
-		return false
-	}
-
 	itemInStashNotMatchingRule := false
 
 	// Check if we already have the item in our stash and if it doesn't match any of our pickit rules
@@ -344,11 +341,10 @@ func shouldKeepRecipeItem(i data.Item) bool {
 
 	recipeMatch := false
 
-	// Check if the item is part of a recipe and if that recipe is enabled
-	// 'Recipes' variable is expected to be defined/imported from 'cube_recipes.go' or similar.
-	// This function (shouldKeepRecipeItem) itself is external to this file.
-	for _, recipe := range Recipes { // Assuming `Recipes` is properly defined/imported
-		if slices.Contains(recipe.Items, string(i.Name)) && slices.Contains(ctx.CharacterCfg.CubeRecipes.EnabledRecipes, recipe.Name) {
+	// Check if the item is part of a recipe and if that recipe is enabled, the recipe inputs decide the qualities
+	// accepted, up to magic by default
+	for _, recipe := range config.GetCubeRecipes() {
+		if recipe.UsesItem(i) && slices.Contains(ctx.CharacterCfg.CubeRecipes.EnabledRecipes, recipe.Name) {
 			recipeMatch = true
 			break
 		}
@@ -408,15 +404,15 @@ func dropExcessItems() {
 	ctx := context.Get()
 	ctx.SetLastAction("dropExcessItems")
 
-	itemsToDrop := make([]data.Item, 0)
+	itemsToDrop := make([]droppedItem, 0)
 	for _, i := range ctx.Data.Inventory.ByLocation(item.LocationInventory) {
 		if i.IsPotion() {
 			continue
 		}
 
-		_, dropIt, _, _ := shouldStashIt(i, false) // Re-evaluate if it should be dropped (not firstRun)
+		_, dropIt, rule, ruleFile := shouldStashIt(i, false) // Re-evaluate if it should be dropped (not firstRun)
 		if dropIt {
-			itemsToDrop = append(itemsToDrop, i)
+			itemsToDrop = append(itemsToDrop, droppedItem{item: i, rule: rule, ruleFile: ruleFile})
 		}
 	}
 
@@ -425,12 +421,36 @@ func dropExcessItems() {
 		// Ensure we are not in a menu before dropping
 		step.CloseAllMenus()
 
-		for _, i := range itemsToDrop {
-			DropItem(i)
+		for _, d := range itemsToDrop {
+			DropItem(d.item)
+			notifyItemDropped(d.item, d.rule, d.ruleFile)
 		}
 	}
 }
 
+type droppedItem struct {
+	item     data.Item
+	rule     string
+	ruleFile string
+}
+
+// notifyItemDropped sends an ItemDisposedEvent when an item matched by a pickit rule has been dropped, DropItem already
+// refreshed the game data so an item still in the inventory was not dropped.
+func notifyItemDropped(i data.Item, rule, ruleFile string) {
+	ctx := context.Get()
+	if ruleFile == "" {
+		return
+	}
+	for _, it := range ctx.Data.Inventory.ByLocation(item.LocationInventory) {
+		if it.UnitID == i.UnitID {
+			return
+		}
+	}
+
+	drop := data.Drop{Item: i, Rule: rule, RuleFile: ruleFile}
+	event.Send(event.ItemDisposed(event.Text(ctx.Name, ""), drop, event.DisposalDropped))
+}
+
 func blacklistItem(i data.Item) {
 	ctx := context.Get()
 	ctx.CurrentGame.BlacklistedItems = append(ctx.CurrentGame.BlacklistedItems, i)
@@ -547,16 +567,29 @@ func OpenStash() error {
 			return ctx.Data.OpenMenus.Stash
 		},
 	)
+	snapshotStash()
 
 	return nil
 }
 
+// snapshotStash publishes the stash and inventory content, so items can be searched across characters.
+func snapshotStash() {
+	ctx := context.Get()
+	ctx.RefreshGameData()
+	if !ctx.Data.OpenMenus.Stash {
+		return
+	}
+
+	items := ctx.Data.Inventory.ByLocation(item.LocationStash, item.LocationSharedStash, item.LocationInventory)
+	event.Send(event.StashSnapshot(event.Text(ctx.Name, ""), items))
+}
+
 func CloseStash() error {
 	ctx := context.Get()
 	ctx.SetLastAction("CloseStash")
 
 	if ctx.Data.OpenMenus.Stash {
-		ctx.HID.PressKey(win.VK_ESCAPE)
+		ctx.HID.PressKey(game.KeyEscape)
 
 	} else {
 		return errors.New("stash is not open")
@@ -9,6 +9,7 @@ import (
 	"github.com/hectorgimenez/d2go/pkg/data/skill"
 	"github.com/hectorgimenez/koolo/internal/context"
 	"github.com/hectorgimenez/koolo/internal/game"
+	"github.com/hectorgimenez/koolo/internal/pather"
 	"github.com/hectorgimenez/koolo/internal/ui"
 	"github.com/hectorgimenez/koolo/internal/utils"
 )
@@ -279,7 +280,14 @@ func MoveTo(dest data.Position, options ...MoveOption) error {
 		}
 
 		//Compute path to reach destination
-		path, _, found := ctx.PathFinder.GetPath(currentDest)
+		var path pather.Path
+		var found bool
+		if opts.ignoreMonsters && !ctx.Data.CanTeleport() && ctx.CharacterCfg.Character.AvoidDangerousMonsters {
+			// Not clearing the way, walk around the dangerous packs
+			path, _, found = ctx.PathFinder.GetSafePath(currentDest)
+		} else {
+			path, _, found = ctx.PathFinder.GetPath(currentDest)
+		}
 		if !found {
 			//Couldn't find path, abort movement
 			ctx.Logger.Warn("path could not be calculated. Current area: [" + ctx.Data.PlayerUnit.Area.Area().Name + "]. Trying to path to Destination: [" + fmt.Sprintf("%d,%d", currentDest.X, currentDest.Y) + "]")
@@ -60,7 +60,7 @@ func PreRun(firstRun bool) error {
 			ctx.CharacterCfg.MulingState.CurrentMuleIndex++
 
 			// CRITICAL: Save the updated index to the config file BEFORE switching
-			if err := config.SaveSupervisorConfig(ctx.Name, ctx.CharacterCfg); err != nil {
+			if err := config.SaveSupervisorConfig(ctx.Name, ctx.CharacterCfg, "muling"); err != nil {
 				ctx.Logger.Error("Failed to save muling state before switching", "error", err)
 				return err // Stop if we can't save state
 			}
@@ -77,7 +77,7 @@ func PreRun(firstRun bool) error {
 			if ctx.CharacterCfg.MulingState.CurrentMuleIndex != 0 {
 				ctx.Logger.Info("Muling process complete, resetting mule index.")
 				ctx.CharacterCfg.MulingState.CurrentMuleIndex = 0
-				if err := config.SaveSupervisorConfig(ctx.Name, ctx.CharacterCfg); err != nil {
+				if err := config.SaveSupervisorConfig(ctx.Name, ctx.CharacterCfg, "muling"); err != nil {
 					ctx.Logger.Error("Failed to reset muling state", "error", err)
 				}
 			}
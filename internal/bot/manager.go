@@ -3,6 +3,7 @@ package bot
 import (
 	"fmt"
 	"log/slog"
+	"path/filepath"
 	"strconv"
 	"syscall"
 	"time"
@@ -27,6 +28,7 @@ type SupervisorManager struct {
 	supervisors    map[string]Supervisor
 	crashDetectors map[string]*game.CrashDetector
 	eventListener  *event.Listener
+	statsHandlers  map[string]*event.Subscription
 }
 
 func NewSupervisorManager(logger *slog.Logger, eventListener *event.Listener) *SupervisorManager {
@@ -36,6 +38,7 @@ func NewSupervisorManager(logger *slog.Logger, eventListener *event.Listener) *S
 		supervisors:    make(map[string]Supervisor),
 		crashDetectors: make(map[string]*game.CrashDetector),
 		eventListener:  eventListener,
+		statsHandlers:  make(map[string]*event.Subscription),
 	}
 }
 
@@ -62,6 +65,15 @@ func (mng *SupervisorManager) Start(supervisorName string, attachToExisting bool
 		return fmt.Errorf("error loading config: %w", err)
 	}
 
+	if cfg, found := config.GetCharacter(supervisorName); found {
+		if errs := cfg.Runtime.ValidationErrors.Errors(); len(errs) > 0 {
+			return fmt.Errorf("invalid %s config: %w", supervisorName, errs)
+		}
+		for _, w := range cfg.Runtime.ValidationErrors.Warnings() {
+			mng.logger.Warn("Character config warning", slog.String("supervisor", supervisorName), slog.String("field", w.Field), slog.String("warning", w.Message))
+		}
+	}
+
 	supervisorLogger, err := log.NewLogger(config.Koolo.Debug.Log, config.Koolo.LogSaveDirectory, supervisorName)
 	if err != nil {
 		return err
@@ -157,6 +169,13 @@ func (mng *SupervisorManager) Stop(supervisor string) {
 		// Stop the Supervisor's internal loops and kill the client if configured
 		s.Stop()
 
+		// Flush the game state recording, if any
+		if ctx := s.GetContext(); ctx != nil && ctx.Recorder != nil {
+			if err := ctx.Recorder.Close(); err != nil {
+				mng.logger.Warn("Error closing game recording", slog.Any("error", err))
+			}
+		}
+
 		// Delete from the list of active Supervisors
 		delete(mng.supervisors, supervisor)
 
@@ -166,6 +185,12 @@ func (mng *SupervisorManager) Stop(supervisor string) {
 			delete(mng.crashDetectors, supervisor)
 		}
 
+		// Stop receiving events for the stopped supervisor
+		if sub, ok := mng.statsHandlers[supervisor]; ok {
+			sub.Unsubscribe()
+			delete(mng.statsHandlers, supervisor)
+		}
+
 		// The logic to start the next character has been removed from here.
 		// The restartFunc is now the single source of truth for this,
 		// preventing the mule from restarting itself.
@@ -260,6 +285,13 @@ func (mng *SupervisorManager) buildSupervisor(supervisorName string, logger *slo
 	ctx.GameReader = gr
 	ctx.MemoryInjector = gi
 	ctx.PathFinder = pf
+	if config.Koolo.Debug.RecordGameData {
+		logDir := config.Koolo.LogSaveDirectory
+		if logDir == "" {
+			logDir = "logs"
+		}
+		ctx.Recorder = game.NewRecorder(filepath.Join(logDir, "recordings"), supervisorName)
+	}
 	ctx.BeltManager = bm
 	ctx.HealthManager = hm
 	char, err := character.BuildCharacter(ctx.Context)
@@ -272,8 +304,11 @@ func (mng *SupervisorManager) buildSupervisor(supervisorName string, logger *slo
 	bot := NewBot(ctx.Context, muleManager)
 
 	statsHandler := NewStatsHandler(supervisorName, logger)
-	mng.eventListener.Register(statsHandler.Handle)
-	supervisor, err := NewSinglePlayerSupervisor(supervisorName, bot, statsHandler)
+	if sub, found := mng.statsHandlers[supervisorName]; found {
+		sub.Unsubscribe()
+	}
+	mng.statsHandlers[supervisorName] = mng.eventListener.RegisterWith(statsHandler.Handle, event.SubscriberOptions{Name: "stats-" + supervisorName})
+	supervisor, err := NewSinglePlayerSupervisor(supervisorName, bot, statsHandler, gr)
 
 	if err != nil {
 		return nil, nil, err
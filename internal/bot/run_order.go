@@ -0,0 +1,215 @@
+package bot
+
+import (
+	"log/slog"
+	"math"
+	"math/rand"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/hectorgimenez/koolo/internal/config"
+	"github.com/hectorgimenez/koolo/internal/event"
+	"github.com/hectorgimenez/koolo/internal/remote/statslog"
+)
+
+// RunOrderStrategy decides in which order the configured runs are executed in a new game, it can also leave some
+// of them out.
+type RunOrderStrategy interface {
+	Order(runs []string) []string
+}
+
+// configuredRunOrder keeps the runs as they are defined in the character config.
+type configuredRunOrder struct{}
+
+func (configuredRunOrder) Order(runs []string) []string {
+	return runs
+}
+
+// pinnedRuns are never moved nor skipped by the adaptive strategy, they have to be executed at their configured
+// position (leveling flow, muling, parking in town...).
+var pinnedRuns = []string{
+	string(config.LevelingRun),
+	string(config.QuestsRun),
+	string(config.MuleRun),
+	string(config.UtilityRun),
+	string(config.TerrorZoneRun),
+}
+
+// minRunSamples is the minimum amount of finished runs required to trust the historical score of a run, runs below
+// it get the best known score, so they keep being executed until we have enough data.
+const minRunSamples = 3
+
+type AdaptiveRunOptions struct {
+	// SkipAfterFailures is the amount of deaths/chickens within SkipFor needed to temporarily skip a run, 0 disables it
+	SkipAfterFailures int
+	SkipFor           time.Duration
+}
+
+type RunScore struct {
+	Run       string
+	Score     float64
+	Samples   int
+	SkipUntil time.Time
+}
+
+// ScoreRuns computes the score of every given run based on the historical records, see statslog.AnalyzeRuns.
+func ScoreRuns(runs []string, records []statslog.Record, now time.Time, opts AdaptiveRunOptions) map[string]RunScore {
+	scores := make(map[string]RunScore, len(runs))
+	for _, r := range runs {
+		scores[r] = RunScore{Run: r}
+	}
+
+	bestScore := 0.0
+	for _, a := range statslog.AnalyzeRuns(records) {
+		s, found := scores[a.Run]
+		if !found {
+			continue
+		}
+		s.Score = a.Score
+		s.Samples = a.Runs
+		scores[a.Run] = s
+
+		if a.Runs >= minRunSamples {
+			bestScore = math.Max(bestScore, a.Score)
+		}
+	}
+
+	for r, s := range scores {
+		if s.Samples < minRunSamples {
+			s.Score = bestScore
+			scores[r] = s
+		}
+	}
+
+	if opts.SkipAfterFailures <= 0 || opts.SkipFor <= 0 {
+		return scores
+	}
+
+	failures := make(map[string][]time.Time)
+	for _, rec := range records {
+		for _, r := range rec.Runs {
+			if r.Reason != event.FinishedDied && r.Reason != event.FinishedChicken && r.Reason != event.FinishedMercChicken {
+				continue
+			}
+			if r.FinishedAt.After(now.Add(-opts.SkipFor)) && !r.FinishedAt.After(now) {
+				failures[r.Name] = append(failures[r.Name], r.FinishedAt)
+			}
+		}
+	}
+
+	for r, f := range failures {
+		s, found := scores[r]
+		if !found || len(f) < opts.SkipAfterFailures {
+			continue
+		}
+		last := slices.MaxFunc(f, func(a, b time.Time) int { return a.Compare(b) })
+		s.SkipUntil = last.Add(opts.SkipFor)
+		scores[r] = s
+	}
+
+	return scores
+}
+
+// OrderRunsByScore sorts the runs by score, highest first, keeping the configured order for ties. When rnd is not
+// nil the order is randomized weighted by score instead. Pinned runs keep their configured position and runs in
+// cooldown are removed, unless all of them are, in that case the runs are returned untouched.
+func OrderRunsByScore(runs []string, scores map[string]RunScore, now time.Time, rnd *rand.Rand) (ordered []string, skipped []string) {
+	movable := make([]string, 0, len(runs))
+	for _, r := range runs {
+		if slices.Contains(pinnedRuns, r) {
+			continue
+		}
+		if now.Before(scores[r].SkipUntil) {
+			skipped = append(skipped, r)
+			continue
+		}
+		movable = append(movable, r)
+	}
+
+	if len(movable) == 0 && len(skipped) > 0 {
+		return runs, nil
+	}
+
+	if rnd != nil {
+		// Weighted random order, every run gets a key of u^(1/w) and the highest keys go first
+		keys := make(map[string]float64, len(movable))
+		for _, r := range movable {
+			keys[r] = math.Pow(rnd.Float64(), 1/math.Max(scores[r].Score, 0.01))
+		}
+		sort.SliceStable(movable, func(i, j int) bool { return keys[movable[i]] > keys[movable[j]] })
+	} else {
+		sort.SliceStable(movable, func(i, j int) bool { return scores[movable[i]].Score > scores[movable[j]].Score })
+	}
+
+	ordered = make([]string, 0, len(runs))
+	for _, r := range runs {
+		switch {
+		case slices.Contains(pinnedRuns, r):
+			ordered = append(ordered, r)
+		case slices.Contains(skipped, r):
+			continue
+		default:
+			ordered = append(ordered, movable[0])
+			movable = movable[1:]
+		}
+	}
+
+	return ordered, skipped
+}
+
+// AdaptiveRunOrder reorders and temporarily skips runs based on the game history recorded by statslog.
+type AdaptiveRunOrder struct {
+	supervisor string
+	cfg        *config.CharacterCfg
+	logger     *slog.Logger
+	rnd        *rand.Rand
+	now        func() time.Time
+	history    func(f statslog.Filter) ([]statslog.Record, error)
+}
+
+func NewAdaptiveRunOrder(supervisor string, cfg *config.CharacterCfg, logger *slog.Logger) *AdaptiveRunOrder {
+	return &AdaptiveRunOrder{
+		supervisor: supervisor,
+		cfg:        cfg,
+		logger:     logger,
+		rnd:        rand.New(rand.NewSource(time.Now().UnixNano())),
+		now:        time.Now,
+		history: func(f statslog.Filter) ([]statslog.Record, error) {
+			return statslog.ReadAll(statslog.Dir(), f)
+		},
+	}
+}
+
+func (o *AdaptiveRunOrder) Order(runs []string) []string {
+	now := o.now()
+	opts := o.cfg.Game.AdaptiveRuns
+
+	lookback := time.Duration(opts.LookbackDays) * 24 * time.Hour
+	if lookback <= 0 {
+		lookback = 7 * 24 * time.Hour
+	}
+
+	records, err := o.history(statslog.Filter{Supervisor: o.supervisor, From: now.Add(-lookback)})
+	if err != nil {
+		o.logger.Warn("Error reading run history, using configured run order", slog.Any("error", err))
+		return runs
+	}
+
+	scores := ScoreRuns(runs, records, now, AdaptiveRunOptions{
+		SkipAfterFailures: opts.SkipAfterFailures,
+		SkipFor:           time.Duration(opts.SkipForMinutes) * time.Minute,
+	})
+
+	var rnd *rand.Rand
+	if o.cfg.Game.RandomizeRuns {
+		rnd = o.rnd
+	}
+	ordered, skipped := OrderRunsByScore(runs, scores, now, rnd)
+	for _, r := range skipped {
+		o.logger.Info("Skipping run due to recent failures", slog.String("run", r), slog.Time("until", scores[r].SkipUntil))
+	}
+	o.logger.Debug("Adaptive run order", slog.Any("runs", ordered))
+
+	return ordered
+}
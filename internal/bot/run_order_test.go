@@ -0,0 +1,171 @@
+package bot
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/koolo/internal/event"
+	"github.com/hectorgimenez/koolo/internal/remote/statslog"
+)
+
+var runOrderNow = time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+
+func runRecord(name string, startedAt time.Time, duration time.Duration, reason event.FinishReason, drops int) statslog.RunRecord {
+	return statslog.RunRecord{
+		Name:       name,
+		Reason:     reason,
+		StartedAt:  startedAt,
+		FinishedAt: startedAt.Add(duration),
+		Drops:      make([]data.Drop, drops),
+	}
+}
+
+func gameRecord(runs ...statslog.RunRecord) statslog.Record {
+	return statslog.Record{StartedAt: runs[0].StartedAt, FinishedAt: runs[len(runs)-1].FinishedAt, Runs: runs}
+}
+
+func TestScoreRunsPrefersMoreDropsPerHour(t *testing.T) {
+	var records []statslog.Record
+	for i := 0; i < 5; i++ {
+		start := runOrderNow.Add(-time.Duration(i+2) * time.Hour)
+		records = append(records, gameRecord(
+			runRecord("pit", start, 3*time.Minute, event.FinishedOK, 2),
+			runRecord("countess", start.Add(5*time.Minute), 2*time.Minute, event.FinishedOK, 0),
+		))
+	}
+
+	scores := ScoreRuns([]string{"countess", "pit"}, records, runOrderNow, AdaptiveRunOptions{})
+	if scores["pit"].Score <= scores["countess"].Score {
+		t.Errorf("Expected pit to score higher than countess, got %f and %f", scores["pit"].Score, scores["countess"].Score)
+	}
+
+	ordered, skipped := OrderRunsByScore([]string{"countess", "pit"}, scores, runOrderNow, nil)
+	if !slices.Equal(ordered, []string{"pit", "countess"}) {
+		t.Errorf("Expected [pit countess], got %v", ordered)
+	}
+	if len(skipped) != 0 {
+		t.Errorf("Expected no skipped runs, got %v", skipped)
+	}
+}
+
+func TestScoreRunsPenalizesFailures(t *testing.T) {
+	var records []statslog.Record
+	for i := 0; i < 4; i++ {
+		start := runOrderNow.Add(-time.Duration(i+24) * time.Hour)
+		reason := event.FinishedOK
+		if i%2 == 0 {
+			reason = event.FinishedDied
+		}
+		records = append(records, gameRecord(
+			runRecord("baal", start, 4*time.Minute, reason, 2),
+			runRecord("mephisto", start.Add(5*time.Minute), 4*time.Minute, event.FinishedOK, 2),
+		))
+	}
+
+	scores := ScoreRuns([]string{"baal", "mephisto"}, records, runOrderNow, AdaptiveRunOptions{})
+	if scores["baal"].Score >= scores["mephisto"].Score {
+		t.Errorf("Expected baal to score lower than mephisto, got %f and %f", scores["baal"].Score, scores["mephisto"].Score)
+	}
+}
+
+func TestScoreRunsUnknownRunGetsBestScore(t *testing.T) {
+	var records []statslog.Record
+	for i := 0; i < 3; i++ {
+		start := runOrderNow.Add(-time.Duration(i+1) * time.Hour)
+		records = append(records, gameRecord(runRecord("pit", start, 3*time.Minute, event.FinishedOK, 1)))
+	}
+
+	scores := ScoreRuns([]string{"pit", "cows"}, records, runOrderNow, AdaptiveRunOptions{})
+	if scores["cows"].Score != scores["pit"].Score {
+		t.Errorf("Expected cows to get the best known score %f, got %f", scores["pit"].Score, scores["cows"].Score)
+	}
+}
+
+func TestScoreRunsSkipsAfterRecentFailures(t *testing.T) {
+	opts := AdaptiveRunOptions{SkipAfterFailures: 3, SkipFor: time.Hour}
+
+	var records []statslog.Record
+	for i := 0; i < 3; i++ {
+		start := runOrderNow.Add(-time.Duration(50-i*10) * time.Minute)
+		records = append(records, gameRecord(
+			runRecord("pit", start, 2*time.Minute, event.FinishedOK, 1),
+			runRecord("baal", start.Add(3*time.Minute), 2*time.Minute, event.FinishedDied, 0),
+		))
+	}
+
+	scores := ScoreRuns([]string{"pit", "baal", "mule"}, records, runOrderNow, opts)
+	expectedUntil := records[2].Runs[1].FinishedAt.Add(time.Hour)
+	if !scores["baal"].SkipUntil.Equal(expectedUntil) {
+		t.Errorf("Expected baal to be skipped until %s, got %s", expectedUntil, scores["baal"].SkipUntil)
+	}
+
+	ordered, skipped := OrderRunsByScore([]string{"baal", "pit", "mule"}, scores, runOrderNow, nil)
+	if !slices.Equal(ordered, []string{"pit", "mule"}) {
+		t.Errorf("Expected [pit mule], got %v", ordered)
+	}
+	if !slices.Equal(skipped, []string{"baal"}) {
+		t.Errorf("Expected [baal] to be skipped, got %v", skipped)
+	}
+
+	// Once the cooldown is over the run is back
+	ordered, _ = OrderRunsByScore([]string{"baal", "pit", "mule"}, scores, expectedUntil, nil)
+	if !slices.Contains(ordered, "baal") {
+		t.Errorf("Expected baal to be back after cooldown, got %v", ordered)
+	}
+
+	// Only two failures, not enough to skip it
+	scores = ScoreRuns([]string{"pit", "baal"}, records[1:], runOrderNow, opts)
+	if !scores["baal"].SkipUntil.IsZero() {
+		t.Errorf("Expected baal not to be skipped, got %s", scores["baal"].SkipUntil)
+	}
+}
+
+func TestOrderRunsByScoreKeepsPinnedRuns(t *testing.T) {
+	scores := map[string]RunScore{
+		"countess": {Run: "countess", Score: 1},
+		"pit":      {Run: "pit", Score: 3},
+		"cows":     {Run: "cows", Score: 2},
+	}
+
+	ordered, _ := OrderRunsByScore([]string{"countess", "pit", "mule", "cows", "utility"}, scores, runOrderNow, nil)
+	if !slices.Equal(ordered, []string{"pit", "cows", "mule", "countess", "utility"}) {
+		t.Errorf("Expected [pit cows mule countess utility], got %v", ordered)
+	}
+}
+
+func TestOrderRunsByScoreNeverSkipsEverything(t *testing.T) {
+	scores := map[string]RunScore{
+		"pit": {Run: "pit", SkipUntil: runOrderNow.Add(time.Minute)},
+	}
+
+	ordered, skipped := OrderRunsByScore([]string{"pit"}, scores, runOrderNow, nil)
+	if !slices.Equal(ordered, []string{"pit"}) || len(skipped) != 0 {
+		t.Errorf("Expected configured runs when all of them are in cooldown, got %v (skipped %v)", ordered, skipped)
+	}
+}
+
+func TestOrderRunsByScoreWeightedRandom(t *testing.T) {
+	scores := map[string]RunScore{
+		"pit":      {Run: "pit", Score: 100},
+		"countess": {Run: "countess", Score: 0.1},
+	}
+
+	rnd := rand.New(rand.NewSource(1))
+	first := 0
+	for i := 0; i < 200; i++ {
+		ordered, _ := OrderRunsByScore([]string{"countess", "pit"}, scores, runOrderNow, rnd)
+		if len(ordered) != 2 {
+			t.Fatalf("Expected 2 runs, got %v", ordered)
+		}
+		if ordered[0] == "pit" {
+			first++
+		}
+	}
+
+	if first < 190 {
+		t.Errorf("Expected pit to go first most of the times, got %d/200", first)
+	}
+}
@@ -0,0 +1,257 @@
+package bot
+
+import (
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hectorgimenez/koolo/internal/config"
+	"github.com/hectorgimenez/koolo/internal/remote/statslog"
+)
+
+// ScheduleWindow is a period of time in which a supervisor is scheduled to play.
+type ScheduleWindow struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// SupervisorTimeline contains the computed schedule for a supervisor, play time budgets already applied.
+type SupervisorTimeline struct {
+	Supervisor       string           `json:"supervisor"`
+	Group            string           `json:"group,omitempty"`
+	UsedTodayMinutes float64          `json:"usedTodayMinutes"`
+	UsedWeekMinutes  float64          `json:"usedWeekMinutes"`
+	Windows          []ScheduleWindow `json:"windows"`
+}
+
+// Active returns the window containing now, if any.
+func (t SupervisorTimeline) Active(now time.Time) (ScheduleWindow, bool) {
+	for _, w := range t.Windows {
+		if !now.Before(w.Start) && now.Before(w.End) {
+			return w, true
+		}
+	}
+
+	return ScheduleWindow{}, false
+}
+
+// PlayTimeUsage returns how long a supervisor has been in game between from and to.
+type PlayTimeUsage func(supervisor string, from, to time.Time) time.Duration
+
+// HistoryPlayTime computes the play time from the games recorded by statslog, the game in progress is not counted
+// until it's finished.
+func HistoryPlayTime(supervisor string, from, to time.Time) time.Duration {
+	records, err := statslog.ReadAll(statslog.Dir(), statslog.Filter{Supervisor: supervisor, From: from.AddDate(0, 0, -1), To: to})
+	if err != nil {
+		return 0
+	}
+
+	total := time.Duration(0)
+	for _, rec := range records {
+		start, end := rec.StartedAt, rec.FinishedAt
+		if start.Before(from) {
+			start = from
+		}
+		if end.After(to) {
+			end = to
+		}
+		if end.After(start) {
+			total += end.Sub(start)
+		}
+	}
+
+	return total
+}
+
+// PreviewSchedules computes the timeline of every scheduled supervisor for the next days.
+func PreviewSchedules(now time.Time, days int, usage PlayTimeUsage) []SupervisorTimeline {
+	return buildTimelines(config.GetCharacters(), config.Koolo.ScheduleGroups, now, days, usage)
+}
+
+func buildTimelines(characters map[string]*config.CharacterCfg, groups []config.ScheduleGroup, now time.Time, days int, usage PlayTimeUsage) []SupervisorTimeline {
+	names := make([]string, 0, len(characters))
+	for name := range characters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	timelines := make([]SupervisorTimeline, 0)
+	for _, name := range names {
+		cfg := characters[name]
+		if cfg == nil {
+			continue
+		}
+		sch, group, enabled := effectiveSchedule(name, cfg, groups)
+		if !enabled {
+			continue
+		}
+
+		var usedToday, usedWeek time.Duration
+		if usage != nil && (sch.DailyLimitMinutes > 0 || sch.WeeklyLimitMinutes > 0) {
+			usedToday = usage(name, dayStart(now), now)
+			usedWeek = usage(name, weekStart(now), now)
+		}
+
+		timelines = append(timelines, SupervisorTimeline{
+			Supervisor:       name,
+			Group:            group,
+			UsedTodayMinutes: usedToday.Minutes(),
+			UsedWeekMinutes:  usedWeek.Minutes(),
+			Windows:          PlanSchedule(sch, now, now.AddDate(0, 0, days), usedToday, usedWeek),
+		})
+	}
+
+	return timelines
+}
+
+// effectiveSchedule returns the schedule followed by the supervisor, the group one if it belongs to an enabled group.
+// Play time budgets set on the character take precedence over the group ones.
+func effectiveSchedule(supervisor string, cfg *config.CharacterCfg, groups []config.ScheduleGroup) (config.Scheduler, string, bool) {
+	for _, g := range groups {
+		if !g.Scheduler.Enabled || !slices.ContainsFunc(g.Supervisors, func(s string) bool { return strings.EqualFold(s, supervisor) }) {
+			continue
+		}
+
+		sch := g.Scheduler
+		if cfg.Scheduler.DailyLimitMinutes > 0 {
+			sch.DailyLimitMinutes = cfg.Scheduler.DailyLimitMinutes
+		}
+		if cfg.Scheduler.WeeklyLimitMinutes > 0 {
+			sch.WeeklyLimitMinutes = cfg.Scheduler.WeeklyLimitMinutes
+		}
+
+		return sch, g.Name, true
+	}
+
+	return cfg.Scheduler, "", cfg.Scheduler.Enabled
+}
+
+// PlanSchedule returns the windows between from and to in which the supervisor should be playing. Ranges ending at
+// or before their start time cross midnight. Budgets are applied assuming the supervisor plays every window, starting
+// with the time already used today and this week.
+func PlanSchedule(sch config.Scheduler, from, to time.Time, usedToday, usedWeek time.Duration) []ScheduleWindow {
+	windows := scheduleWindows(sch, from, to)
+
+	daily := time.Duration(sch.DailyLimitMinutes) * time.Minute
+	weekly := time.Duration(sch.WeeklyLimitMinutes) * time.Minute
+	if daily <= 0 && weekly <= 0 {
+		return windows
+	}
+
+	usedByDay := map[time.Time]time.Duration{dayStart(from): usedToday}
+	usedByWeek := map[time.Time]time.Duration{weekStart(from): usedWeek}
+
+	budgeted := make([]ScheduleWindow, 0, len(windows))
+	for _, w := range windows {
+		// Budgets are per calendar day, so windows crossing midnight are split
+		for start := w.Start; start.Before(w.End); {
+			day := dayStart(start)
+			week := weekStart(start)
+			end := day.AddDate(0, 0, 1)
+			if w.End.Before(end) {
+				end = w.End
+			}
+
+			allowed := end.Sub(start)
+			if daily > 0 {
+				allowed = min(allowed, daily-usedByDay[day])
+			}
+			if weekly > 0 {
+				allowed = min(allowed, weekly-usedByWeek[week])
+			}
+			if allowed > 0 {
+				budgeted = append(budgeted, ScheduleWindow{Start: start, End: start.Add(allowed)})
+				usedByDay[day] += allowed
+				usedByWeek[week] += allowed
+			}
+
+			start = end
+		}
+	}
+
+	return mergeWindows(budgeted)
+}
+
+// scheduleWindows expands the weekly time ranges and date overrides into windows between from and to.
+func scheduleWindows(sch config.Scheduler, from, to time.Time) []ScheduleWindow {
+	windows := make([]ScheduleWindow, 0)
+
+	// Start one day earlier, overnight ranges from the previous day may still be running
+	for day := dayStart(from).AddDate(0, 0, -1); day.Before(to); day = day.AddDate(0, 0, 1) {
+		for _, tr := range timeRangesForDate(sch, day) {
+			start := time.Date(day.Year(), day.Month(), day.Day(), tr.Start.Hour(), tr.Start.Minute(), 0, 0, day.Location())
+			end := time.Date(day.Year(), day.Month(), day.Day(), tr.End.Hour(), tr.End.Minute(), 0, 0, day.Location())
+			if !end.After(start) {
+				end = end.AddDate(0, 0, 1)
+			}
+
+			if start.Before(from) {
+				start = from
+			}
+			if end.After(to) {
+				end = to
+			}
+			if end.After(start) {
+				windows = append(windows, ScheduleWindow{Start: start, End: end})
+			}
+		}
+	}
+
+	return mergeWindows(windows)
+}
+
+func timeRangesForDate(sch config.Scheduler, day time.Time) []config.TimeRange {
+	date := day.Format("2006-01-02")
+	for _, o := range sch.Overrides {
+		if o.Date != date {
+			continue
+		}
+		if o.Off {
+			return nil
+		}
+		if len(o.TimeRanges) > 0 {
+			return o.TimeRanges
+		}
+	}
+
+	var ranges []config.TimeRange
+	for _, d := range sch.Days {
+		if d.DayOfWeek == int(day.Weekday()) {
+			ranges = append(ranges, d.TimeRanges...)
+		}
+	}
+
+	return ranges
+}
+
+func mergeWindows(windows []ScheduleWindow) []ScheduleWindow {
+	if len(windows) < 2 {
+		return windows
+	}
+
+	sort.Slice(windows, func(i, j int) bool { return windows[i].Start.Before(windows[j].Start) })
+
+	merged := []ScheduleWindow{windows[0]}
+	for _, w := range windows[1:] {
+		last := &merged[len(merged)-1]
+		if !w.Start.After(last.End) {
+			if w.End.After(last.End) {
+				last.End = w.End
+			}
+			continue
+		}
+		merged = append(merged, w)
+	}
+
+	return merged
+}
+
+func dayStart(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// weekStart returns the beginning of the week containing t, weeks start on Monday.
+func weekStart(t time.Time) time.Time {
+	return dayStart(t).AddDate(0, 0, -((int(t.Weekday()) + 6) % 7))
+}
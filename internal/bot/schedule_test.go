@@ -0,0 +1,130 @@
+package bot
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hectorgimenez/koolo/internal/config"
+)
+
+// Friday
+var scheduleNow = time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+
+func clock(hour, minute int) time.Time {
+	return time.Date(0, 1, 1, hour, minute, 0, 0, time.UTC)
+}
+
+func weekly(day time.Weekday, ranges ...config.TimeRange) config.Scheduler {
+	return config.Scheduler{
+		Enabled: true,
+		Days:    []config.Day{{DayOfWeek: int(day), TimeRanges: ranges}},
+	}
+}
+
+func assertWindows(t *testing.T, got []ScheduleWindow, expected ...ScheduleWindow) {
+	t.Helper()
+
+	if len(got) != len(expected) {
+		t.Fatalf("Expected %d windows, got %d: %v", len(expected), len(got), got)
+	}
+	for i := range expected {
+		if !got[i].Start.Equal(expected[i].Start) || !got[i].End.Equal(expected[i].End) {
+			t.Errorf("Expected window %d to be %s - %s, got %s - %s", i, expected[i].Start, expected[i].End, got[i].Start, got[i].End)
+		}
+	}
+}
+
+func at(day, hour, minute int) time.Time {
+	return time.Date(2025, 1, day, hour, minute, 0, 0, time.UTC)
+}
+
+func TestPlanScheduleOvernightRange(t *testing.T) {
+	sch := weekly(time.Friday, config.TimeRange{Start: clock(22, 0), End: clock(2, 0)})
+
+	windows := PlanSchedule(sch, scheduleNow, scheduleNow.AddDate(0, 0, 7), 0, 0)
+	assertWindows(t, windows, ScheduleWindow{Start: at(10, 22, 0), End: at(11, 2, 0)})
+
+	// After midnight the range started the previous day is still active
+	tl := SupervisorTimeline{Windows: PlanSchedule(sch, at(11, 1, 0), at(12, 0, 0), 0, 0)}
+	if _, active := tl.Active(at(11, 1, 0)); !active {
+		t.Errorf("Expected overnight range to be active at 01:00")
+	}
+	if _, active := tl.Active(at(11, 2, 0)); active {
+		t.Errorf("Expected overnight range to be finished at 02:00")
+	}
+}
+
+func TestPlanScheduleDateOverrides(t *testing.T) {
+	sch := config.Scheduler{Enabled: true}
+	for d := 0; d < 7; d++ {
+		sch.Days = append(sch.Days, config.Day{DayOfWeek: d, TimeRanges: []config.TimeRange{{Start: clock(14, 0), End: clock(16, 0)}}})
+	}
+	sch.Overrides = []config.DateOverride{
+		{Date: "2025-01-11", Off: true},
+		{Date: "2025-01-12", TimeRanges: []config.TimeRange{{Start: clock(8, 0), End: clock(9, 0)}}},
+	}
+
+	windows := PlanSchedule(sch, scheduleNow, at(13, 0, 0), 0, 0)
+	assertWindows(t, windows,
+		ScheduleWindow{Start: at(10, 14, 0), End: at(10, 16, 0)},
+		ScheduleWindow{Start: at(12, 8, 0), End: at(12, 9, 0)},
+	)
+}
+
+func TestPlanScheduleDailyLimit(t *testing.T) {
+	sch := config.Scheduler{Enabled: true, DailyLimitMinutes: 120}
+	for d := 0; d < 7; d++ {
+		sch.Days = append(sch.Days, config.Day{DayOfWeek: d, TimeRanges: []config.TimeRange{{Start: clock(10, 0), End: clock(18, 0)}}})
+	}
+
+	// 30 minutes already played today, it's 12:00 so only 90 minutes left
+	windows := PlanSchedule(sch, scheduleNow, at(12, 0, 0), 30*time.Minute, 30*time.Minute)
+	assertWindows(t, windows,
+		ScheduleWindow{Start: at(10, 12, 0), End: at(10, 13, 30)},
+		ScheduleWindow{Start: at(11, 10, 0), End: at(11, 12, 0)},
+	)
+}
+
+func TestPlanScheduleWeeklyLimit(t *testing.T) {
+	sch := config.Scheduler{Enabled: true, WeeklyLimitMinutes: 300}
+	for d := 0; d < 7; d++ {
+		sch.Days = append(sch.Days, config.Day{DayOfWeek: d, TimeRanges: []config.TimeRange{{Start: clock(20, 0), End: clock(22, 0)}}})
+	}
+
+	// 4 hours used this week: 1 hour left until Sunday, full budget again on Monday
+	windows := PlanSchedule(sch, scheduleNow, at(14, 0, 0), 0, 4*time.Hour)
+	assertWindows(t, windows,
+		ScheduleWindow{Start: at(10, 20, 0), End: at(10, 21, 0)},
+		ScheduleWindow{Start: at(13, 20, 0), End: at(13, 22, 0)},
+	)
+}
+
+func TestBuildTimelinesGroupSchedule(t *testing.T) {
+	characters := map[string]*config.CharacterCfg{
+		"sorc":   {},
+		"pally":  {},
+		"barb":   {},
+		"amazon": {Scheduler: weekly(time.Friday, config.TimeRange{Start: clock(18, 0), End: clock(19, 0)})},
+	}
+	characters["pally"].Scheduler.DailyLimitMinutes = 30
+
+	groups := []config.ScheduleGroup{{
+		Name:        "farmers",
+		Supervisors: []string{"sorc", "Pally"},
+		Scheduler:   weekly(time.Friday, config.TimeRange{Start: clock(13, 0), End: clock(15, 0)}),
+	}}
+
+	usage := func(supervisor string, from, to time.Time) time.Duration { return 0 }
+	timelines := buildTimelines(characters, groups, scheduleNow, 1, usage)
+	if len(timelines) != 3 {
+		t.Fatalf("Expected 3 scheduled supervisors, got %v", timelines)
+	}
+
+	// Sorted by name: amazon, pally, sorc
+	assertWindows(t, timelines[0].Windows, ScheduleWindow{Start: at(10, 18, 0), End: at(10, 19, 0)})
+	assertWindows(t, timelines[1].Windows, ScheduleWindow{Start: at(10, 13, 0), End: at(10, 13, 30)})
+	assertWindows(t, timelines[2].Windows, ScheduleWindow{Start: at(10, 13, 0), End: at(10, 15, 0)})
+	if timelines[1].Group != "farmers" || timelines[2].Group != "farmers" {
+		t.Errorf("Expected group members to report their group, got %q and %q", timelines[1].Group, timelines[2].Group)
+	}
+}
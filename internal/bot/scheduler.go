@@ -7,10 +7,16 @@ import (
 	"github.com/hectorgimenez/koolo/internal/config"
 )
 
+// maxSchedulerWait is the maximum time between checks, so config changes are applied even if there is no upcoming
+// start or stop.
+const maxSchedulerWait = time.Minute
+
 type Scheduler struct {
 	manager *SupervisorManager
 	logger  *slog.Logger
 	stop    chan struct{}
+	now     func() time.Time
+	usage   PlayTimeUsage
 }
 
 func NewScheduler(manager *SupervisorManager, logger *slog.Logger) *Scheduler {
@@ -18,19 +24,20 @@ func NewScheduler(manager *SupervisorManager, logger *slog.Logger) *Scheduler {
 		manager: manager,
 		logger:  logger,
 		stop:    make(chan struct{}),
+		now:     time.Now,
+		usage:   HistoryPlayTime,
 	}
 }
 
 func (s *Scheduler) Start() {
 	s.logger.Info("Scheduler started")
-	ticker := time.NewTicker(30 * time.Second)
-	defer ticker.Stop()
 
 	for {
+		timer := time.NewTimer(s.checkSchedules())
 		select {
-		case <-ticker.C:
-			s.checkSchedules()
+		case <-timer.C:
 		case <-s.stop:
+			timer.Stop()
 			s.logger.Info("Scheduler stopped")
 			return
 		}
@@ -41,47 +48,34 @@ func (s *Scheduler) Stop() {
 	close(s.stop)
 }
 
-func (s *Scheduler) checkSchedules() {
-	now := time.Now()
-	currentDay := int(now.Weekday())
-
-	for supervisorName, cfg := range config.GetCharacters() {
-		if !cfg.Scheduler.Enabled {
-			continue
-		}
-
-		for _, day := range cfg.Scheduler.Days {
-			if day.DayOfWeek != currentDay {
-				continue
+// checkSchedules starts or stops the scheduled supervisors and returns how long to wait until the next check, the
+// next window start or end.
+func (s *Scheduler) checkSchedules() time.Duration {
+	now := s.now()
+	next := now.Add(maxSchedulerWait)
+
+	// Two days are enough to find the next transition, windows can't be longer than that
+	for _, tl := range buildTimelines(config.GetCharacters(), config.Koolo.ScheduleGroups, now, 2, s.usage) {
+		for _, w := range tl.Windows {
+			if w.Start.After(now) && w.Start.Before(next) {
+				next = w.Start
 			}
-
-			actionTaken := false
-
-			// Check if any time range is active
-			for _, timeRange := range day.TimeRanges {
-				start := time.Date(now.Year(), now.Month(), now.Day(), timeRange.Start.Hour(), timeRange.Start.Minute(), 0, 0, now.Location())
-				end := time.Date(now.Year(), now.Month(), now.Day(), timeRange.End.Hour(), timeRange.End.Minute(), 0, 0, now.Location())
-
-				if now.After(start) && now.Before(end) && s.supervisorNotStarted(supervisorName) {
-					s.logger.Info("Starting supervisor based on schedule. Time range: "+start.Format("15:04")+" - "+end.Format("15:04"), "supervisor", supervisorName)
-					go s.startSupervisor(supervisorName)
-					actionTaken = true
-					break
-				} else if now.After(end) || now == end || now.Before(start) && !s.supervisorNotStarted(supervisorName) {
-					s.logger.Info("Stopping supervisor based on schedule. Time range: "+start.Format("15:04")+" - "+end.Format("15:04"), "supervisor", supervisorName)
-					s.stopSupervisor(supervisorName)
-					actionTaken = true
-					break
-				}
+			if w.End.After(now) && w.End.Before(next) {
+				next = w.End
 			}
+		}
 
-			// To prevent unnecessary checks since it can be only one day.
-			// Also avoid starting/stoping a supervisor in the same minute ... this is rediculous
-			if actionTaken {
-				break
-			}
+		w, active := tl.Active(now)
+		if active && s.supervisorNotStarted(tl.Supervisor) {
+			s.logger.Info("Starting supervisor based on schedule. Time range: "+w.Start.Format("15:04")+" - "+w.End.Format("15:04"), "supervisor", tl.Supervisor, "group", tl.Group)
+			go s.startSupervisor(tl.Supervisor)
+		} else if !active && !s.supervisorNotStarted(tl.Supervisor) {
+			s.logger.Info("Stopping supervisor based on schedule", "supervisor", tl.Supervisor, "group", tl.Group)
+			s.stopSupervisor(tl.Supervisor)
 		}
 	}
+
+	return max(next.Sub(now), time.Second)
 }
 
 func (s *Scheduler) supervisorNotStarted(name string) bool {
@@ -33,6 +33,7 @@ const (
 
 type SinglePlayerSupervisor struct {
 	*baseSupervisor
+	adaptiveRunOrder *AdaptiveRunOrder
 }
 
 func (s *SinglePlayerSupervisor) GetData() *game.Data {
@@ -43,14 +44,15 @@ func (s *SinglePlayerSupervisor) GetContext() *ct.Context {
 	return s.bot.ctx
 }
 
-func NewSinglePlayerSupervisor(name string, bot *Bot, statsHandler *StatsHandler) (*SinglePlayerSupervisor, error) {
-	bs, err := newBaseSupervisor(bot, name, statsHandler)
+func NewSinglePlayerSupervisor(name string, bot *Bot, statsHandler *StatsHandler, gameReader *game.MemoryReader) (*SinglePlayerSupervisor, error) {
+	bs, err := newBaseSupervisor(bot, name, statsHandler, gameReader)
 	if err != nil {
 		return nil, err
 	}
 
 	return &SinglePlayerSupervisor{
-		baseSupervisor: bs,
+		baseSupervisor:   bs,
+		adaptiveRunOrder: NewAdaptiveRunOrder(name, bot.ctx.CharacterCfg, bot.ctx.Logger),
 	}, nil
 }
 
@@ -86,10 +88,18 @@ func (s *SinglePlayerSupervisor) orderRuns(runs []string) []string {
 
 	}
 
-	return runs
+	return s.runOrderStrategy().Order(runs)
 
 }
 
+func (s *SinglePlayerSupervisor) runOrderStrategy() RunOrderStrategy {
+	if s.bot.ctx.CharacterCfg.Game.AdaptiveRuns.Enabled {
+		return s.adaptiveRunOrder
+	}
+
+	return configuredRunOrder{}
+}
+
 func (s *SinglePlayerSupervisor) changeDifficulty(d difficulty.Difficulty) {
 
 	s.bot.ctx.GameReader.GetSelectedCharacterName()
@@ -211,7 +221,8 @@ func (s *SinglePlayerSupervisor) Start() error {
 		gameStart := time.Now()
 		cfg, _ := config.GetCharacter(s.name)
 
-		if cfg.Game.RandomizeRuns {
+		// Adaptive run order already applies its own weighted randomization
+		if cfg.Game.RandomizeRuns && !cfg.Game.AdaptiveRuns.Enabled {
 			rand.Shuffle(len(runs), func(i, j int) { runs[i], runs[j] = runs[j], runs[i] })
 		}
 
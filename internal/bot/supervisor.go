@@ -32,6 +32,7 @@ type baseSupervisor struct {
 	bot          *Bot
 	name         string
 	statsHandler *StatsHandler
+	gameReader   *game.MemoryReader // the game process and window, the context only exposes the game state
 	cancelFn     context.CancelFunc
 }
 
@@ -39,11 +40,13 @@ func newBaseSupervisor(
 	bot *Bot,
 	name string,
 	statsHandler *StatsHandler,
+	gameReader *game.MemoryReader,
 ) (*baseSupervisor, error) {
 	return &baseSupervisor{
 		bot:          bot,
 		name:         name,
 		statsHandler: statsHandler,
+		gameReader:   gameReader,
 	}, nil
 }
 
@@ -78,7 +81,7 @@ func (s *baseSupervisor) Stop() {
 	s.bot.ctx.SwitchPriority(ct.PriorityStop)
 
 	s.bot.ctx.MemoryInjector.Unload()
-	s.bot.ctx.GameReader.Close()
+	s.gameReader.Close()
 
 	if s.bot.ctx.CharacterCfg.KillD2OnStop || s.bot.ctx.CharacterCfg.Scheduler.Enabled {
 		s.KillClient()
@@ -89,7 +92,7 @@ func (s *baseSupervisor) Stop() {
 
 func (s *baseSupervisor) KillClient() error {
 
-	process, err := os.FindProcess(int(s.bot.ctx.GameReader.Process.GetPID()))
+	process, err := os.FindProcess(int(s.gameReader.Process.GetPID()))
 	if err != nil {
 		s.bot.ctx.Logger.Info("Failed to find process", slog.String("configuration", s.name))
 		return err
@@ -151,7 +154,7 @@ func (s *baseSupervisor) waitUntilCharacterSelectionScreen() error {
 
 		// Try to select a character up to 25 times then give up and kill the client
 		for i := 0; i < 25; i++ {
-			characterName := s.bot.ctx.GameReader.GameReader.GetSelectedCharacterName()
+			characterName := s.bot.ctx.GameReader.GetSelectedCharacterName()
 
 			s.bot.ctx.Logger.Debug(fmt.Sprintf("Checking character: %s", characterName))
 
@@ -176,7 +179,7 @@ func (s *baseSupervisor) waitUntilCharacterSelectionScreen() error {
 
 func (s *baseSupervisor) SetWindowPosition(x, y int) {
 	uFlags := win.SWP_NOZORDER | win.SWP_NOSIZE | win.SWP_NOACTIVATE
-	win.SetWindowPos(s.bot.ctx.GameReader.HWND, 0, int32(x), int32(y), 0, 0, uint32(uFlags))
+	win.SetWindowPos(s.gameReader.HWND, 0, int32(x), int32(y), 0, 0, uint32(uFlags))
 }
 
 func (s *baseSupervisor) ensureOnline() error {
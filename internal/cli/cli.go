@@ -0,0 +1,107 @@
+// Package cli implements the koolo commands that don't need the game, so configs, pickit files, droplogs and game
+// recordings can be checked on any platform, like a CI server.
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+)
+
+type command struct {
+	usage string
+	run   func(args []string, stdout, stderr io.Writer) int
+}
+
+const (
+	configValidateUsage = "[-dir <koolo folder>]"
+	pickitLintUsage     = "[-strict] <pickit dir>"
+	pickitTestUsage     = "[-v] [-expect match|partial|nomatch] <nip file> <item.json>"
+	droplogStatsUsage   = "[-dir <droplogs dir>] [-days N] [-supervisor name] [-top N]"
+	journalListUsage    = "[-dir <journal dir>] [-from date] [-to date] [-supervisor name] [-type types]"
+	journalReplayUsage  = "-sink name [-out <logs dir>] [-token T -chat ID] [-delay 2s] [filters of journal list]"
+	mapRenderUsage      = "[-frame N] [-format png|svg] [-scale N] [-radius N] [-to x,y] [-out file] <recording>"
+)
+
+var commands = map[string]map[string]command{
+	"config": {
+		"validate": {configValidateUsage, configValidate},
+	},
+	"pickit": {
+		"lint": {pickitLintUsage, pickitLint},
+		"test": {pickitTestUsage, pickitTest},
+	},
+	"droplog": {
+		"stats": {droplogStatsUsage, droplogStats},
+	},
+	"journal": {
+		"list":   {journalListUsage, journalList},
+		"replay": {journalReplayUsage, journalReplay},
+	},
+	"map": {
+		"render": {mapRenderUsage, mapRender},
+	},
+}
+
+// IsCommand returns true when name is one of the offline command groups
+func IsCommand(name string) bool {
+	_, found := commands[name]
+	return found || name == "help" || name == "-h" || name == "--help"
+}
+
+// Usage prints every available command
+func Usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage:")
+	fmt.Fprintln(w, "  koolo                          Run the bots with the UI window")
+	fmt.Fprintln(w, "  koolo serve [-headless] [-port N]")
+	for _, group := range []string{"config", "pickit", "droplog", "journal", "map"} {
+		for _, name := range sortedKeys(commands[group]) {
+			fmt.Fprintf(w, "  koolo %s %s %s\n", group, name, commands[group][name].usage)
+		}
+	}
+}
+
+// Run executes the command in args (without the program name) and returns the exit code: 0 when everything is fine,
+// 1 when the checks failed and 2 for usage errors.
+func Run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		Usage(stdout)
+		return 0
+	}
+
+	group, found := commands[args[0]]
+	if !found || len(args) < 2 {
+		Usage(stderr)
+		return 2
+	}
+	cmd, found := group[args[1]]
+	if !found {
+		fmt.Fprintf(stderr, "unknown command %q\n\n", args[0]+" "+args[1])
+		Usage(stderr)
+		return 2
+	}
+
+	return cmd.run(args[2:], stdout, stderr)
+}
+
+func newFlagSet(name, usage string, stderr io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: koolo %s %s\n", name, usage)
+		fs.PrintDefaults()
+	}
+
+	return fs
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,188 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/area"
+	"github.com/hectorgimenez/d2go/pkg/data/item"
+	"github.com/hectorgimenez/koolo/internal/event"
+	"github.com/hectorgimenez/koolo/internal/game"
+	"github.com/hectorgimenez/koolo/internal/remote/droplog"
+	"github.com/hectorgimenez/koolo/internal/remote/journal"
+)
+
+func run(t *testing.T, args ...string) (int, string) {
+	t.Helper()
+
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	code := Run(args, stdout, stderr)
+
+	return code, stdout.String() + stderr.String()
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
+
+func TestRunUsage(t *testing.T) {
+	if code, out := run(t, "help"); code != 0 || !strings.Contains(out, "koolo pickit lint") {
+		t.Errorf("Unexpected help output %d: %s", code, out)
+	}
+	if code, _ := run(t, "pickit", "format"); code != 2 {
+		t.Errorf("Expected usage error for unknown command, got %d", code)
+	}
+	if code, _ := run(t, "pickit", "lint"); code != 2 {
+		t.Errorf("Expected usage error for missing folder, got %d", code)
+	}
+}
+
+func TestPickitLint(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "general.nip"), "[type] == ring && [quality] == unique\n// comment\n[name] == ring && [quality] == unique\n")
+	code, out := run(t, "pickit", "lint", dir)
+	if code != 0 || !strings.Contains(out, "general.nip:3: warning:") || !strings.Contains(out, "1 files, 2 rules, 0 errors, 1 warnings") {
+		t.Errorf("Unexpected lint result %d: %s", code, out)
+	}
+	if code, _ = run(t, "pickit", "lint", "-strict", dir); code != 1 {
+		t.Errorf("Expected strict lint to fail on warnings, got %d", code)
+	}
+
+	writeFile(t, filepath.Join(dir, "broken.nip"), "[type] == ring &&& [quality] == unique\n[type] == amulet # [notastat] >= 1\n")
+	code, out = run(t, "pickit", "lint", dir)
+	if code != 1 || !strings.Contains(out, "broken.nip:1: error:") || !strings.Contains(out, "broken.nip:2: error: Unknown stat notastat") {
+		t.Errorf("Unexpected lint result %d: %s", code, out)
+	}
+}
+
+func TestPickitTest(t *testing.T) {
+	dir := t.TempDir()
+	nipFile := filepath.Join(dir, "runes.nip")
+	writeFile(t, nipFile, "[name] == berrune\n[name] == jahrune\n")
+	writeFile(t, filepath.Join(dir, "ber.json"), `{"name": "berrune"}`)
+	writeFile(t, filepath.Join(dir, "runes.json"), `[{"name": "jahrune"}, {"name": "elrune"}]`)
+
+	code, out := run(t, "pickit", "test", "-expect", "match", nipFile, filepath.Join(dir, "ber.json"))
+	if code != 0 || !strings.Contains(out, "berrune: match (runes.nip:1") {
+		t.Errorf("Unexpected test result %d: %s", code, out)
+	}
+
+	code, out = run(t, "pickit", "test", "-expect", "match", nipFile, filepath.Join(dir, "runes.json"))
+	if code != 1 || !strings.Contains(out, "jahrune: match (runes.nip:2") || !strings.Contains(out, "elrune: nomatch") {
+		t.Errorf("Unexpected test result %d: %s", code, out)
+	}
+}
+
+func TestDroplogStats(t *testing.T) {
+	dir := t.TempDir()
+	records := []droplog.Record{
+		{Time: time.Now(), Supervisor: "sorc", Drop: data.Drop{Item: data.Item{Name: "BerRune", Quality: item.QualityNormal}, RuleFile: `C:\pickit\runes.nip:1`}},
+		{Time: time.Now(), Supervisor: "sorc", Drop: data.Drop{Item: data.Item{Name: "Ring", IdentifiedName: "The Stone of Jordan", Quality: item.QualityUnique}, RuleFile: `C:\pickit\uniques.nip:4`}},
+		{Time: time.Now(), Supervisor: "pally", Drop: data.Drop{Item: data.Item{Name: "BerRune", Quality: item.QualityNormal}, RuleFile: `C:\pickit\runes.nip:1`}},
+		{Time: time.Now().AddDate(0, 0, -40), Supervisor: "pally", Drop: data.Drop{Item: data.Item{Name: "JahRune", Quality: item.QualityNormal}}},
+	}
+	var content strings.Builder
+	for _, rec := range records {
+		d, _ := json.Marshal(rec)
+		content.Write(append(d, '\n'))
+	}
+	writeFile(t, filepath.Join(dir, "droplog-2025-01-01.jsonl"), content.String())
+
+	code, out := run(t, "droplog", "stats", "-dir", dir)
+	if code != 0 || !strings.Contains(out, "Items stashed: 4") || !strings.Contains(strings.Join(strings.Fields(out), " "), "runes.nip:1 2") {
+		t.Errorf("Unexpected stats %d: %s", code, out)
+	}
+
+	code, out = run(t, "droplog", "stats", "-dir", dir, "-days", "30", "-supervisor", "sorc")
+	if code != 0 || !strings.Contains(out, "Items stashed: 2") || !strings.Contains(out, "The Stone of Jordan") {
+		t.Errorf("Unexpected stats %d: %s", code, out)
+	}
+}
+
+func TestJournalListAndReplay(t *testing.T) {
+	dir := t.TempDir()
+	journalDir := filepath.Join(dir, "journal")
+	w := journal.NewWriter(journalDir, journal.Options{}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	w.Handle(context.Background(), event.GameCreated(event.Text("sorc", "New game created"), "game-1", "pass"))
+	w.Handle(context.Background(), event.ItemStashed(event.Text("sorc", "Item BerRune stashed"), data.Drop{Item: data.Item{Name: "BerRune"}}))
+	w.Handle(context.Background(), event.ItemStashed(event.Text("pally", "Item JahRune stashed"), data.Drop{Item: data.Item{Name: "JahRune"}}))
+
+	code, out := run(t, "journal", "list", "-dir", journalDir, "-type", "ItemStashed")
+	if code != 0 || !strings.Contains(out, "Item BerRune stashed") || strings.Contains(out, "New game created") || !strings.Contains(out, "2 events") {
+		t.Errorf("Unexpected list output %d: %s", code, out)
+	}
+
+	code, out = run(t, "journal", "replay", "-dir", journalDir, "-sink", "droplog", "-out", dir, "-supervisor", "pally")
+	if code != 0 || !strings.Contains(out, "1 events replayed into droplog, 0 errors") {
+		t.Errorf("Unexpected replay output %d: %s", code, out)
+	}
+	records, err := droplog.ReadAll(filepath.Join(dir, "droplogs"))
+	if err != nil || len(records) != 1 || records[0].Drop.Item.Name != "JahRune" {
+		t.Errorf("Unexpected rebuilt droplog %+v %v", records, err)
+	}
+
+	if code, _ = run(t, "journal", "replay", "-dir", journalDir, "-sink", "nope"); code != 2 {
+		t.Errorf("Expected usage error for an unknown sink, got %d", code)
+	}
+	if code, _ = run(t, "journal", "list", "-dir", journalDir, "-from", "yesterday"); code != 2 {
+		t.Errorf("Expected usage error for an invalid date, got %d", code)
+	}
+}
+
+func TestMapRender(t *testing.T) {
+	cg := make([][]game.CollisionType, 20)
+	for y := range cg {
+		cg[y] = make([]game.CollisionType, 30)
+		for x := range cg[y] {
+			cg[y][x] = game.CollisionTypeWalkable
+		}
+	}
+	grid := &game.Grid{OffsetX: 1000, OffsetY: 2000, Width: 30, Height: 20, CollisionGrid: cg}
+	d := game.Data{Areas: map[area.ID]game.AreaData{area.BloodMoor: {Area: area.BloodMoor, Name: "Blood Moor", Grid: grid}}}
+	d.IsIngame = true
+	d.PlayerUnit.Area = area.BloodMoor
+	d.PlayerUnit.Position = data.Position{X: 1005, Y: 2005}
+
+	dir := t.TempDir()
+	r := game.NewRecorder(dir, "sorc")
+	if err := r.Record(d, 1); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	file := r.File()
+	r.Close()
+
+	code, out := run(t, "map", "render", "-to", "1020,2015", "-format", "svg", file)
+	want := strings.TrimSuffix(file, ".rec.gz") + "-0.svg"
+	if code != 0 || !strings.Contains(out, "Frame 1 of 1, Blood Moor, player at 1005,2005") || !strings.Contains(out, "Map written to "+want) {
+		t.Errorf("Unexpected render output %d: %s", code, out)
+	}
+	if svg, err := os.ReadFile(want); err != nil || !strings.Contains(string(svg), `class="path"`) {
+		t.Errorf("Expected the SVG with the path, got error %v", err)
+	}
+
+	if code, _ = run(t, "map", "render", "-to", "1020", file); code != 2 {
+		t.Errorf("Expected usage error for an invalid destination, got %d", code)
+	}
+	if code, _ = run(t, "map", "render", "-scale", "1000", file); code != 2 {
+		t.Errorf("Expected usage error for a scale out of range, got %d", code)
+	}
+	if code, _ = run(t, "map", "render", "-frame", "5", file); code != 1 {
+		t.Errorf("Expected an error for a missing frame, got %d", code)
+	}
+}
@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/hectorgimenez/koolo/internal/config"
+)
+
+// configValidate loads the configuration the same way koolo does on start and reports every invalid character setting,
+// warnings are reported but don't make a character invalid
+func configValidate(args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("config validate", configValidateUsage, stderr)
+	dir := fs.String("dir", ".", "Koolo folder, the one containing the config folder")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	if *dir != "." {
+		cwd, err := os.Getwd()
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+		if err = os.Chdir(*dir); err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+		defer os.Chdir(cwd)
+	}
+
+	if err := config.Load(); err != nil {
+		fmt.Fprintf(stdout, "Error loading configuration: %v\n", err)
+		return 1
+	}
+
+	characters := config.GetCharacters()
+	invalid := 0
+	for _, name := range sortedKeys(characters) {
+		cfg := characters[name]
+		for _, verr := range cfg.Runtime.ValidationErrors.Warnings() {
+			fmt.Fprintf(stdout, "%s: warning: %s: %s\n", name, verr.Field, verr.Message)
+		}
+		errs := cfg.Runtime.ValidationErrors.Errors()
+		if len(errs) == 0 {
+			fmt.Fprintf(stdout, "%s: ok (%d pickit rules)\n", name, len(cfg.Runtime.Rules))
+			continue
+		}
+
+		invalid++
+		for _, verr := range errs {
+			fmt.Fprintf(stdout, "%s: %s: %s\n", name, verr.Field, verr.Message)
+		}
+	}
+
+	fmt.Fprintf(stdout, "%d characters, %d invalid\n", len(characters), invalid)
+	if invalid > 0 {
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/hectorgimenez/koolo/internal/remote/droplog"
+)
+
+// droplogStats summarizes the stashed items: how many per supervisor and quality, the most common items and the rules
+// keeping them
+func droplogStats(args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("droplog stats", droplogStatsUsage, stderr)
+	dir := fs.String("dir", filepath.Join("logs", "droplogs"), "Droplogs folder")
+	days := fs.Int("days", 0, "Only count the items stashed in the last days, 0 counts everything")
+	supervisor := fs.String("supervisor", "", "Only count the items of this supervisor")
+	top := fs.Int("top", 10, "Number of items and rules to list")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	records, err := droplog.ReadAll(*dir)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	disposed, err := droplog.ReadDisposed(*dir)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+
+	from := time.Time{}
+	if *days > 0 {
+		from = time.Now().AddDate(0, 0, -*days)
+	}
+	keep := func(rec droplog.Record) bool {
+		return (*supervisor == "" || strings.EqualFold(rec.Supervisor, *supervisor)) && !rec.Time.Before(from)
+	}
+
+	bySupervisor := map[string]int{}
+	byQuality := map[string]int{}
+	byItem := map[string]int{}
+	byRule := map[string]int{}
+	total := 0
+	for _, rec := range records {
+		if !keep(rec) {
+			continue
+		}
+		total++
+		itm := rec.Drop.Item
+		name := string(itm.Name)
+		if itm.IdentifiedName != "" {
+			name = itm.IdentifiedName
+		}
+
+		bySupervisor[rec.Supervisor]++
+		byQuality[itm.Quality.ToString()]++
+		byItem[name]++
+		if rec.Drop.RuleFile != "" {
+			byRule[ruleLocation(rec.Drop.RuleFile)]++
+		}
+	}
+	disposedCount := 0
+	for _, rec := range disposed {
+		if keep(rec) {
+			disposedCount++
+		}
+	}
+
+	fmt.Fprintf(stdout, "Items stashed: %d\n", total)
+	fmt.Fprintf(stdout, "Items sold or dropped after being kept: %d\n", disposedCount)
+
+	printCounts(stdout, "Supervisor", bySupervisor, 0)
+	printCounts(stdout, "Quality", byQuality, 0)
+	printCounts(stdout, "Item", byItem, *top)
+	printCounts(stdout, "Rule", byRule, *top)
+
+	return 0
+}
+
+// printCounts prints the counts sorted from highest to lowest, limit 0 prints all of them
+func printCounts(w io.Writer, title string, counts map[string]int, limit int) {
+	keys := sortedKeys(counts)
+	sort.SliceStable(keys, func(i, j int) bool { return counts[keys[i]] > counts[keys[j]] })
+	if limit > 0 && len(keys) > limit {
+		keys = keys[:limit]
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "\n%s\tItems\n", title)
+	for _, k := range keys {
+		fmt.Fprintf(tw, "%s\t%d\n", k, counts[k])
+	}
+	tw.Flush()
+}
+
+// ruleLocation removes the folder from the file:line recorded for a drop
+func ruleLocation(ruleFile string) string {
+	if i := strings.LastIndexAny(ruleFile, `/\`); i >= 0 {
+		return ruleFile[i+1:]
+	}
+
+	return ruleFile
+}
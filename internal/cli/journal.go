@@ -0,0 +1,208 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hectorgimenez/koolo/internal/config"
+	"github.com/hectorgimenez/koolo/internal/event"
+	"github.com/hectorgimenez/koolo/internal/remote/droplog"
+	"github.com/hectorgimenez/koolo/internal/remote/journal"
+	"github.com/hectorgimenez/koolo/internal/remote/stashindex"
+	"github.com/hectorgimenez/koolo/internal/remote/statslog"
+	"github.com/hectorgimenez/koolo/internal/remote/telegram"
+)
+
+// sinkOptions are the replay flags a sink may need
+type sinkOptions struct {
+	out    string // output folder for the log writers
+	token  string
+	chat   string
+	stdout io.Writer
+	logger *slog.Logger
+}
+
+// replaySinks are the handlers a journal can be replayed into, by -sink name
+var replaySinks = map[string]func(opts sinkOptions) (event.Handler, error){
+	"print": func(opts sinkOptions) (event.Handler, error) {
+		return func(_ context.Context, e event.Event) error {
+			rec, err := event.NewRecord(e)
+			if err != nil {
+				return err
+			}
+			printRecord(opts.stdout, rec)
+			return nil
+		}, nil
+	},
+	"droplog": func(opts sinkOptions) (event.Handler, error) {
+		return droplog.NewWriter(filepath.Join(opts.out, "droplogs"), opts.logger).Handle, nil
+	},
+	"statslog": func(opts sinkOptions) (event.Handler, error) {
+		return statslog.NewWriter(filepath.Join(opts.out, "stats"), opts.logger).Handle, nil
+	},
+	"stashindex": func(opts sinkOptions) (event.Handler, error) {
+		return stashindex.NewWriter(filepath.Join(opts.out, "stash"), opts.logger).Handle, nil
+	},
+	"telegram": func(opts sinkOptions) (event.Handler, error) {
+		chatID, err := strconv.ParseInt(opts.chat, 10, 64)
+		if opts.token == "" || err != nil {
+			return nil, errors.New("the telegram sink needs -token and a numeric -chat")
+		}
+		bot, err := telegram.NewBot(opts.token, chatID, opts.logger)
+		if err != nil {
+			return nil, err
+		}
+		return bot.Handle, nil
+	},
+}
+
+// journalFilterFlags adds the flags shared by list and replay
+func journalFilterFlags(fs *flag.FlagSet) func() (string, journal.Filter, error) {
+	dir := fs.String("dir", filepath.Join("logs", "journal"), "Journal folder")
+	from := fs.String("from", "", "Only events since this date (2006-01-02 or RFC3339)")
+	to := fs.String("to", "", "Only events until this date (2006-01-02 or RFC3339), a date includes the whole day")
+	supervisor := fs.String("supervisor", "", "Only events of this supervisor")
+	types := fs.String("type", "", "Only these event types, comma separated (e.g. ItemStashed,GameFinished)")
+
+	return func() (string, journal.Filter, error) {
+		f := journal.Filter{Supervisor: *supervisor}
+		var err error
+		if f.From, err = parseDate(*from, false); err != nil {
+			return "", f, err
+		}
+		if f.To, err = parseDate(*to, true); err != nil {
+			return "", f, err
+		}
+		for _, t := range strings.Split(*types, ",") {
+			if t = strings.TrimSpace(t); t != "" {
+				f.Types = append(f.Types, t)
+			}
+		}
+
+		return *dir, f, nil
+	}
+}
+
+// journalList prints the journaled events matching the filters
+func journalList(args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("journal list", journalListUsage, stderr)
+	filter := journalFilterFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	dir, f, err := filter()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+
+	records, err := journal.Read(dir, f)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	for _, rec := range records {
+		printRecord(stdout, rec)
+	}
+	fmt.Fprintf(stdout, "%d events\n", len(records))
+
+	return 0
+}
+
+// journalReplay feeds the journaled events to one of the event handlers, to rebuild a log or reproduce a notification
+func journalReplay(args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("journal replay", journalReplayUsage, stderr)
+	filter := journalFilterFlags(fs)
+	sink := fs.String("sink", "", "Handler receiving the events: "+strings.Join(sortedKeys(replaySinks), ", "))
+	out := fs.String("out", "logs", "Logs folder written by the droplog, statslog and stashindex sinks")
+	token := fs.String("token", "", "Bot token for the notification sinks")
+	chat := fs.String("chat", "", "Chat or channel ID for the notification sinks")
+	delay := fs.Duration("delay", 0, "Wait between events, e.g. 2s to avoid rate limits")
+	stopOnError := fs.Bool("stop-on-error", false, "Stop at the first handler error")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	newSink, found := replaySinks[*sink]
+	if !found {
+		fmt.Fprintf(stderr, "unknown sink %q\n", *sink)
+		fs.Usage()
+		return 2
+	}
+	dir, f, err := filter()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+
+	// The character details of the rebuilt logs and the notification settings come from the configuration
+	if _, err = os.Stat(filepath.Join("config", "koolo.yaml")); err == nil {
+		if err = config.Load(); err != nil {
+			fmt.Fprintf(stderr, "Error loading configuration, replaying without it: %v\n", err)
+		}
+	}
+
+	logger := slog.New(slog.NewTextHandler(stderr, nil))
+	handler, err := newSink(sinkOptions{out: *out, token: *token, chat: *chat, stdout: stdout, logger: logger})
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+
+	records, err := journal.Read(dir, f)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	result, err := journal.Replay(ctx, dir, records, journal.ReplayOptions{Delay: *delay, StopOnError: *stopOnError}, handler)
+	fmt.Fprintf(stdout, "%d events replayed into %s, %d errors\n", result.Events, *sink, result.Errors)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	if result.Errors > 0 {
+		return 1
+	}
+
+	return 0
+}
+
+func printRecord(w io.Writer, rec event.Record) {
+	line := fmt.Sprintf("%s  %-12s %-28s %s", rec.OccurredAt.Format("2006-01-02 15:04:05"), rec.Supervisor, rec.Type, rec.Message)
+	if rec.Screenshot != "" {
+		line += " [" + rec.Screenshot + "]"
+	}
+	fmt.Fprintln(w, strings.TrimRight(line, " "))
+}
+
+// parseDate accepts a day or a full timestamp, endOfDay moves a day to its last second
+func parseDate(value string, endOfDay bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q, use 2006-01-02 or RFC3339", value)
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+
+	return t, nil
+}
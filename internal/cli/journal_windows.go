@@ -0,0 +1,28 @@
+//go:build windows
+
+package cli
+
+import (
+	"errors"
+
+	"github.com/hectorgimenez/koolo/internal/config"
+	"github.com/hectorgimenez/koolo/internal/event"
+	"github.com/hectorgimenez/koolo/internal/remote/discord"
+)
+
+// Discord depends on the bot packages, so it's only available on Windows
+func init() {
+	replaySinks["discord"] = func(opts sinkOptions) (event.Handler, error) {
+		if opts.token == "" || opts.chat == "" {
+			return nil, errors.New("the discord sink needs -token and -chat")
+		}
+		if config.Koolo == nil {
+			return nil, errors.New("the discord sink uses the message settings of config/koolo.yaml, run it from the Koolo folder")
+		}
+		bot, err := discord.NewBot(opts.token, opts.chat, nil)
+		if err != nil {
+			return nil, err
+		}
+		return bot.Handle, nil
+	}
+}
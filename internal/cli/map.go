@@ -0,0 +1,88 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/koolo/internal/game"
+	"github.com/hectorgimenez/koolo/internal/pather/render"
+)
+
+// mapRender draws the area of a recording frame with the units on it, the rooms visited until then and the path to a
+// destination, to attach a picture to stuck reports
+func mapRender(args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("map render", mapRenderUsage, stderr)
+	frame := fs.Int("frame", -1, "Frame to draw, negative values count from the end (-1 is the last one)")
+	format := fs.String("format", render.FormatPNG, "Image format, png or svg")
+	scale := fs.Int("scale", 2, fmt.Sprintf("Pixels per tile, from 1 to %d", render.MaxScale))
+	radius := fs.Int("radius", 0, "Only draw the tiles up to this distance from the player, 0 draws the whole area")
+	to := fs.String("to", "", "Draw the path from the player to this position, as x,y game coordinates")
+	out := fs.String("out", "", "Output file, next to the recording by default")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 || (*format != render.FormatPNG && *format != render.FormatSVG) || *scale < 1 || *scale > render.MaxScale {
+		fs.Usage()
+		return 2
+	}
+	var dest *data.Position
+	if *to != "" {
+		p, err := render.ParsePosition(*to)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 2
+		}
+		dest = &p
+	}
+
+	rec, err := game.LoadRecording(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	scene, err := render.FromRecording(rec, *frame)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	if *frame < 0 {
+		*frame += len(rec.Frames)
+	}
+	if dest != nil && !scene.PlanPath(*dest) {
+		fmt.Fprintf(stderr, "No path from %d,%d to %d,%d\n", scene.Player.X, scene.Player.Y, dest.X, dest.Y)
+	}
+
+	file := *out
+	if file == "" {
+		file = fmt.Sprintf("%s-%d.%s", strings.TrimSuffix(fs.Arg(0), ".rec.gz"), *frame, *format)
+	}
+	f, err := os.Create(file)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	defer f.Close()
+
+	opts := render.Options{Scale: *scale, Radius: *radius}
+	if *format == render.FormatSVG {
+		err = render.SVG(f, scene, opts)
+	} else {
+		err = render.PNG(f, scene, opts)
+	}
+	if err != nil {
+		f.Close()
+		os.Remove(file)
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+
+	d := rec.Frames[*frame].Data
+	fmt.Fprintf(stdout, "Frame %d of %d, %s, player at %d,%d, %d monsters, %d rooms visited\n", *frame+1, len(rec.Frames),
+		d.PlayerUnit.Area.Area().Name, d.PlayerUnit.Position.X, d.PlayerUnit.Position.Y, len(scene.Monsters), len(scene.VisitedRooms))
+	fmt.Fprintf(stdout, "Map written to %s\n", file)
+
+	return 0
+}
@@ -0,0 +1,168 @@
+package cli
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hectorgimenez/d2go/pkg/nip"
+	"github.com/hectorgimenez/koolo/internal/pickit"
+)
+
+// pickitLint reports the syntax errors of every NIP file in a folder and the findings of the rule analyzer
+func pickitLint(args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("pickit lint", pickitLintUsage, stderr)
+	strict := fs.Bool("strict", false, "Fail on warnings too")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+
+	entries, err := os.ReadDir(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+
+	rules := nip.Rules{}
+	files, errorCount, warningCount := 0, 0, 0
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(strings.ToLower(entry.Name()), ".nip") {
+			continue
+		}
+		files++
+
+		f, err := os.Open(filepath.Join(fs.Arg(0), entry.Name()))
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+		scanner := bufio.NewScanner(f)
+		lineNumber := 0
+		for scanner.Scan() {
+			lineNumber++
+			rule, err := nip.NewRule(scanner.Text(), entry.Name(), lineNumber)
+			if errors.Is(err, nip.ErrEmptyRule) {
+				continue
+			}
+			if err != nil {
+				errorCount++
+				fmt.Fprintf(stdout, "%s:%d: error: %v\n", entry.Name(), lineNumber, err)
+				continue
+			}
+			rules = append(rules, rule)
+		}
+		f.Close()
+		if err = scanner.Err(); err != nil {
+			fmt.Fprintf(stderr, "error reading %s: %v\n", entry.Name(), err)
+			return 1
+		}
+	}
+
+	for _, finding := range pickit.AnalyzeRules(rules) {
+		if finding.Severity == "error" {
+			errorCount++
+		} else {
+			warningCount++
+		}
+		fmt.Fprintf(stdout, "%s:%d: %s: %s [%s]\n", finding.File, finding.Line, finding.Severity, finding.Message, finding.Type)
+	}
+
+	fmt.Fprintf(stdout, "%d files, %d rules, %d errors, %d warnings\n", files, len(rules), errorCount, warningCount)
+	if errorCount > 0 || (*strict && warningCount > 0) {
+		return 1
+	}
+
+	return 0
+}
+
+// pickitTest evaluates the items described in a JSON file (a pickit.SimulatedItem or a list of them) against a NIP file
+func pickitTest(args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("pickit test", pickitTestUsage, stderr)
+	verbose := fs.Bool("v", false, "Show the result of every rule")
+	expect := fs.String("expect", "", "Fail unless every item gets this result: match, partial or nomatch")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 2 || (*expect != "" && *expect != "match" && *expect != "partial" && *expect != "nomatch") {
+		fs.Usage()
+		return 2
+	}
+
+	content, err := os.ReadFile(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	rules, err := pickit.ParseNIPContent(string(content), filepath.Base(fs.Arg(0)))
+	if err != nil {
+		fmt.Fprintln(stdout, err)
+		return 1
+	}
+
+	items, err := readSimulatedItems(fs.Arg(1))
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+
+	failed := false
+	for _, spec := range items {
+		sim, err := pickit.SimulateItem(rules, spec)
+		if err != nil {
+			fmt.Fprintf(stdout, "%s: error: %v\n", spec.Name, err)
+			failed = true
+			continue
+		}
+
+		fmt.Fprintf(stdout, "%s: %s", spec.Name, sim.Result)
+		if sim.MatchedRule != nil {
+			fmt.Fprintf(stdout, " (%s:%d %s)", sim.MatchedRule.File, sim.MatchedRule.Line, sim.MatchedRule.Rule)
+		}
+		fmt.Fprintln(stdout)
+		if *verbose {
+			for _, ev := range sim.Evaluations {
+				fmt.Fprintf(stdout, "  %s:%d %s: %s\n", ev.File, ev.Line, ev.Result, ev.Reason)
+			}
+		}
+
+		if *expect != "" && sim.Result != *expect {
+			failed = true
+		}
+	}
+
+	if failed {
+		return 1
+	}
+
+	return 0
+}
+
+func readSimulatedItems(file string) ([]pickit.SimulatedItem, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	items := []pickit.SimulatedItem{}
+	if strings.HasPrefix(strings.TrimSpace(string(content)), "[") {
+		err = json.Unmarshal(content, &items)
+	} else {
+		item := pickit.SimulatedItem{}
+		err = json.Unmarshal(content, &item)
+		items = append(items, item)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", file, err)
+	}
+
+	return items, nil
+}
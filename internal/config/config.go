@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"path/filepath"
+	"slices"
 	"sync"
 	"time"
 
@@ -36,6 +37,8 @@ type KooloCfg struct {
 		Log         bool `yaml:"log"`
 		Screenshots bool `yaml:"screenshots"`
 		RenderMap   bool `yaml:"renderMap"`
+		// RecordGameData saves the game state read on every tick, to replay real situations in tests
+		RecordGameData bool `yaml:"recordGameData"`
 	} `yaml:"debug"`
 	FirstRun              bool   `yaml:"firstRun"`
 	UseCustomSettings     bool   `yaml:"useCustomSettings"`
@@ -60,6 +63,38 @@ type KooloCfg struct {
 		ChatID  int64  `yaml:"chatId"`
 		Token   string `yaml:"token"`
 	}
+	MapProvider    MapProvider     `yaml:"mapProvider"`
+	Journal        Journal         `yaml:"journal"`
+	ScheduleGroups []ScheduleGroup `yaml:"scheduleGroups"`
+	Webhooks       []Webhook       `yaml:"webhooks"`
+}
+
+// MapProvider selects where the map data of every game comes from, the koolo-map.exe tool by default.
+type MapProvider struct {
+	Type      string `yaml:"type"`      // executable, http or files
+	URL       string `yaml:"url"`       // http: map server URL, {seed} and {difficulty} are replaced by the game ones
+	Dir       string `yaml:"dir"`       // files: folder with the pre-generated <seed>-<difficulty>.json files
+	CacheSize int    `yaml:"cacheSize"` // Max cached games, 0 means 100 and a negative value disables the cache
+	CacheDir  string `yaml:"cacheDir"`  // Defaults to cache/maps
+}
+
+// Journal tunes the event journal kept in logs/journal, used by "koolo journal" to rebuild logs and replay notifications.
+type Journal struct {
+	Screenshots   bool `yaml:"screenshots"`   // Saves the screenshots of the events next to the journal files
+	RetentionDays int  `yaml:"retentionDays"` // Days the journal files are kept, 0 means 14 and a negative value keeps them all
+}
+
+// Webhook is a generic HTTP endpoint notified on the selected events.
+type Webhook struct {
+	Enabled        bool              `yaml:"enabled"`
+	Name           string            `yaml:"name"`
+	URL            string            `yaml:"url"`
+	Events         []string          `yaml:"events"`   // game_created, game_finished, run_started, run_finished, item_stashed, error. Empty means all
+	Template       string            `yaml:"template"` // Go text/template for the request body, JSON payload by default
+	Headers        map[string]string `yaml:"headers"`
+	Secret         string            `yaml:"secret"`     // Signs the body with HMAC-SHA256, sent in the X-Koolo-Signature header
+	MaxRetries     *int              `yaml:"maxRetries"` // Retries of a failed delivery, 3 when not set and 0 disables them
+	TimeoutSeconds int               `yaml:"timeoutSeconds"`
 }
 
 type Day struct {
@@ -70,6 +105,25 @@ type Day struct {
 type Scheduler struct {
 	Enabled bool  `yaml:"enabled"`
 	Days    []Day `yaml:"days"`
+	// Play time budgets in minutes, 0 means no limit. Weeks start on Monday
+	DailyLimitMinutes  int            `yaml:"dailyLimitMinutes"`
+	WeeklyLimitMinutes int            `yaml:"weeklyLimitMinutes"`
+	Overrides          []DateOverride `yaml:"overrides"`
+}
+
+// DateOverride replaces the weekly time ranges for a specific date, it can be used for days off or to schedule
+// around maintenance windows.
+type DateOverride struct {
+	Date       string      `yaml:"date"` // YYYY-MM-DD
+	Off        bool        `yaml:"off"`
+	TimeRanges []TimeRange `yaml:"timeRange"`
+}
+
+// ScheduleGroup shares a schedule between several supervisors, so they are started and stopped together.
+type ScheduleGroup struct {
+	Name        string    `yaml:"name"`
+	Supervisors []string  `yaml:"supervisors"`
+	Scheduler   Scheduler `yaml:"scheduler"`
 }
 
 type TimeRange struct {
@@ -126,6 +180,7 @@ type CharacterCfg struct {
 		StashToShared                bool   `yaml:"stashToShared"`
 		UseTeleport                  bool   `yaml:"useTeleport"`
 		ClearPathDist                int    `yaml:"clearPathDist"`
+		AvoidDangerousMonsters       bool   `yaml:"avoidDangerousMonsters"`
 		ShouldHireAct2MercFrozenAura bool   `yaml:"shouldHireAct2MercFrozenAura"`
 		UseExtraBuffs                bool   `yaml:"useExtraBuffs"`
 		BuffOnNewArea                bool   `yaml:"buffOnNewArea"`
@@ -172,6 +227,12 @@ type CharacterCfg struct {
 		Pindleskin             struct {
 			SkipOnImmunities []stat.Resist `yaml:"skipOnImmunities"`
 		} `yaml:"pindleskin"`
+		AdaptiveRuns struct {
+			Enabled           bool `yaml:"enabled"`
+			LookbackDays      int  `yaml:"lookbackDays"`
+			SkipAfterFailures int  `yaml:"skipAfterFailures"`
+			SkipForMinutes    int  `yaml:"skipForMinutes"`
+		} `yaml:"adaptiveRuns"`
 		Cows struct {
 			OpenChests bool `yaml:"openChests"`
 		} `yaml:"cows"`
@@ -334,6 +395,8 @@ type CharacterCfg struct {
 		Rules     nip.Rules   `yaml:"-"`
 		TierRules []int       `yaml:"-"`
 		Drops     []data.Item `yaml:"-"`
+		// ValidationErrors are the problems found by ValidateCharacter the last time the config was loaded
+		ValidationErrors ValidationErrors `yaml:"-"`
 	} `yaml:"-"`
 }
 
@@ -403,6 +466,12 @@ func Load() error {
 		return fmt.Errorf("error reading config %s: %w", kooloPath, err)
 	}
 
+	recipes, err := LoadCubeRecipes(getAbsPath("config/cube_recipes.yaml"))
+	if err != nil {
+		return err
+	}
+	cubeRecipes, AvailableRecipes = recipes, recipeNames(recipes)
+
 	configDir := getAbsPath("config")
 	entries, err := os.ReadDir(configDir)
 	if err != nil {
@@ -439,12 +508,12 @@ func Load() error {
 		if Koolo.CentralizedPickitPath != "" && charCfg.UseCentralizedPickit {
 			if _, err := os.Stat(Koolo.CentralizedPickitPath); os.IsNotExist(err) {
 				utils.ShowDialog("Error loading pickit rules for "+entry.Name(), "The centralized pickit path does not exist: "+Koolo.CentralizedPickitPath+"\nPlease check your Koolo settings.\nFalling back to local pickit.")
-				pickitPath = getAbsPath(filepath.Join("config", entry.Name(), "pickit")) + "\\"
+				pickitPath = getAbsPath(filepath.Join("config", entry.Name(), "pickit")) + string(os.PathSeparator)
 			} else {
-				pickitPath = Koolo.CentralizedPickitPath + "\\"
+				pickitPath = Koolo.CentralizedPickitPath + string(os.PathSeparator)
 			}
 		} else {
-			pickitPath = getAbsPath(filepath.Join("config", entry.Name(), "pickit")) + "\\"
+			pickitPath = getAbsPath(filepath.Join("config", entry.Name(), "pickit")) + string(os.PathSeparator)
 		}
 
 		rules, err := nip.ReadDir(pickitPath)
@@ -484,7 +553,7 @@ func Load() error {
 							}
 						}
 
-						fallbackRules, _ := nip.ReadDir(tempDir + "\\")
+						fallbackRules, _ := nip.ReadDir(tempDir + string(os.PathSeparator))
 						rules = append(rules, fallbackRules...)
 						os.RemoveAll(tempDir)
 					}
@@ -511,8 +580,9 @@ func Load() error {
 		Characters[entry.Name()] = &charCfg
 	}
 
-	for _, charCfg := range Characters {
+	for name, charCfg := range Characters {
 		charCfg.Validate()
+		charCfg.Runtime.ValidationErrors = ValidateCharacter(name, charCfg, Characters)
 	}
 
 	return nil
@@ -535,7 +605,7 @@ func readSinglePickitFile(filePath string) (nip.Rules, error) {
 		return nil, fmt.Errorf("failed to write to temp pickit file: %w", err)
 	}
 
-	rules, err := nip.ReadDir(tempDir + "\\")
+	rules, err := nip.ReadDir(tempDir + string(os.PathSeparator))
 	if err != nil {
 		return nil, fmt.Errorf("error reading from temp pickit directory %s: %w", tempDir, err)
 	}
@@ -560,7 +630,8 @@ func CreateFromTemplate(name string) error {
 	return Load()
 }
 
-func ValidateAndSaveConfig(config KooloCfg) error {
+// ValidateAndSaveConfig saves the koolo config, source tells the config history what changed it.
+func ValidateAndSaveConfig(config KooloCfg, source string) error {
 	config.D2LoDPath = strings.ReplaceAll(strings.ToLower(config.D2LoDPath), "game.exe", "")
 	config.D2RPath = strings.ReplaceAll(strings.ToLower(config.D2RPath), "d2r.exe", "")
 
@@ -577,7 +648,7 @@ func ValidateAndSaveConfig(config KooloCfg) error {
 		return fmt.Errorf("error parsing koolo config: %w", err)
 	}
 
-	err = os.WriteFile("config/koolo.yaml", text, 0644)
+	err = writeConfigFile("", text, source)
 	if err != nil {
 		return fmt.Errorf("error writing koolo config: %w", err)
 	}
@@ -585,15 +656,16 @@ func ValidateAndSaveConfig(config KooloCfg) error {
 	return Load()
 }
 
-func SaveSupervisorConfig(supervisorName string, config *CharacterCfg) error {
-	filePath := filepath.Join("config", supervisorName, "config.yaml")
+// SaveSupervisorConfig saves the config of a character, source tells the config history what changed it, like
+// "settings" or the run adjusting it.
+func SaveSupervisorConfig(supervisorName string, config *CharacterCfg, source string) error {
 	d, err := yaml.Marshal(config)
 	config.Validate()
 	if err != nil {
 		return err
 	}
 
-	err = os.WriteFile(filePath, d, 0644)
+	err = writeConfigFile(supervisorName, d, source)
 	if err != nil {
 		return fmt.Errorf("error writing supervisor config: %w", err)
 	}
@@ -601,6 +673,19 @@ func SaveSupervisorConfig(supervisorName string, config *CharacterCfg) error {
 	return Load()
 }
 
+// Clone returns a copy that can be edited without affecting the running supervisor, slices edited in place by the
+// settings page are copied too.
+func (c *CharacterCfg) Clone() *CharacterCfg {
+	clone := *c
+	clone.Scheduler.Days = slices.Clone(c.Scheduler.Days)
+	clone.Inventory.InventoryLock = make([][]int, len(c.Inventory.InventoryLock))
+	for i, row := range c.Inventory.InventoryLock {
+		clone.Inventory.InventoryLock[i] = slices.Clone(row)
+	}
+
+	return &clone
+}
+
 func (c *CharacterCfg) Validate() {
 	if c.Character.Class == "nova" || c.Character.Class == "lightsorc" {
 		minThreshold := 65 // Default
@@ -0,0 +1,354 @@
+package config
+
+import (
+	"bytes"
+	_ "embed"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/item"
+	"github.com/hectorgimenez/d2go/pkg/nip"
+	"gopkg.in/yaml.v3"
+)
+
+// cubeCapacity is the number of items fitting in the cube, when all of them take a single cell
+const cubeCapacity = 12
+
+//go:embed cube_recipes.yaml
+var defaultCubeRecipes []byte
+
+var (
+	cubeRecipes []CubeRecipe
+	// AvailableRecipes are the names of the loaded cube recipes, the ones that can be enabled.
+	AvailableRecipes []string
+)
+
+// CubeRecipe is a Horadric Cube recipe, the default ones are in cube_recipes.yaml and can be extended or replaced by
+// config/cube_recipes.yaml.
+type CubeRecipe struct {
+	Name     string              `yaml:"name"`
+	Inputs   []CubeRecipeInput   `yaml:"inputs"`
+	Purchase *CubeRecipePurchase `yaml:"purchase"`
+	// Output is a NIP expression of the transmuted items worth stashing even when no pickit rule keeps them
+	Output string `yaml:"output"`
+
+	outputRule *nip.Rule
+}
+
+// CubeRecipeInput is an item taken from the stash, it has to match every constraint set.
+type CubeRecipeInput struct {
+	Name  string   `yaml:"name"`
+	Names []string `yaml:"names"`
+	// Type is the item type code, like jewl or amul
+	Type string `yaml:"type"`
+	// Quality are the accepted qualities, up to magic when empty so uniques or rares are never used by mistake
+	Quality []string `yaml:"quality"`
+	// The item level is not read from the game, the required level is the closest constraint available
+	MinLevelReq int   `yaml:"minLevelReq"`
+	MaxLevelReq int   `yaml:"maxLevelReq"`
+	Ethereal    *bool `yaml:"ethereal"`
+	Count       int   `yaml:"count"`
+	// SkipPickitMatches never uses the items wanted by the pickit rules, like good jewels
+	SkipPickitMatches bool `yaml:"skipPickitMatches"`
+
+	qualities []item.Quality
+}
+
+// CubeRecipePurchase is an item gambled right before cubing the recipe.
+type CubeRecipePurchase struct {
+	Items   []string `yaml:"items"`
+	Quality string   `yaml:"quality"`
+
+	quality item.Quality
+}
+
+type cubeRecipesFile struct {
+	Recipes []CubeRecipe `yaml:"recipes"`
+}
+
+func init() {
+	recipes, err := ParseCubeRecipes(defaultCubeRecipes)
+	if err != nil {
+		panic(fmt.Sprintf("invalid default cube recipes: %v", err))
+	}
+	cubeRecipes, AvailableRecipes = recipes, recipeNames(recipes)
+}
+
+// GetCubeRecipes returns the loaded cube recipes, they must not be modified.
+func GetCubeRecipes() []CubeRecipe {
+	cfgMux.RLock()
+	defer cfgMux.RUnlock()
+
+	return cubeRecipes
+}
+
+// LoadCubeRecipes returns the default cube recipes merged with the ones of the file, a recipe with the name of a
+// default one replaces it. A missing file only returns the default recipes.
+func LoadCubeRecipes(path string) ([]CubeRecipe, error) {
+	recipes, err := ParseCubeRecipes(defaultCubeRecipes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid default cube recipes: %w", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return recipes, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error loading cube recipes: %w", err)
+	}
+	custom, err := ParseCubeRecipes(content)
+	if err != nil {
+		return nil, fmt.Errorf("error reading cube recipes %s: %w", path, err)
+	}
+
+	for _, r := range custom {
+		if i := slices.IndexFunc(recipes, func(d CubeRecipe) bool { return d.Name == r.Name }); i >= 0 {
+			recipes[i] = r
+		} else {
+			recipes = append(recipes, r)
+		}
+	}
+
+	return recipes, nil
+}
+
+// ParseCubeRecipes reads and validates a recipes file, unknown fields are errors so typos don't go unnoticed. The
+// validation problems are returned as ValidationErrors.
+func ParseCubeRecipes(content []byte) ([]CubeRecipe, error) {
+	var f cubeRecipesFile
+	d := yaml.NewDecoder(bytes.NewReader(content))
+	d.KnownFields(true)
+	if err := d.Decode(&f); err != nil {
+		return nil, err
+	}
+
+	errs := ValidationErrors{}
+	seen := make(map[string]bool)
+	for i := range f.Recipes {
+		field := fmt.Sprintf("recipes[%d]", i)
+		f.Recipes[i].validate(field, &errs)
+		if seen[f.Recipes[i].Name] {
+			errs.add(field+".name", "recipe %q is duplicated", f.Recipes[i].Name)
+		}
+		seen[f.Recipes[i].Name] = true
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	return f.Recipes, nil
+}
+
+// FindInputs picks the items used by the recipe, skip tells the items an input can't use, like the ones matching the
+// pickit rules. Inputs accepting the same items are solved together, so a recipe taking a PerfectAmethyst and any
+// other perfect gem doesn't fail by using the only amethyst as the other gem.
+func (r CubeRecipe) FindInputs(items []data.Item, skip func(CubeRecipeInput, data.Item) bool) ([]data.Item, bool) {
+	accepts := make([][]bool, len(r.Inputs))
+	var slots []int // input of every item needed
+	for i, in := range r.Inputs {
+		accepts[i] = make([]bool, len(items))
+		for j, it := range items {
+			accepts[i][j] = in.Matches(it) && (skip == nil || !skip(in, it))
+		}
+		for c := 0; c < in.count(); c++ {
+			slots = append(slots, i)
+		}
+	}
+
+	// Bipartite matching between the slots and the items, an item taken by a slot is moved to another one accepting
+	// it when it's the only way to fill the new slot
+	usedBy := make([]int, len(items))
+	for i := range usedBy {
+		usedBy[i] = -1
+	}
+	var assign func(slot int, seen []bool) bool
+	assign = func(slot int, seen []bool) bool {
+		for i := range items {
+			if seen[i] || !accepts[slots[slot]][i] {
+				continue
+			}
+			seen[i] = true
+			if usedBy[i] == -1 || assign(usedBy[i], seen) {
+				usedBy[i] = slot
+				return true
+			}
+		}
+		return false
+	}
+	for slot := range slots {
+		if !assign(slot, make([]bool, len(items))) {
+			return nil, false
+		}
+	}
+
+	found := make([]data.Item, len(slots))
+	for i, slot := range usedBy {
+		if slot != -1 {
+			found[slot] = items[i]
+		}
+	}
+
+	return found, true
+}
+
+// UsesItem checks if any input of the recipe accepts the item, without the pickit rules.
+func (r CubeRecipe) UsesItem(it data.Item) bool {
+	return slices.ContainsFunc(r.Inputs, func(in CubeRecipeInput) bool { return in.Matches(it) })
+}
+
+// KeepsOutput checks if a transmuted item matches the output expression.
+func (r CubeRecipe) KeepsOutput(it data.Item) bool {
+	if r.outputRule == nil {
+		return false
+	}
+	result, err := r.outputRule.Evaluate(it)
+
+	return err == nil && result == nip.RuleResultFullMatch
+}
+
+// Matches checks the item against every constraint of the input.
+func (in CubeRecipeInput) Matches(it data.Item) bool {
+	switch {
+	case in.Name != "" && string(it.Name) != in.Name:
+		return false
+	case len(in.Names) > 0 && !slices.Contains(in.Names, string(it.Name)):
+		return false
+	case in.Type != "" && it.Desc().Type != in.Type:
+		return false
+	case len(in.qualities) == 0 && it.Quality > item.QualityMagic:
+		return false
+	case len(in.qualities) > 0 && !slices.Contains(in.qualities, it.Quality):
+		return false
+	case in.MinLevelReq > 0 && it.LevelReq < in.MinLevelReq:
+		return false
+	case in.MaxLevelReq > 0 && it.LevelReq > in.MaxLevelReq:
+		return false
+	case in.Ethereal != nil && it.Ethereal != *in.Ethereal:
+		return false
+	}
+
+	return true
+}
+
+// ItemQuality is the quality of the gambled item, magic by default.
+func (p CubeRecipePurchase) ItemQuality() item.Quality {
+	if p.quality == 0 {
+		return item.QualityMagic
+	}
+
+	return p.quality
+}
+
+func (in CubeRecipeInput) count() int {
+	return max(1, in.Count)
+}
+
+func (r *CubeRecipe) validate(field string, errs *ValidationErrors) {
+	if strings.TrimSpace(r.Name) == "" {
+		errs.add(field+".name", "is required")
+	}
+	if len(r.Inputs) == 0 {
+		errs.add(field+".inputs", "at least one input is required")
+	}
+
+	items := 0
+	for i := range r.Inputs {
+		r.Inputs[i].validate(fmt.Sprintf("%s.inputs[%d]", field, i), errs)
+		items += r.Inputs[i].count()
+	}
+
+	if r.Purchase != nil {
+		items++
+		if len(r.Purchase.Items) == 0 {
+			errs.add(field+".purchase.items", "at least one item is required")
+		}
+		for i, name := range r.Purchase.Items {
+			if !slices.Contains(item.Names, name) {
+				errs.add(fmt.Sprintf("%s.purchase.items[%d]", field, i), "unknown item %q", name)
+			}
+		}
+		if r.Purchase.Quality != "" {
+			q, found := parseQuality(r.Purchase.Quality)
+			if !found {
+				errs.add(field+".purchase.quality", "unknown quality %q", r.Purchase.Quality)
+			}
+			r.Purchase.quality = q
+		}
+	}
+
+	if items > cubeCapacity {
+		errs.add(field+".inputs", "%d items don't fit in the cube, the maximum is %d", items, cubeCapacity)
+	}
+
+	if r.Output != "" {
+		rule, err := nip.NewRule(r.Output, r.Name, 1)
+		if err != nil {
+			errs.add(field+".output", "invalid NIP expression: %v", err)
+		} else {
+			r.outputRule = &rule
+		}
+	}
+}
+
+func (in *CubeRecipeInput) validate(field string, errs *ValidationErrors) {
+	if in.Name == "" && len(in.Names) == 0 && in.Type == "" {
+		errs.add(field, "name, names or type is required")
+	}
+	if in.Name != "" && len(in.Names) > 0 {
+		errs.add(field+".names", "can not be used together with name")
+	}
+	if in.Name != "" && !slices.Contains(item.Names, in.Name) {
+		errs.add(field+".name", "unknown item %q", in.Name)
+	}
+	for i, name := range in.Names {
+		if !slices.Contains(item.Names, name) {
+			errs.add(fmt.Sprintf("%s.names[%d]", field, i), "unknown item %q", name)
+		}
+	}
+	if _, found := item.ItemTypes[in.Type]; in.Type != "" && !found {
+		errs.add(field+".type", "unknown item type %q", in.Type)
+	}
+
+	in.qualities = nil
+	for i, name := range in.Quality {
+		q, found := parseQuality(name)
+		if !found {
+			errs.add(fmt.Sprintf("%s.quality[%d]", field, i), "unknown quality %q", name)
+			continue
+		}
+		in.qualities = append(in.qualities, q)
+	}
+
+	if in.MinLevelReq < 0 || in.MaxLevelReq < 0 {
+		errs.add(field, "required levels can not be negative")
+	} else if in.MaxLevelReq > 0 && in.MinLevelReq > in.MaxLevelReq {
+		errs.add(field+".minLevelReq", "is higher than maxLevelReq")
+	}
+	if in.Count < 0 {
+		errs.add(field+".count", "can not be negative")
+	}
+}
+
+func parseQuality(name string) (item.Quality, bool) {
+	for q := item.QualityLowQuality; q <= item.QualityCrafted; q++ {
+		if strings.EqualFold(q.ToString(), name) {
+			return q, true
+		}
+	}
+
+	return 0, false
+}
+
+func recipeNames(recipes []CubeRecipe) []string {
+	names := make([]string, 0, len(recipes))
+	for _, r := range recipes {
+		names = append(names, r.Name)
+	}
+
+	return names
+}
@@ -0,0 +1,244 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/item"
+)
+
+func cubeItem(name string, quality item.Quality) data.Item {
+	return data.Item{ID: item.GetIDByName(name), Name: item.Name(name), Quality: quality}
+}
+
+func parseRecipe(t *testing.T, content string) CubeRecipe {
+	t.Helper()
+
+	recipes, err := ParseCubeRecipes([]byte(content))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(recipes) != 1 {
+		t.Fatalf("Expected a single recipe, got %d", len(recipes))
+	}
+
+	return recipes[0]
+}
+
+func itemNames(items []data.Item) []string {
+	names := make([]string, 0, len(items))
+	for _, it := range items {
+		names = append(names, string(it.Name))
+	}
+
+	return names
+}
+
+func sortedItemNames(items []data.Item) []string {
+	names := itemNames(items)
+	slices.Sort(names)
+
+	return names
+}
+
+func TestDefaultCubeRecipes(t *testing.T) {
+	recipes, err := ParseCubeRecipes(defaultCubeRecipes)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, name := range []string{"Perfect Amethyst", "Upgrade Cham", "Reroll GrandCharms", "Hitpower Shield"} {
+		if !slices.Contains(AvailableRecipes, name) {
+			t.Errorf("Expected %q to be available", name)
+		}
+	}
+	if len(AvailableRecipes) != len(recipes) {
+		t.Errorf("Expected %d available recipes, got %d", len(recipes), len(AvailableRecipes))
+	}
+}
+
+func TestParseCubeRecipesValidation(t *testing.T) {
+	_, err := ParseCubeRecipes([]byte(`
+recipes:
+  - name: Broken
+    inputs:
+      - {name: TalRune, names: [RalRune]}
+      - {name: NotAnItem, quality: [legendary]}
+      - {type: nope, minLevelReq: 20, maxLevelReq: 10}
+      - {count: -1}
+    purchase: {items: [Amulet, Amulett], quality: shiny}
+    output: "[type] == amulet && [nope] == 1"
+  - name: Too Big
+    inputs:
+      - {name: ElRune, count: 12}
+    purchase: {items: [Ring]}
+  - name: Too Big
+`))
+	var errs ValidationErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("Expected validation errors, got %v", err)
+	}
+	assertFields(t, errs,
+		"recipes[0].inputs[0].names",
+		"recipes[0].inputs[1].name",
+		"recipes[0].inputs[1].quality[0]",
+		"recipes[0].inputs[2].type",
+		"recipes[0].inputs[2].minLevelReq",
+		"recipes[0].inputs[3]",
+		"recipes[0].inputs[3].count",
+		"recipes[0].purchase.items[1]",
+		"recipes[0].purchase.quality",
+		"recipes[0].output",
+		"recipes[1].inputs",
+		"recipes[2].inputs",
+		"recipes[2].name",
+	)
+
+	if _, err = ParseCubeRecipes([]byte("recipes:\n  - name: Typo\n    input:\n      - {name: ElRune}\n")); err == nil {
+		t.Errorf("Expected an error for an unknown field")
+	}
+}
+
+func TestLoadCubeRecipes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cube_recipes.yaml")
+	recipes, err := LoadCubeRecipes(path)
+	if err != nil || len(recipes) != len(AvailableRecipes) {
+		t.Fatalf("Expected the default recipes without the file, got %d, %v", len(recipes), err)
+	}
+
+	custom := `
+recipes:
+  - name: Upgrade El
+    inputs:
+      - {name: ElRune, count: 3, ethereal: false}
+  - name: Downgrade Ral
+    inputs:
+      - {name: RalRune}
+      - {name: ChippedTopaz}
+`
+	if err = os.WriteFile(path, []byte(custom), 0o644); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	recipes, err = LoadCubeRecipes(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(recipes) != len(AvailableRecipes)+1 || recipes[len(recipes)-1].Name != "Downgrade Ral" {
+		t.Errorf("Expected the new recipe after the default ones, got %v", recipeNames(recipes))
+	}
+	i := slices.Index(recipeNames(recipes), "Upgrade El")
+	if in := recipes[i].Inputs[0]; in.Ethereal == nil || *in.Ethereal {
+		t.Errorf("Expected the default recipe to be replaced, got %+v", in)
+	}
+
+	if err = os.WriteFile(path, []byte("recipes:\n  - name: Empty\n"), 0o644); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err = LoadCubeRecipes(path); err == nil {
+		t.Errorf("Expected an error for an invalid recipe")
+	}
+}
+
+func TestCubeRecipeInputMatches(t *testing.T) {
+	r := parseRecipe(t, `
+recipes:
+  - name: Inputs
+    inputs:
+      - {name: Jewel}
+      - {type: amul, quality: [magic, rare], minLevelReq: 10, maxLevelReq: 30}
+      - {names: [Ring, Amulet], ethereal: false}
+`)
+	jewel, amulet, rings := r.Inputs[0], r.Inputs[1], r.Inputs[2]
+
+	ethRing := cubeItem("Ring", item.QualityMagic)
+	ethRing.Ethereal = true
+	lowAmulet, goodAmulet, highAmulet := cubeItem("Amulet", item.QualityRare), cubeItem("Amulet", item.QualityRare), cubeItem("Amulet", item.QualityMagic)
+	lowAmulet.LevelReq, goodAmulet.LevelReq, highAmulet.LevelReq = 5, 20, 31
+
+	tests := []struct {
+		name  string
+		input CubeRecipeInput
+		item  data.Item
+		want  bool
+	}{
+		{"name", jewel, cubeItem("Jewel", item.QualityMagic), true},
+		{"other name", jewel, cubeItem("Ring", item.QualityMagic), false},
+		{"up to magic by default", jewel, cubeItem("Jewel", item.QualityRare), false},
+		{"type and quality", amulet, goodAmulet, true},
+		{"type", amulet, cubeItem("Ring", item.QualityRare), false},
+		{"quality", amulet, cubeItem("Amulet", item.QualityUnique), false},
+		{"min level", amulet, lowAmulet, false},
+		{"max level", amulet, highAmulet, false},
+		{"names", rings, cubeItem("Ring", item.QualityMagic), true},
+		{"ethereal", rings, ethRing, false},
+	}
+	for _, tt := range tests {
+		if got := tt.input.Matches(tt.item); got != tt.want {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestCubeRecipeFindInputs(t *testing.T) {
+	upgrade := parseRecipe(t, "recipes:\n  - name: Upgrade Thul\n    inputs:\n      - {name: ThulRune, count: 3}\n      - {name: ChippedTopaz}\n")
+	stash := []data.Item{cubeItem("ThulRune", item.QualityNormal), cubeItem("ChippedTopaz", item.QualityNormal), cubeItem("ThulRune", item.QualityNormal)}
+	if _, found := upgrade.FindInputs(stash, nil); found {
+		t.Errorf("Expected missing inputs with 2 runes")
+	}
+	stash = append(stash, cubeItem("ThulRune", item.QualityNormal))
+	items, found := upgrade.FindInputs(stash, nil)
+	if want := []string{"ThulRune", "ThulRune", "ThulRune", "ChippedTopaz"}; !found || !slices.Equal(itemNames(items), want) {
+		t.Errorf("Expected %v, got %v", want, itemNames(items))
+	}
+
+	// The any gem input comes first, a greedy pick would use the only amethyst for it. The order of the items taken by
+	// the same input is not relevant, they are sorted to compare them
+	gems := parseRecipe(t, `
+recipes:
+  - name: Gems
+    inputs:
+      - {names: [PerfectAmethyst, PerfectRuby, PerfectTopaz], count: 2}
+      - {name: PerfectAmethyst}
+`)
+	stash = []data.Item{cubeItem("PerfectAmethyst", item.QualityNormal), cubeItem("PerfectRuby", item.QualityNormal), cubeItem("PerfectTopaz", item.QualityNormal)}
+	items, found = gems.FindInputs(stash, nil)
+	if want := []string{"PerfectAmethyst", "PerfectRuby", "PerfectTopaz"}; !found || !slices.Equal(sortedItemNames(items), want) || items[2].Name != "PerfectAmethyst" {
+		t.Errorf("Expected %v, got %v", want, itemNames(items))
+	}
+
+	// Skipped items are not used, like the perfect rubies when rerolling charms
+	skipRuby := func(in CubeRecipeInput, it data.Item) bool { return len(in.Names) > 1 && it.Name == "PerfectRuby" }
+	if _, found = gems.FindInputs(stash, skipRuby); found {
+		t.Errorf("Expected missing inputs without the ruby")
+	}
+	stash = append(stash, cubeItem("PerfectTopaz", item.QualityNormal))
+	items, found = gems.FindInputs(stash, skipRuby)
+	if want := []string{"PerfectTopaz", "PerfectTopaz", "PerfectAmethyst"}; !found || !slices.Equal(itemNames(items), want) {
+		t.Errorf("Expected %v, got %v", want, itemNames(items))
+	}
+}
+
+func TestCubeRecipeOutput(t *testing.T) {
+	r := parseRecipe(t, `
+recipes:
+  - name: Caster Amulet
+    inputs:
+      - {name: RalRune}
+      - {name: PerfectAmethyst}
+      - {name: Jewel, quality: [magic], skipPickitMatches: true}
+    purchase: {items: [Amulet]}
+    output: "[type] == amulet && [quality] == crafted"
+`)
+	if r.Purchase.ItemQuality() != item.QualityMagic {
+		t.Errorf("Expected magic purchases by default, got %v", r.Purchase.ItemQuality())
+	}
+	if !r.KeepsOutput(cubeItem("Amulet", item.QualityCrafted)) || r.KeepsOutput(cubeItem("Ring", item.QualityCrafted)) {
+		t.Errorf("Expected only the crafted amulet to match the output")
+	}
+	if r.UsesItem(cubeItem("Amulet", item.QualityCrafted)) || !r.UsesItem(cubeItem("Jewel", item.QualityMagic)) {
+		t.Errorf("Expected the jewel to be an input and the crafted amulet not")
+	}
+}
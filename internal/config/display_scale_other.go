@@ -0,0 +1,8 @@
+//go:build !windows
+
+package config
+
+// GetCurrentDisplayScale has no display to check outside Windows, the UI is only shown there.
+func GetCurrentDisplayScale() float64 {
+	return 1
+}
@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"os"
 
-	"github.com/lxn/win"
 	cp "github.com/otiai10/copy"
 )
 
@@ -54,11 +53,3 @@ func InstallMod() error {
 
 	return os.WriteFile(Koolo.D2RPath+"\\mods\\koolo\\koolo.mpq\\modinfo.json", modFileContent, 0644)
 }
-
-func GetCurrentDisplayScale() float64 {
-	hDC := win.GetDC(0)
-	defer win.ReleaseDC(0, hDC)
-	dpiX := win.GetDeviceCaps(hDC, win.LOGPIXELSX)
-
-	return float64(dpiX) / 96.0
-}
@@ -0,0 +1,356 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	historyDir          = "config_history"
+	maxConfigSnapshots  = 100
+	snapshotIDFormat    = "20060102T150405.000000000"
+	snapshotSourceFirst = "baseline"
+)
+
+var historyMux sync.Mutex
+
+// ConfigSnapshot is a saved version of a config file. Supervisor is empty for the koolo config.
+type ConfigSnapshot struct {
+	ID         string        `json:"id"`
+	Supervisor string        `json:"supervisor,omitempty"`
+	CreatedAt  time.Time     `json:"createdAt"`
+	Source     string        `json:"source"`
+	Changes    []FieldChange `json:"changes"`
+	Content    string        `json:"content,omitempty"`
+}
+
+// FieldChange is a difference between two configs, Path is the dotted yaml path of the field.
+type FieldChange struct {
+	Path string `json:"path"`
+	Type string `json:"type"` // added, removed or changed
+	Old  string `json:"old,omitempty"`
+	New  string `json:"new,omitempty"`
+}
+
+func configFilePath(supervisor string) string {
+	if supervisor == "" {
+		return filepath.Join("config", "koolo.yaml")
+	}
+
+	return filepath.Join("config", supervisor, "config.yaml")
+}
+
+func snapshotDir(supervisor string) string {
+	if supervisor == "" {
+		return filepath.Join(historyDir, "koolo")
+	}
+
+	return filepath.Join(historyDir, "characters", supervisor)
+}
+
+func validHistoryName(name string) bool {
+	return name != "." && name != ".." && filepath.Base(name) == name && !strings.ContainsAny(name, `/\`)
+}
+
+// writeConfigFile writes the config and snapshots the new content, the previous content is snapshotted first when
+// there is no history yet so the first edit can be rolled back too. Saving is never blocked by a history failure.
+func writeConfigFile(supervisor string, content []byte, source string) error {
+	path := configFilePath(supervisor)
+	previous, _ := os.ReadFile(path)
+
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		return err
+	}
+
+	if !bytes.Equal(previous, content) {
+		_ = recordSnapshot(supervisor, previous, content, source)
+	}
+
+	return nil
+}
+
+func recordSnapshot(supervisor string, previous, content []byte, source string) error {
+	historyMux.Lock()
+	defer historyMux.Unlock()
+
+	dir := snapshotDir(supervisor)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	existing, err := snapshotIDs(dir)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if len(existing) == 0 && len(previous) > 0 {
+		baseline := ConfigSnapshot{Supervisor: supervisor, CreatedAt: now.Add(-time.Nanosecond), Source: snapshotSourceFirst, Changes: []FieldChange{}, Content: string(previous)}
+		if err = writeSnapshot(dir, baseline); err != nil {
+			return err
+		}
+	}
+
+	changes, err := DiffConfigs(previous, content)
+	if err != nil {
+		// Still keep the content, the diff is only informative
+		changes = []FieldChange{}
+	}
+
+	if err = writeSnapshot(dir, ConfigSnapshot{Supervisor: supervisor, CreatedAt: now, Source: source, Changes: changes, Content: string(content)}); err != nil {
+		return err
+	}
+
+	return pruneSnapshots(dir)
+}
+
+func writeSnapshot(dir string, s ConfigSnapshot) error {
+	s.ID = s.CreatedAt.UTC().Format(snapshotIDFormat)
+	d, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(dir, s.ID+".json"), d, 0644)
+}
+
+// snapshotIDs returns the snapshot ids of a directory, oldest first.
+func snapshotIDs(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), ".json") {
+			ids = append(ids, strings.TrimSuffix(e.Name(), ".json"))
+		}
+	}
+	sort.Strings(ids)
+
+	return ids, nil
+}
+
+func pruneSnapshots(dir string) error {
+	ids, err := snapshotIDs(dir)
+	if err != nil {
+		return err
+	}
+
+	for len(ids) > maxConfigSnapshots {
+		if err = os.Remove(filepath.Join(dir, ids[0]+".json")); err != nil {
+			return err
+		}
+		ids = ids[1:]
+	}
+
+	return nil
+}
+
+// ConfigHistory lists the snapshots of a supervisor config (or the koolo config if empty), newest first. Content is
+// not included, use GetConfigSnapshot to retrieve it.
+func ConfigHistory(supervisor string) ([]ConfigSnapshot, error) {
+	if supervisor != "" && !validHistoryName(supervisor) {
+		return nil, fmt.Errorf("invalid supervisor name: %s", supervisor)
+	}
+
+	historyMux.Lock()
+	defer historyMux.Unlock()
+
+	dir := snapshotDir(supervisor)
+	ids, err := snapshotIDs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots := make([]ConfigSnapshot, 0, len(ids))
+	for i := len(ids) - 1; i >= 0; i-- {
+		s, err := readSnapshot(dir, ids[i])
+		if err != nil {
+			return nil, err
+		}
+		s.Content = ""
+		snapshots = append(snapshots, s)
+	}
+
+	return snapshots, nil
+}
+
+// GetConfigSnapshot returns a single snapshot, including the saved config content.
+func GetConfigSnapshot(supervisor, id string) (ConfigSnapshot, error) {
+	if (supervisor != "" && !validHistoryName(supervisor)) || !validHistoryName(id) {
+		return ConfigSnapshot{}, errors.New("invalid snapshot")
+	}
+
+	historyMux.Lock()
+	defer historyMux.Unlock()
+
+	return readSnapshot(snapshotDir(supervisor), id)
+}
+
+func readSnapshot(dir, id string) (ConfigSnapshot, error) {
+	d, err := os.ReadFile(filepath.Join(dir, id+".json"))
+	if err != nil {
+		return ConfigSnapshot{}, fmt.Errorf("error reading config snapshot %s: %w", id, err)
+	}
+
+	s := ConfigSnapshot{}
+	if err = json.Unmarshal(d, &s); err != nil {
+		return ConfigSnapshot{}, fmt.Errorf("error parsing config snapshot %s: %w", id, err)
+	}
+
+	return s, nil
+}
+
+// RestoreConfigSnapshot writes back the content of a snapshot and reloads the config, the restore is snapshotted too.
+func RestoreConfigSnapshot(supervisor, id string) error {
+	s, err := GetConfigSnapshot(supervisor, id)
+	if err != nil {
+		return err
+	}
+
+	// Refuse to restore something that can not be loaded afterward
+	if supervisor == "" {
+		err = yaml.Unmarshal([]byte(s.Content), &KooloCfg{})
+	} else {
+		if _, err = os.Stat(filepath.Join("config", supervisor)); err != nil {
+			return fmt.Errorf("supervisor config %s not found", supervisor)
+		}
+		err = yaml.Unmarshal([]byte(s.Content), &CharacterCfg{})
+	}
+	if err != nil {
+		return fmt.Errorf("snapshot %s is not a valid config: %w", id, err)
+	}
+
+	if err = writeConfigFile(supervisor, []byte(s.Content), "restore "+id); err != nil {
+		return fmt.Errorf("error restoring config: %w", err)
+	}
+
+	return Load()
+}
+
+// DiffCharacterConfigs compares the saved config files of two supervisors.
+func DiffCharacterConfigs(a, b string) ([]FieldChange, error) {
+	if !validHistoryName(a) || !validHistoryName(b) {
+		return nil, errors.New("invalid supervisor name")
+	}
+
+	contentA, err := os.ReadFile(configFilePath(a))
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s config: %w", a, err)
+	}
+	contentB, err := os.ReadFile(configFilePath(b))
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s config: %w", b, err)
+	}
+
+	return DiffConfigs(contentA, contentB)
+}
+
+// DiffConfigs compares two yaml documents field by field. Lists of plain values are compared as a whole, lists of
+// objects item by item.
+func DiffConfigs(old, new []byte) ([]FieldChange, error) {
+	oldFields, err := flattenYAML(old)
+	if err != nil {
+		return nil, err
+	}
+	newFields, err := flattenYAML(new)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make([]string, 0, len(oldFields)+len(newFields))
+	for p := range oldFields {
+		paths = append(paths, p)
+	}
+	for p := range newFields {
+		if _, found := oldFields[p]; !found {
+			paths = append(paths, p)
+		}
+	}
+	sort.Strings(paths)
+
+	changes := make([]FieldChange, 0)
+	for _, p := range paths {
+		o, inOld := oldFields[p]
+		n, inNew := newFields[p]
+		switch {
+		case !inOld:
+			changes = append(changes, FieldChange{Path: p, Type: "added", New: n})
+		case !inNew:
+			changes = append(changes, FieldChange{Path: p, Type: "removed", Old: o})
+		case o != n:
+			changes = append(changes, FieldChange{Path: p, Type: "changed", Old: o, New: n})
+		}
+	}
+
+	return changes, nil
+}
+
+func flattenYAML(content []byte) (map[string]string, error) {
+	fields := make(map[string]string)
+	if len(bytes.TrimSpace(content)) == 0 {
+		return fields, nil
+	}
+
+	var doc any
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, fmt.Errorf("error parsing config: %w", err)
+	}
+	flattenValue("", doc, fields)
+
+	return fields, nil
+}
+
+func flattenValue(path string, v any, fields map[string]string) {
+	switch val := v.(type) {
+	case map[string]any:
+		for k, child := range val {
+			p := k
+			if path != "" {
+				p = path + "." + k
+			}
+			flattenValue(p, child, fields)
+		}
+	case []any:
+		scalars := make([]string, 0, len(val))
+		for _, item := range val {
+			switch item.(type) {
+			case map[string]any, []any:
+				for i, child := range val {
+					flattenValue(fmt.Sprintf("%s[%d]", path, i), child, fields)
+				}
+				return
+			}
+			scalars = append(scalars, scalarString(item))
+		}
+		fields[path] = "[" + strings.Join(scalars, ", ") + "]"
+	default:
+		fields[path] = scalarString(val)
+	}
+}
+
+func scalarString(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case time.Time:
+		return val.Format(time.RFC3339)
+	default:
+		return fmt.Sprint(val)
+	}
+}
@@ -0,0 +1,96 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDiffConfigs(t *testing.T) {
+	old := []byte(`
+game:
+  runs: [pindleskin, mephisto]
+  difficulty: hell
+health:
+  chickenAt: 30
+companion:
+  leader: sorc
+`)
+	new := []byte(`
+game:
+  runs: [pindleskin, andariel]
+  difficulty: hell
+health:
+  chickenAt: 40
+  mercChickenAt: 10
+`)
+
+	changes, err := DiffConfigs(old, new)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []FieldChange{
+		{Path: "companion.leader", Type: "removed", Old: "sorc"},
+		{Path: "game.runs", Type: "changed", Old: "[pindleskin, mephisto]", New: "[pindleskin, andariel]"},
+		{Path: "health.chickenAt", Type: "changed", Old: "30", New: "40"},
+		{Path: "health.mercChickenAt", Type: "added", New: "10"},
+	}
+	if len(changes) != len(expected) {
+		t.Fatalf("Expected %d changes, got %v", len(expected), changes)
+	}
+	for i := range expected {
+		if changes[i] != expected[i] {
+			t.Errorf("Expected change %d to be %+v, got %+v", i, expected[i], changes[i])
+		}
+	}
+}
+
+func TestConfigHistorySnapshots(t *testing.T) {
+	wd, _ := os.Getwd()
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	if err := os.MkdirAll(filepath.Join("config", "sorc"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(configFilePath("sorc"), []byte("game:\n  runs: [pit]\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := writeConfigFile("sorc", []byte("game:\n  runs: [pit, cows]\n"), "test"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	// Saving the same content again doesn't create a new snapshot
+	if err := writeConfigFile("sorc", []byte("game:\n  runs: [pit, cows]\n"), "test"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	history, err := ConfigHistory("sorc")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(history) != 2 {
+		t.Fatalf("Expected baseline and edit snapshots, got %v", history)
+	}
+	if history[0].Source != "test" || len(history[0].Changes) != 1 || history[0].Changes[0].Path != "game.runs" {
+		t.Errorf("Unexpected latest snapshot %+v", history[0])
+	}
+	if history[1].Source != snapshotSourceFirst {
+		t.Errorf("Expected the oldest snapshot to be the baseline, got %+v", history[1])
+	}
+
+	baseline, err := GetConfigSnapshot("sorc", history[1].ID)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if baseline.Content != "game:\n  runs: [pit]\n" {
+		t.Errorf("Unexpected baseline content %q", baseline.Content)
+	}
+
+	if _, err = GetConfigSnapshot("sorc", "../../config/sorc/config"); err == nil {
+		t.Errorf("Expected error for invalid snapshot id")
+	}
+}
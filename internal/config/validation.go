@@ -0,0 +1,256 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/hectorgimenez/d2go/pkg/data/difficulty"
+)
+
+// CharacterClasses are the classes supported by character.BuildCharacter for regular runs, keep them in sync.
+var CharacterClasses = []string{
+	"sorceress", "fireballsorc", "mule", "nova", "hydraorb", "lightsorc", "hammerdin", "foh", "trapsin", "mosaic",
+	"winddruid", "javazon", "berserker",
+}
+
+// LevelingClasses are the classes supported by character.BuildCharacter when the first run is leveling.
+var LevelingClasses = []string{
+	"sorceress_leveling", "necromancer", "paladin", "assassin", "druid_leveling", "amazon_leveling",
+}
+
+var beltColumnTypes = []string{"healing", "mana", "rejuvenation"}
+
+const (
+	inventoryLockRows    = 4
+	inventoryLockColumns = 10
+)
+
+// ValidationError is a config problem, Field is the yaml path of the invalid value, like game.runs[2]. Warnings are
+// advisory, the supervisor still starts with them.
+type ValidationError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+	Warning bool   `json:"warning,omitempty"`
+}
+
+func (e ValidationError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+type ValidationErrors []ValidationError
+
+func (ve ValidationErrors) Error() string {
+	msgs := make([]string, 0, len(ve))
+	for _, e := range ve {
+		msgs = append(msgs, e.Error())
+	}
+
+	return strings.Join(msgs, "; ")
+}
+
+func (ve *ValidationErrors) add(field, format string, args ...any) {
+	*ve = append(*ve, ValidationError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+func (ve *ValidationErrors) warn(field, format string, args ...any) {
+	*ve = append(*ve, ValidationError{Field: field, Message: fmt.Sprintf(format, args...), Warning: true})
+}
+
+// Errors returns the problems preventing the supervisor from starting.
+func (ve ValidationErrors) Errors() ValidationErrors {
+	return ve.filter(false)
+}
+
+// Warnings returns the advisory findings, the supervisor starts with them.
+func (ve ValidationErrors) Warnings() ValidationErrors {
+	return ve.filter(true)
+}
+
+func (ve ValidationErrors) filter(warning bool) ValidationErrors {
+	filtered := ValidationErrors{}
+	for _, e := range ve {
+		if e.Warning == warning {
+			filtered = append(filtered, e)
+		}
+	}
+
+	return filtered
+}
+
+// ValidateCharacter checks the whole character config, profiles are the known supervisor configs used to validate
+// references like mule profiles. Settings the bot can't work with are errors, the ones it can but probably not as
+// intended (duplicated runs, potions never used, unknown recipes that are ignored) are warnings. It doesn't modify
+// the config, use Validate for that.
+func ValidateCharacter(name string, c *CharacterCfg, profiles map[string]*CharacterCfg) ValidationErrors {
+	errs := ValidationErrors{}
+
+	if c.MaxGameLength < 0 {
+		errs.add("maxGameLength", "can not be negative")
+	}
+
+	validateRuns(c, &errs)
+	validateHealth(c, &errs)
+	validateInventory(c, &errs)
+
+	switch c.Game.Difficulty {
+	case difficulty.Normal, difficulty.Nightmare, difficulty.Hell:
+	default:
+		errs.add("game.difficulty", "unknown difficulty %q, allowed values: normal, nightmare, hell", c.Game.Difficulty)
+	}
+
+	for i, recipe := range c.CubeRecipes.EnabledRecipes {
+		if !slices.Contains(AvailableRecipes, recipe) {
+			errs.warn(fmt.Sprintf("cubing.enabledRecipes[%d]", i), "unknown recipe %q, it is ignored", recipe)
+		}
+	}
+	for i, recipe := range c.Game.Leveling.EnabledRunewordRecipes {
+		if !slices.Contains(AvailableRunewordRecipes, recipe) {
+			errs.warn(fmt.Sprintf("game.leveling.enabledRunewordRecipes[%d]", i), "unknown runeword recipe %q, it is ignored", recipe)
+		}
+	}
+
+	if c.Companion.Enabled && !c.Companion.Leader && c.Companion.LeaderName == "" {
+		errs.add("companion.leaderName", "is required for companion followers")
+	}
+
+	validateMuling(name, c, profiles, &errs)
+
+	return errs
+}
+
+func validateRuns(c *CharacterCfg, errs *ValidationErrors) {
+	class := strings.ToLower(c.Character.Class)
+	leveling := len(c.Game.Runs) > 0 && c.Game.Runs[0] == LevelingRun
+
+	seen := make(map[Run]bool)
+	for i, run := range c.Game.Runs {
+		field := fmt.Sprintf("game.runs[%d]", i)
+		if _, found := AvailableRuns[run]; !found {
+			errs.add(field, "unknown run %q", run)
+			continue
+		}
+		if seen[run] {
+			errs.warn(field, "run %q is duplicated", run)
+		}
+		seen[run] = true
+
+		if run == LevelingRun && i > 0 {
+			errs.add(field, "leveling must be the first run")
+		}
+	}
+
+	switch {
+	case class == "":
+		errs.add("character.class", "is required")
+	case leveling && !slices.Contains(LevelingClasses, class):
+		errs.add("character.class", "class %q doesn't support leveling, allowed values: %s", c.Character.Class, strings.Join(LevelingClasses, ", "))
+	case !leveling && slices.Contains(LevelingClasses, class):
+		errs.add("character.class", "class %q is only available when leveling is the first run", c.Character.Class)
+	case !leveling && !slices.Contains(CharacterClasses, class):
+		errs.add("character.class", "unknown class %q, allowed values: %s", c.Character.Class, strings.Join(CharacterClasses, ", "))
+	}
+}
+
+func validateHealth(c *CharacterCfg, errs *ValidationErrors) {
+	percents := []struct {
+		field string
+		value int
+	}{
+		{"health.healingPotionAt", c.Health.HealingPotionAt},
+		{"health.manaPotionAt", c.Health.ManaPotionAt},
+		{"health.rejuvPotionAtLife", c.Health.RejuvPotionAtLife},
+		{"health.rejuvPotionAtMana", c.Health.RejuvPotionAtMana},
+		{"health.mercHealingPotionAt", c.Health.MercHealingPotionAt},
+		{"health.mercRejuvPotionAt", c.Health.MercRejuvPotionAt},
+		{"health.chickenAt", c.Health.ChickenAt},
+		{"health.townChickenAt", c.Health.TownChickenAt},
+		{"health.mercChickenAt", c.Health.MercChickenAt},
+	}
+	for _, p := range percents {
+		if p.value < 0 || p.value > 100 {
+			errs.add(p.field, "must be a percentage between 0 and 100, got %d", p.value)
+		}
+	}
+
+	// Potions are useless if the character chickens before drinking them
+	mustBeBelow := func(chickenField string, chicken int, potionField string, potion int) {
+		if chicken > 0 && potion > 0 && chicken >= potion {
+			errs.warn(chickenField, "should be lower than %s (%d) or the potions are never used, got %d", potionField, potion, chicken)
+		}
+	}
+	mustBeBelow("health.chickenAt", c.Health.ChickenAt, "health.healingPotionAt", c.Health.HealingPotionAt)
+	mustBeBelow("health.chickenAt", c.Health.ChickenAt, "health.rejuvPotionAtLife", c.Health.RejuvPotionAtLife)
+	mustBeBelow("health.mercChickenAt", c.Health.MercChickenAt, "health.mercHealingPotionAt", c.Health.MercHealingPotionAt)
+	mustBeBelow("health.mercChickenAt", c.Health.MercChickenAt, "health.mercRejuvPotionAt", c.Health.MercRejuvPotionAt)
+}
+
+func validateInventory(c *CharacterCfg, errs *ValidationErrors) {
+	if len(c.Inventory.InventoryLock) != inventoryLockRows {
+		errs.add("inventory.inventoryLock", "must have %d rows, got %d", inventoryLockRows, len(c.Inventory.InventoryLock))
+	}
+	for y, row := range c.Inventory.InventoryLock {
+		if len(row) != inventoryLockColumns {
+			errs.add(fmt.Sprintf("inventory.inventoryLock[%d]", y), "must have %d columns, got %d", inventoryLockColumns, len(row))
+		}
+		for x, v := range row {
+			if v != 0 && v != 1 {
+				errs.add(fmt.Sprintf("inventory.inventoryLock[%d][%d]", y, x), "must be 0 (locked) or 1 (unlocked), got %d", v)
+			}
+		}
+	}
+
+	for i, col := range c.Inventory.BeltColumns {
+		if !slices.Contains(beltColumnTypes, strings.ToLower(col)) {
+			errs.add(fmt.Sprintf("inventory.beltColumns[%d]", i), "unknown column type %q, allowed values: %s", col, strings.Join(beltColumnTypes, ", "))
+		}
+	}
+
+	counts := []struct {
+		field string
+		value int
+	}{
+		{"inventory.healingPotionCount", c.Inventory.HealingPotionCount},
+		{"inventory.manaPotionCount", c.Inventory.ManaPotionCount},
+		{"inventory.rejuvPotionCount", c.Inventory.RejuvPotionCount},
+	}
+	for _, cnt := range counts {
+		if cnt.value < 0 {
+			errs.add(cnt.field, "can not be negative")
+		}
+	}
+}
+
+func validateMuling(name string, c *CharacterCfg, profiles map[string]*CharacterCfg, errs *ValidationErrors) {
+	if !c.Muling.Enabled {
+		return
+	}
+
+	// Mules only need to know where to return, farmers need at least one mule
+	if c.Muling.ReturnTo != "" {
+		target, found := profiles[c.Muling.ReturnTo]
+		switch {
+		case !found:
+			errs.add("muling.returnTo", "profile %q doesn't exist", c.Muling.ReturnTo)
+		case strings.EqualFold(target.Character.Class, "mule"):
+			errs.add("muling.returnTo", "profile %q is a mule", c.Muling.ReturnTo)
+		}
+		return
+	}
+
+	if len(c.Muling.MuleProfiles) == 0 {
+		errs.add("muling.muleProfiles", "at least one mule profile is required")
+	}
+	for i, mule := range c.Muling.MuleProfiles {
+		field := fmt.Sprintf("muling.muleProfiles[%d]", i)
+		mcfg, found := profiles[mule]
+		switch {
+		case mule == name:
+			errs.add(field, "a profile can not be its own mule")
+		case !found:
+			errs.add(field, "profile %q doesn't exist", mule)
+		case !strings.EqualFold(mcfg.Character.Class, "mule"):
+			errs.add(field, "profile %q is not a mule, its class is %q", mule, mcfg.Character.Class)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/hectorgimenez/d2go/pkg/data/difficulty"
+)
+
+func validCharacter() *CharacterCfg {
+	c := &CharacterCfg{}
+	c.Character.Class = "sorceress"
+	c.Game.Difficulty = difficulty.Hell
+	c.Game.Runs = []Run{PitRun, CowsRun}
+	c.Health.HealingPotionAt = 75
+	c.Health.RejuvPotionAtLife = 50
+	c.Health.ChickenAt = 30
+	c.Inventory.BeltColumns = BeltColumns{"healing", "healing", "mana", "rejuvenation"}
+	for i := 0; i < inventoryLockRows; i++ {
+		c.Inventory.InventoryLock = append(c.Inventory.InventoryLock, []int{1, 1, 1, 1, 1, 1, 1, 0, 0, 0})
+	}
+
+	return c
+}
+
+func assertFields(t *testing.T, errs ValidationErrors, fields ...string) {
+	t.Helper()
+
+	if len(errs) != len(fields) {
+		t.Fatalf("Expected errors for %v, got %v", fields, errs)
+	}
+	for i, f := range fields {
+		if errs[i].Field != f {
+			t.Errorf("Expected error %d for %s, got %s", i, f, errs[i])
+		}
+	}
+}
+
+func TestValidateCharacterValid(t *testing.T) {
+	assertFields(t, ValidateCharacter("sorc", validCharacter(), nil))
+}
+
+func TestValidateCharacterFieldErrors(t *testing.T) {
+	c := validCharacter()
+	c.Game.Runs = []Run{PitRun, "pitt", PitRun}
+	c.Health.ChickenAt = 80
+	c.Inventory.BeltColumns[2] = "stamina"
+	c.Inventory.InventoryLock[1] = []int{1, 1, 2}
+
+	assertFields(t, ValidateCharacter("sorc", c, nil),
+		"game.runs[1]",
+		"game.runs[2]",
+		"health.chickenAt",
+		"health.chickenAt",
+		"inventory.inventoryLock[1]",
+		"inventory.inventoryLock[1][2]",
+		"inventory.beltColumns[2]",
+	)
+}
+
+func TestValidateCharacterWarnings(t *testing.T) {
+	c := validCharacter()
+	c.Game.Runs = []Run{PitRun, PitRun}
+	c.Health.MercHealingPotionAt = 40
+	c.Health.MercChickenAt = 50
+	c.CubeRecipes.EnabledRecipes = []string{"Perfect Amethyst", "Removed Recipe"}
+
+	errs := ValidateCharacter("sorc", c, nil)
+	assertFields(t, errs.Warnings(), "game.runs[1]", "health.mercChickenAt", "cubing.enabledRecipes[1]")
+	assertFields(t, errs.Errors())
+
+	c.Game.Runs = []Run{PitRun, "pitt"}
+	errs = ValidateCharacter("sorc", c, nil)
+	assertFields(t, errs.Errors(), "game.runs[1]")
+	if len(errs.Warnings()) != 2 {
+		t.Errorf("Expected the warnings to be kept next to the errors, got %v", errs)
+	}
+}
+
+func TestValidateCharacterLevelingClasses(t *testing.T) {
+	c := validCharacter()
+	c.Game.Runs = []Run{LevelingRun}
+	assertFields(t, ValidateCharacter("sorc", c, nil), "character.class")
+
+	c.Character.Class = "paladin"
+	assertFields(t, ValidateCharacter("sorc", c, nil))
+
+	c.Game.Runs = []Run{PitRun, LevelingRun}
+	assertFields(t, ValidateCharacter("sorc", c, nil), "game.runs[1]", "character.class")
+}
+
+func TestValidateCharacterMuling(t *testing.T) {
+	mule := validCharacter()
+	mule.Character.Class = "mule"
+	profiles := map[string]*CharacterCfg{"mule1": mule, "sorc": validCharacter(), "pally": validCharacter()}
+
+	c := validCharacter()
+	c.Muling.Enabled = true
+	c.Muling.MuleProfiles = []string{"mule1", "pally", "missing"}
+	assertFields(t, ValidateCharacter("sorc", c, profiles), "muling.muleProfiles[1]", "muling.muleProfiles[2]")
+
+	mule.Muling.Enabled = true
+	mule.Muling.ReturnTo = "sorc"
+	assertFields(t, ValidateCharacter("mule1", mule, profiles))
+}
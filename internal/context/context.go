@@ -43,11 +43,11 @@ type Context struct {
 	CharacterCfg         *config.CharacterCfg
 	Data                 *game.Data
 	EventListener        *event.Listener
-	HID                  *game.HID
+	HID                  game.InputSender
 	Logger               *slog.Logger
-	Manager              *game.Manager
-	GameReader           *game.MemoryReader
-	MemoryInjector       *game.MemoryInjector
+	Manager              game.GameManager
+	GameReader           game.StateReader
+	MemoryInjector       game.Injector
 	PathFinder           *pather.PathFinder
 	BeltManager          *health.BeltManager
 	HealthManager        *health.Manager
@@ -60,8 +60,9 @@ type Context struct {
 	StopSupervisorFn     StopFunc
 	CleanStopRequested   bool
 	RestartWithCharacter string
-	PacketSender         *game.PacketSender
+	PacketSender         game.PacketSender
 	IsLevelingCharacter  *bool
+	Recorder             *game.Recorder // only set when game data recording is enabled
 }
 
 type Debug struct {
@@ -159,6 +160,11 @@ func (ctx *Context) RefreshGameData() {
 	}
 	ctx.Data.IsLevelingCharacter = *ctx.IsLevelingCharacter
 
+	if ctx.Recorder != nil {
+		if err := ctx.Recorder.Record(*ctx.Data, ctx.GameReader.MapSeed()); err != nil {
+			ctx.Logger.Debug("Error recording game data", slog.Any("error", err))
+		}
+	}
 }
 
 func (ctx *Context) RefreshInventory() {
@@ -0,0 +1,375 @@
+package event
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Policy decides what Publish does when a subscriber queue is full.
+type Policy int
+
+const (
+	// Block waits until the subscriber has room, applying backpressure to the publisher. Meant for sinks that must
+	// not lose events, like the droplog.
+	Block Policy = iota
+	// DropNewest discards the event being published.
+	DropNewest
+	// DropOldest discards the oldest queued event to make room for the new one.
+	DropOldest
+)
+
+func (p Policy) String() string {
+	switch p {
+	case Block:
+		return "block"
+	case DropNewest:
+		return "drop_newest"
+	case DropOldest:
+		return "drop_oldest"
+	}
+
+	return fmt.Sprintf("policy(%d)", int(p))
+}
+
+const defaultBufferSize = 256
+
+// SubscriberOptions tunes how events are queued and delivered to a single subscriber.
+type SubscriberOptions struct {
+	Name       string        // used in logs and stats, defaults to subscriber-N
+	BufferSize int           // queued events before the policy kicks in, defaults to 256
+	Policy     Policy        // what to do when the queue is full
+	Timeout    time.Duration // handler deadline, zero means no timeout
+}
+
+// SubscriberStats are the delivery counters of a single subscriber.
+type SubscriberStats struct {
+	Name      string `json:"name"`
+	Policy    string `json:"policy"`
+	Queued    int    `json:"queued"`
+	Capacity  int    `json:"capacity"`
+	Delivered uint64 `json:"delivered"`
+	Dropped   uint64 `json:"dropped"`
+	Errors    uint64 `json:"errors"`
+	Timeouts  uint64 `json:"timeouts"`
+}
+
+// Bus delivers the published events to every subscriber asynchronously. Each subscriber has its own buffered queue
+// and goroutine, so a slow handler only delays its own events and never the bot that published them (unless its
+// policy is Block and the queue is full).
+type Bus struct {
+	mu          sync.RWMutex
+	subscribers []*Subscription
+	nextID      int
+	logger      atomic.Pointer[slog.Logger]
+
+	ctx     context.Context
+	cancel  context.CancelFunc
+	closing chan struct{}
+	closed  sync.Once
+	wg      sync.WaitGroup
+}
+
+// Subscription is a registered handler, it can be removed from the bus with Unsubscribe.
+type Subscription struct {
+	bus     *Bus
+	handler Handler
+	accepts func(Event) bool
+	opts    SubscriberOptions
+	queue   chan Event
+	done    chan struct{}
+	stop    sync.Once
+
+	delivered atomic.Uint64
+	dropped   atomic.Uint64
+	errors    atomic.Uint64
+	timeouts  atomic.Uint64
+}
+
+func NewBus(logger *slog.Logger) *Bus {
+	ctx, cancel := context.WithCancel(context.Background())
+	b := &Bus{
+		ctx:     ctx,
+		cancel:  cancel,
+		closing: make(chan struct{}),
+	}
+	b.SetLogger(logger)
+
+	return b
+}
+
+// SetLogger replaces the logger used to report handler errors, timeouts and drops.
+func (b *Bus) SetLogger(logger *slog.Logger) {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	b.logger.Store(logger)
+}
+
+// Subscribe registers a handler receiving every event published from now on.
+func (b *Bus) Subscribe(h Handler, opts SubscriberOptions) *Subscription {
+	return b.subscribe(h, nil, opts)
+}
+
+func (b *Bus) subscribe(h Handler, accepts func(Event) bool, opts SubscriberOptions) *Subscription {
+	if opts.BufferSize <= 0 {
+		opts.BufferSize = defaultBufferSize
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.nextID++
+	if opts.Name == "" {
+		opts.Name = fmt.Sprintf("subscriber-%d", b.nextID)
+	}
+	s := &Subscription{
+		bus:     b,
+		handler: h,
+		accepts: accepts,
+		opts:    opts,
+		queue:   make(chan Event, opts.BufferSize),
+		done:    make(chan struct{}),
+	}
+	b.subscribers = append(b.subscribers, s)
+
+	b.wg.Add(1)
+	go s.run()
+
+	return s
+}
+
+// SubscribeTo registers a handler only receiving the events of type T, like SubscribeTo[ItemStashedEvent]. Other
+// events are filtered before being queued, so they don't count against the buffer.
+func SubscribeTo[T Event](b *Bus, h func(ctx context.Context, e T) error, opts SubscriberOptions) *Subscription {
+	handler := func(ctx context.Context, e Event) error {
+		return h(ctx, e.(T))
+	}
+	accepts := func(e Event) bool {
+		_, ok := e.(T)
+		return ok
+	}
+
+	return b.subscribe(handler, accepts, opts)
+}
+
+// Publish queues the event for every subscriber, applying each subscriber policy when its queue is full. Events
+// published after Close are discarded.
+func (b *Bus) Publish(e Event) {
+	select {
+	case <-b.closing:
+		return
+	default:
+	}
+
+	b.mu.RLock()
+	subscribers := make([]*Subscription, len(b.subscribers))
+	copy(subscribers, b.subscribers)
+	b.mu.RUnlock()
+
+	for _, s := range subscribers {
+		s.enqueue(e)
+	}
+}
+
+// Stats returns the counters of every subscriber, in subscription order.
+func (b *Bus) Stats() []SubscriberStats {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	stats := make([]SubscriberStats, 0, len(b.subscribers))
+	for _, s := range b.subscribers {
+		stats = append(stats, s.Stats())
+	}
+
+	return stats
+}
+
+// Close stops accepting events and waits up to timeout for the subscribers to handle what is already queued, then
+// cancels the context of the handlers still running.
+func (b *Bus) Close(timeout time.Duration) {
+	b.closed.Do(func() {
+		close(b.closing)
+
+		drained := make(chan struct{})
+		go func() {
+			b.wg.Wait()
+			close(drained)
+		}()
+
+		select {
+		case <-drained:
+		case <-time.After(timeout):
+			b.log().Warn("event bus closed with pending events", slog.Any("subscribers", b.Stats()))
+		}
+		b.cancel()
+	})
+}
+
+func (b *Bus) log() *slog.Logger {
+	return b.logger.Load()
+}
+
+// Unsubscribe removes the subscription from the bus, queued events are discarded. It doesn't wait for a running
+// handler to return.
+func (s *Subscription) Unsubscribe() {
+	s.stop.Do(func() {
+		close(s.done)
+
+		b := s.bus
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		for i, sub := range b.subscribers {
+			if sub == s {
+				b.subscribers = append(b.subscribers[:i:i], b.subscribers[i+1:]...)
+				break
+			}
+		}
+	})
+}
+
+func (s *Subscription) Stats() SubscriberStats {
+	return SubscriberStats{
+		Name:      s.opts.Name,
+		Policy:    s.opts.Policy.String(),
+		Queued:    len(s.queue),
+		Capacity:  cap(s.queue),
+		Delivered: s.delivered.Load(),
+		Dropped:   s.dropped.Load(),
+		Errors:    s.errors.Load(),
+		Timeouts:  s.timeouts.Load(),
+	}
+}
+
+func (s *Subscription) enqueue(e Event) {
+	if s.accepts != nil && !s.accepts(e) {
+		return
+	}
+
+	switch s.opts.Policy {
+	case DropNewest:
+		select {
+		case s.queue <- e:
+		case <-s.done:
+		default:
+			s.drop(e)
+		}
+
+	case DropOldest:
+		for {
+			select {
+			case s.queue <- e:
+				return
+			case <-s.done:
+				return
+			default:
+			}
+
+			// Queue is full, make room. The subscriber may have taken the oldest one meanwhile, then just retry.
+			select {
+			case old := <-s.queue:
+				s.drop(old)
+			default:
+			}
+		}
+
+	default:
+		select {
+		case s.queue <- e:
+		case <-s.done:
+		case <-s.bus.closing:
+		}
+	}
+}
+
+func (s *Subscription) drop(e Event) {
+	// Log one out of every 100 drops, a stuck subscriber would flood the log otherwise
+	if s.dropped.Add(1)%100 == 1 {
+		s.bus.log().Warn("event subscriber queue is full, dropping events",
+			slog.String("subscriber", s.opts.Name),
+			slog.String("event", fmt.Sprintf("%T", e)),
+			slog.Uint64("dropped", s.dropped.Load()),
+		)
+	}
+}
+
+func (s *Subscription) run() {
+	defer s.bus.wg.Done()
+
+	for {
+		select {
+		case e := <-s.queue:
+			s.deliver(e)
+		case <-s.done:
+			return
+		case <-s.bus.closing:
+			// Drain what was queued before closing, the bus waits for it
+			for {
+				select {
+				case e := <-s.queue:
+					s.deliver(e)
+				case <-s.done:
+					return
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (s *Subscription) deliver(e Event) {
+	ctx := s.bus.ctx
+	if s.opts.Timeout <= 0 {
+		s.result(e, s.call(ctx, e))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.opts.Timeout)
+	defer cancel()
+
+	// The handler keeps running in the background after the timeout, it's expected to stop when ctx is done. Next
+	// events may be delivered while it's still running.
+	result := make(chan error, 1)
+	go func() {
+		result <- s.call(ctx, e)
+	}()
+
+	select {
+	case err := <-result:
+		s.result(e, err)
+	case <-ctx.Done():
+		s.timeouts.Add(1)
+		s.bus.log().Error("event handler timed out",
+			slog.String("subscriber", s.opts.Name),
+			slog.String("event", fmt.Sprintf("%T", e)),
+			slog.Duration("timeout", s.opts.Timeout),
+		)
+	}
+}
+
+func (s *Subscription) call(ctx context.Context, e Event) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("handler panic: %v", r)
+		}
+	}()
+
+	return s.handler(ctx, e)
+}
+
+func (s *Subscription) result(e Event, err error) {
+	s.delivered.Add(1)
+	if err == nil {
+		return
+	}
+
+	s.errors.Add(1)
+	// Silent events are internal bookkeeping, don't bother the user with them
+	if e.Message() != "" {
+		s.bus.log().Error("error running event handler", slog.String("subscriber", s.opts.Name), slog.Any("error", err))
+	}
+}
@@ -0,0 +1,313 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+)
+
+func newTestBus(t *testing.T) *Bus {
+	t.Helper()
+
+	b := NewBus(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	t.Cleanup(func() { b.Close(time.Second) })
+
+	return b
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestBusDeliversInOrder(t *testing.T) {
+	b := newTestBus(t)
+
+	var mu sync.Mutex
+	received := make([]string, 0)
+	b.Subscribe(func(_ context.Context, e Event) error {
+		mu.Lock()
+		defer mu.Unlock()
+		received = append(received, e.Message())
+		return nil
+	}, SubscriberOptions{})
+
+	for _, msg := range []string{"one", "two", "three"} {
+		b.Publish(Text("sorc", msg))
+	}
+	b.Close(time.Second)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(received) != 3 || received[0] != "one" || received[2] != "three" {
+		t.Errorf("Unexpected events %v", received)
+	}
+	if stats := b.Stats(); stats[0].Delivered != 3 || stats[0].Name != "subscriber-1" {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+}
+
+func TestBusTypedSubscription(t *testing.T) {
+	b := newTestBus(t)
+
+	stashed := make(chan ItemStashedEvent, 10)
+	SubscribeTo(b, func(_ context.Context, e ItemStashedEvent) error {
+		stashed <- e
+		return nil
+	}, SubscriberOptions{Name: "stash"})
+
+	b.Publish(GamePaused(Text("sorc", ""), true))
+	b.Publish(ItemStashed(Text("sorc", "stashed"), data.Drop{Item: data.Item{Name: "BerRune"}}))
+	b.Publish(RunStarted(Text("sorc", ""), "mephisto"))
+	b.Close(time.Second)
+
+	if len(stashed) != 1 {
+		t.Fatalf("Expected a single stashed event, got %d", len(stashed))
+	}
+	if e := <-stashed; e.Item.Item.Name != "BerRune" {
+		t.Errorf("Unexpected event %+v", e)
+	}
+	if stats := b.Stats(); stats[0].Delivered != 1 {
+		t.Errorf("Filtered events should not be counted, got %+v", stats[0])
+	}
+}
+
+func TestBusSlowSubscriberDoesNotBlockPublisher(t *testing.T) {
+	b := newTestBus(t)
+
+	started := make(chan struct{}, 10)
+	release := make(chan struct{})
+	fast := make(chan Event, 100)
+	b.Subscribe(func(_ context.Context, _ Event) error {
+		started <- struct{}{}
+		<-release
+		return nil
+	}, SubscriberOptions{Name: "slow", BufferSize: 2, Policy: DropNewest})
+	b.Subscribe(func(_ context.Context, e Event) error {
+		fast <- e
+		return nil
+	}, SubscriberOptions{Name: "fast"})
+
+	b.Publish(Text("sorc", "event"))
+	<-started
+
+	published := make(chan struct{})
+	go func() {
+		for i := 0; i < 9; i++ {
+			b.Publish(Text("sorc", "event"))
+		}
+		close(published)
+	}()
+
+	select {
+	case <-published:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Publisher was blocked by the slow subscriber")
+	}
+	waitFor(t, "fast subscriber", func() bool { return len(fast) == 10 })
+
+	// One event is being handled, two are queued, the rest was dropped
+	if stats := b.Stats(); stats[0].Dropped != 7 || stats[0].Queued != 2 {
+		t.Errorf("Unexpected slow subscriber stats %+v", stats[0])
+	}
+	close(release)
+}
+
+func TestBusDropOldest(t *testing.T) {
+	b := newTestBus(t)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var mu sync.Mutex
+	received := make([]string, 0)
+	b.Subscribe(func(_ context.Context, e Event) error {
+		if e.Message() == "first" {
+			close(started)
+			<-release
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		received = append(received, e.Message())
+		return nil
+	}, SubscriberOptions{BufferSize: 2, Policy: DropOldest})
+
+	b.Publish(Text("sorc", "first"))
+	<-started
+	for _, msg := range []string{"a", "b", "c", "d"} {
+		b.Publish(Text("sorc", msg))
+	}
+	close(release)
+	b.Close(time.Second)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(received) != 3 || received[1] != "c" || received[2] != "d" {
+		t.Errorf("Expected the newest events to be kept, got %v", received)
+	}
+	if stats := b.Stats(); stats[0].Dropped != 2 {
+		t.Errorf("Unexpected stats %+v", stats[0])
+	}
+}
+
+func TestBusBlockPolicyAppliesBackpressure(t *testing.T) {
+	b := newTestBus(t)
+
+	started := make(chan struct{}, 10)
+	release := make(chan struct{})
+	b.Subscribe(func(_ context.Context, _ Event) error {
+		started <- struct{}{}
+		<-release
+		return nil
+	}, SubscriberOptions{BufferSize: 1, Policy: Block})
+
+	b.Publish(Text("sorc", "handled"))
+	<-started
+	b.Publish(Text("sorc", "queued"))
+
+	published := make(chan struct{})
+	go func() {
+		b.Publish(Text("sorc", "blocked"))
+		close(published)
+	}()
+
+	select {
+	case <-published:
+		t.Fatal("Expected the publisher to wait for room in the queue")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	select {
+	case <-published:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Publisher still blocked after the subscriber caught up")
+	}
+	b.Close(time.Second)
+
+	if stats := b.Stats(); stats[0].Delivered != 3 || stats[0].Dropped != 0 {
+		t.Errorf("Unexpected stats %+v", stats[0])
+	}
+}
+
+func TestBusHandlerTimeoutAndErrors(t *testing.T) {
+	b := newTestBus(t)
+
+	b.Subscribe(func(ctx context.Context, e Event) error {
+		switch e.Message() {
+		case "slow":
+			<-ctx.Done()
+			return ctx.Err()
+		case "error":
+			return errors.New("upload failed")
+		case "panic":
+			panic("boom")
+		}
+		return nil
+	}, SubscriberOptions{Timeout: 20 * time.Millisecond})
+
+	for _, msg := range []string{"slow", "error", "panic", "ok"} {
+		b.Publish(Text("sorc", msg))
+	}
+
+	waitFor(t, "every event", func() bool {
+		s := b.Stats()[0]
+		return s.Delivered+s.Timeouts == 4
+	})
+	if stats := b.Stats(); stats[0].Timeouts != 1 || stats[0].Errors != 2 || stats[0].Delivered != 3 {
+		t.Errorf("Unexpected stats %+v", stats[0])
+	}
+}
+
+func TestBusUnsubscribe(t *testing.T) {
+	b := newTestBus(t)
+
+	received := make(chan Event, 10)
+	sub := b.Subscribe(func(_ context.Context, e Event) error {
+		received <- e
+		return nil
+	}, SubscriberOptions{})
+
+	b.Publish(Text("sorc", "before"))
+	waitFor(t, "first event", func() bool { return len(received) == 1 })
+
+	sub.Unsubscribe()
+	sub.Unsubscribe()
+	b.Publish(Text("sorc", "after"))
+	b.Close(time.Second)
+
+	if len(received) != 1 || len(b.Stats()) != 0 {
+		t.Errorf("Expected no events after unsubscribing, got %d", len(received))
+	}
+}
+
+func TestBusPublishAfterClose(t *testing.T) {
+	b := newTestBus(t)
+
+	received := make(chan Event, 10)
+	b.Subscribe(func(_ context.Context, e Event) error {
+		received <- e
+		return nil
+	}, SubscriberOptions{})
+
+	b.Close(time.Second)
+	b.Publish(Text("sorc", "late"))
+
+	if len(received) != 0 {
+		t.Errorf("Expected events published after closing to be discarded")
+	}
+}
+
+func TestListenerWaitForEventConcurrently(t *testing.T) {
+	l := &Listener{bus: newTestBus(t)}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	results := make(chan Event, 20)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results <- l.WaitForEvent(ctx)
+		}()
+	}
+
+	// Keep publishing until every waiter got something, some of them may subscribe late
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	for waiting := true; waiting; {
+		l.bus.Publish(GamePaused(Text("sorc", ""), true))
+		select {
+		case <-done:
+			waiting = false
+		case <-time.After(time.Millisecond):
+		}
+	}
+
+	close(results)
+	for e := range results {
+		if _, ok := e.(GamePausedEvent); !ok {
+			t.Errorf("Unexpected event %T", e)
+		}
+	}
+	if len(l.bus.Stats()) != 0 {
+		t.Errorf("Expected every waiter to unsubscribe, got %+v", l.bus.Stats())
+	}
+}
@@ -7,6 +7,7 @@ import (
 
 type FinishReason string
 type InteractionType string
+type DisposalType string
 
 type Event interface {
 	Message() string
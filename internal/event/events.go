@@ -14,6 +14,9 @@ const (
 	InteractionTypeEntrance InteractionType = "entrance"
 	InteractionTypeNPC      InteractionType = "npc"
 	InteractionTypeObject   InteractionType = "object"
+
+	DisposalSold    DisposalType = "sold"
+	DisposalDropped DisposalType = "dropped"
 )
 
 type UsedPotionEvent struct {
@@ -99,6 +102,35 @@ func ItemBlackListed(be BaseEvent, drop data.Drop) ItemBlackListedEvent {
 	}
 }
 
+// ItemDisposedEvent is sent when an item kept or picked up because of a pickit rule is sold or dropped, Item.RuleFile
+// is the rule responsible for it
+type ItemDisposedEvent struct {
+	BaseEvent
+	Item     data.Drop
+	Disposal DisposalType
+}
+
+func ItemDisposed(be BaseEvent, drop data.Drop, disposal DisposalType) ItemDisposedEvent {
+	return ItemDisposedEvent{
+		BaseEvent: be,
+		Item:      drop,
+		Disposal:  disposal,
+	}
+}
+
+// StashSnapshotEvent contains every item in the stash (personal and shared tabs) and inventory while the stash is open.
+type StashSnapshotEvent struct {
+	BaseEvent
+	Items []data.Item
+}
+
+func StashSnapshot(be BaseEvent, items []data.Item) StashSnapshotEvent {
+	return StashSnapshotEvent{
+		BaseEvent: be,
+		Items:     items,
+	}
+}
+
 func RunStarted(be BaseEvent, runName string) RunStartedEvent {
 	return RunStartedEvent{
 		BaseEvent: be,
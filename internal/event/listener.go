@@ -4,8 +4,6 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
-	"math"
-	"math/rand"
 	"os"
 	"time"
 
@@ -13,85 +11,99 @@ import (
 	"github.com/hectorgimenez/koolo/internal/utils"
 )
 
-var events = make(chan Event)
+// closeTimeout is how long Listen waits for the subscribers to handle the queued events when shutting down
+const closeTimeout = 5 * time.Second
+
+// bus receives every event sent by the supervisors
+var bus = NewBus(slog.Default())
 
 type Listener struct {
-	handlers         []Handler
-	deliveryHandlers map[int]Handler
-	logger           *slog.Logger
+	bus    *Bus
+	logger *slog.Logger
 }
 
 type Handler func(ctx context.Context, e Event) error
 
 func NewListener(logger *slog.Logger) *Listener {
-	return &Listener{
-		logger:           logger,
-		deliveryHandlers: make(map[int]Handler),
+	bus.SetLogger(logger)
+	l := &Listener{
+		bus:    bus,
+		logger: logger,
 	}
+	l.bus.Subscribe(l.saveScreenshot, SubscriberOptions{Name: "screenshots", BufferSize: 16, Policy: DropNewest})
+
+	return l
 }
 
+// Register subscribes a handler to every event. Events are queued without losing any, so the handler only slows down
+// the bots when it's hundreds of events behind.
 func (l *Listener) Register(h Handler) {
-	l.handlers = append(l.handlers, h)
+	l.bus.Subscribe(h, SubscriberOptions{})
+}
+
+// RegisterWith subscribes a handler with custom queue options, like dropping events for a slow remote notifier.
+func (l *Listener) RegisterWith(h Handler, opts SubscriberOptions) *Subscription {
+	return l.bus.Subscribe(h, opts)
+}
+
+// Bus returns the underlying bus, for typed subscriptions with SubscribeTo.
+func (l *Listener) Bus() *Bus {
+	return l.bus
 }
 
+// Listen blocks until ctx is done, then gives the subscribers some time to handle the pending events.
 func (l *Listener) Listen(ctx context.Context) error {
-	for {
+	<-ctx.Done()
+	l.bus.Close(closeTimeout)
+
+	return nil
+}
+
+// WaitForEvent returns the next sent event, or nil when ctx is done first.
+func (l *Listener) WaitForEvent(ctx context.Context) Event {
+	evtChan := make(chan Event, 1)
+	sub := l.bus.Subscribe(func(_ context.Context, e Event) error {
 		select {
-		case e := <-events:
-			if _, err := os.Stat("screenshots"); os.IsNotExist(err) {
-				err = os.MkdirAll("screenshots", os.ModePerm)
-				if err != nil {
-					l.logger.Error("error creating screenshots directory", slog.Any("error", err))
-				}
-			}
-
-			if e.Image() != nil && config.Koolo.Debug.Screenshots {
-				fileName := fmt.Sprintf("screenshots/error-%s.jpeg", time.Now().Format("2006-01-02 15_04_05"))
-				err := utils.SaveImageJPEG(e.Image(), fileName)
-				if err != nil {
-					l.logger.Error("error saving screenshot", slog.Any("error", err))
-				}
-			}
-
-			for _, h := range l.handlers {
-				if err := h(ctx, e); err != nil && e.Message() != "" {
-					l.logger.Error("error running event handler", slog.Any("error", err))
-				}
-			}
-			for _, h := range l.deliveryHandlers {
-				if err := h(ctx, e); err != nil {
-					l.logger.Error("error running event delivery handler", slog.Any("error", err))
-				}
-			}
-
-		case <-ctx.Done():
-			return nil
+		case evtChan <- e:
+		default:
 		}
+		return nil
+	}, SubscriberOptions{Name: "wait-for-event", BufferSize: 1, Policy: DropNewest})
+	defer sub.Unsubscribe()
+
+	select {
+	case e := <-evtChan:
+		return e
+	case <-ctx.Done():
+		return nil
 	}
 }
 
-func (l *Listener) WaitForEvent(ctx context.Context) Event {
-	evtChan := make(chan Event)
-	idx := rand.Intn(math.MaxInt64)
-	l.deliveryHandlers[idx] = func(ctx context.Context, e Event) error {
-		evtChan <- e
+func (l *Listener) saveScreenshot(_ context.Context, e Event) error {
+	if e.Image() == nil || !config.Koolo.Debug.Screenshots {
 		return nil
 	}
-	// Clean up the handler when we're done
-	defer func() {
-		delete(l.deliveryHandlers, idx)
-	}()
 
-	for {
-		select {
-		case e := <-evtChan:
-			return e
-		case <-ctx.Done():
-			return nil
+	if _, err := os.Stat("screenshots"); os.IsNotExist(err) {
+		if err = os.MkdirAll("screenshots", os.ModePerm); err != nil {
+			return fmt.Errorf("error creating screenshots directory: %w", err)
 		}
 	}
+
+	fileName := fmt.Sprintf("screenshots/error-%s.jpeg", time.Now().Format("2006-01-02 15_04_05"))
+	if err := utils.SaveImageJPEG(e.Image(), fileName); err != nil {
+		return fmt.Errorf("error saving screenshot: %w", err)
+	}
+
+	return nil
 }
 
+// Send publishes the event to every subscriber without waiting for them to handle it.
 func Send(e Event) {
-	events <- e
+	bus.Publish(e)
+}
+
+// Stats returns the delivery counters of every subscriber.
+func Stats() []SubscriberStats {
+	return bus.Stats()
 }
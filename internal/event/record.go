@@ -0,0 +1,103 @@
+package event
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"reflect"
+	"time"
+)
+
+// recordTypes are the events that can be persisted and rebuilt, by type name
+var recordTypes = map[string]reflect.Type{}
+
+func init() {
+	for _, e := range []Event{
+		BaseEvent{},
+		UsedPotionEvent{},
+		GameCreatedEvent{},
+		GameFinishedEvent{},
+		RunStartedEvent{},
+		RunFinishedEvent{},
+		ItemStashedEvent{},
+		ItemBlackListedEvent{},
+		ItemDisposedEvent{},
+		StashSnapshotEvent{},
+		CompanionLeaderAttackEvent{},
+		CompanionRequestedTPEvent{},
+		InteractedToEvent{},
+		GamePausedEvent{},
+		RequestCompanionJoinGameEvent{},
+		ResetCompanionGameInfoEvent{},
+		CharacterSwitchEvent{},
+	} {
+		t := reflect.TypeOf(e)
+		recordTypes[t.Name()] = t
+	}
+}
+
+// Record is the serializable form of an event, the payload contains the fields of the concrete event type.
+type Record struct {
+	Type       string          `json:"type"`
+	Supervisor string          `json:"supervisor"`
+	Message    string          `json:"message,omitempty"`
+	OccurredAt time.Time       `json:"occurredAt"`
+	Screenshot string          `json:"screenshot,omitempty"` // file name of the screenshot, saved by the writer
+	Payload    json.RawMessage `json:"payload,omitempty"`
+}
+
+// NewRecord serializes the event, the screenshot is not included.
+func NewRecord(e Event) (Record, error) {
+	t := reflect.TypeOf(e)
+	if _, found := recordTypes[t.Name()]; !found || t.Kind() != reflect.Struct {
+		return Record{}, fmt.Errorf("unknown event type %T", e)
+	}
+
+	rec := Record{
+		Type:       t.Name(),
+		Supervisor: e.Supervisor(),
+		Message:    e.Message(),
+		OccurredAt: e.OccurredAt(),
+	}
+	if t.Name() == "BaseEvent" {
+		return rec, nil
+	}
+
+	payload, err := json.Marshal(e)
+	if err != nil {
+		return Record{}, fmt.Errorf("error encoding %s: %w", rec.Type, err)
+	}
+	if string(payload) != "{}" {
+		rec.Payload = payload
+	}
+
+	return rec, nil
+}
+
+// Event rebuilds the original event, keeping the original time, with img as screenshot (it can be nil).
+func (r Record) Event(img image.Image) (Event, error) {
+	t, found := recordTypes[r.Type]
+	if !found {
+		return nil, fmt.Errorf("unknown event type %s", r.Type)
+	}
+
+	base := BaseEvent{
+		message:    r.Message,
+		image:      img,
+		occurredAt: r.OccurredAt,
+		supervisor: r.Supervisor,
+	}
+	if t == reflect.TypeOf(base) {
+		return base, nil
+	}
+
+	v := reflect.New(t)
+	if len(r.Payload) > 0 {
+		if err := json.Unmarshal(r.Payload, v.Interface()); err != nil {
+			return nil, fmt.Errorf("error decoding %s: %w", r.Type, err)
+		}
+	}
+	v.Elem().FieldByName("BaseEvent").Set(reflect.ValueOf(base))
+
+	return v.Elem().Interface().(Event), nil
+}
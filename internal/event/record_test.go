@@ -0,0 +1,87 @@
+package event
+
+import (
+	"encoding/json"
+	"image"
+	"testing"
+	"time"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/item"
+)
+
+type unknownEvent struct {
+	BaseEvent
+}
+
+func roundTrip(t *testing.T, e Event) Event {
+	t.Helper()
+
+	rec, err := NewRecord(e)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	d, err := json.Marshal(rec)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var decoded Record
+	if err = json.Unmarshal(d, &decoded); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	out, err := decoded.Event(nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	return out
+}
+
+func TestRecordRoundTrip(t *testing.T) {
+	occurred := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	base := Text("sorc", "Item stashed")
+	base.occurredAt = occurred
+
+	stashed := ItemStashed(base, data.Drop{Item: data.Item{Name: "BerRune", Quality: item.QualityNormal}, RuleFile: "runes.nip:1"})
+	out, ok := roundTrip(t, stashed).(ItemStashedEvent)
+	if !ok {
+		t.Fatalf("Expected an ItemStashedEvent")
+	}
+	if out.Item.Item.Name != "BerRune" || out.Item.RuleFile != "runes.nip:1" || out.Supervisor() != "sorc" ||
+		out.Message() != "Item stashed" || !out.OccurredAt().Equal(occurred) {
+		t.Errorf("Unexpected event %+v", out)
+	}
+
+	potion, ok := roundTrip(t, UsedPotion(base, data.HealingPotion, true)).(UsedPotionEvent)
+	if !ok || potion.PotionType != data.HealingPotion || !potion.OnMerc {
+		t.Errorf("Unexpected event %+v", potion)
+	}
+
+	if _, ok = roundTrip(t, base).(BaseEvent); !ok {
+		t.Errorf("Expected a BaseEvent")
+	}
+	if _, ok = roundTrip(t, CompanionRequestedTP(base)).(CompanionRequestedTPEvent); !ok {
+		t.Errorf("Expected a CompanionRequestedTPEvent")
+	}
+}
+
+func TestRecordScreenshotAndErrors(t *testing.T) {
+	rec, err := NewRecord(GameFinished(Text("sorc", "died"), FinishedDied))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, 1, 1))
+	e, err := rec.Event(img)
+	if err != nil || e.Image() != img {
+		t.Errorf("Expected the screenshot to be attached, got %v", err)
+	}
+
+	if _, err = NewRecord(unknownEvent{}); err == nil {
+		t.Errorf("Expected an error for an unknown event type")
+	}
+	if _, err = (Record{Type: "NotAnEvent"}).Event(nil); err == nil {
+		t.Errorf("Expected an error for an unknown record type")
+	}
+}
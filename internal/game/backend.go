@@ -0,0 +1,84 @@
+package game
+
+import (
+	"image"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/skill"
+)
+
+// The interfaces below are everything the bot needs from the game client. HID, MemoryReader, MemoryInjector and
+// ProcessPacketSender implement them for the real game on Windows, other implementations (like the sim package) can
+// drive the actions and runs without a game client.
+
+// InputSender sends the mouse and keyboard inputs to the game.
+type InputSender interface {
+	MovePointer(x, y int)
+	Click(btn MouseButton, x, y int)
+	ClickWithModifier(btn MouseButton, x, y int, modifier ModifierKey)
+	PressKey(key byte)
+	KeySequence(keysToPress ...byte)
+	PressKeyWithModifier(key byte, modifier ModifierKey)
+	PressKeyBinding(kb data.KeyBinding)
+	KeyDown(kb data.KeyBinding)
+	KeyUp(kb data.KeyBinding)
+}
+
+// StateReader reads the game state.
+type StateReader interface {
+	GetData() Data
+	GetInventory() data.Inventory
+	HoveredData() data.HoverData
+	MapSeed() uint
+	FetchMapData() error
+	// GameAreaSize returns the size in pixels of the game window client area, used to translate game coords to screen.
+	GameAreaSize() (width, height int)
+	InGame() bool
+	IsOnline() bool
+	IsInLobby() bool
+	IsInCharacterSelectionScreen() bool
+	IsInCharacterCreationScreen() bool
+	IsDismissableModalPresent() (bool, string)
+	LegacyGraphics() bool
+	GetPanel(panelPath ...string) data.Panel
+	GetMercList() []MercOption
+	GetSelectedCharacterName() string
+	LastGameName() string
+	LastGamePass() string
+	Screenshot() image.Image
+}
+
+// PacketSender sends the packets used to interact with the game without inputs.
+type PacketSender interface {
+	SendPacket(packet []byte) error
+	PickUpItem(item data.Item) error
+	InteractWithTp(object data.Object) error
+	InteractWithEntrance(entrance data.Entrance) error
+}
+
+// Injector patches the game process to accept the inputs while the window is not focused.
+type Injector interface {
+	Load() error
+	Unload() error
+	RestoreMemory() error
+}
+
+// GameManager creates and leaves the games.
+type GameManager interface {
+	InGame() bool
+	ExitGame() error
+	NewGame() error
+	CreateLobbyGame(gameCounter int) (string, error)
+	JoinOnlineGame(gameName, password string) error
+}
+
+// MercOption is a mercenary listed in the hire menu.
+type MercOption struct {
+	Index   int
+	Name    string
+	Skill   skill.Skill
+	Level   int
+	Life    int
+	Defense int
+	Cost    int
+}
@@ -1,3 +1,5 @@
+//go:build windows
+
 package game
 
 type HID struct {
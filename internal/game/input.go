@@ -0,0 +1,37 @@
+package game
+
+import "github.com/hectorgimenez/d2go/pkg/data"
+
+// Values match the win32 ones (MK_RBUTTON, MK_LBUTTON, VK_SHIFT and VK_CONTROL), they are sent as is to the game
+// window. They are declared here so the recordings can be replayed on any platform.
+const (
+	RightButton MouseButton = 0x0002
+	LeftButton  MouseButton = 0x0001
+
+	ShiftKey ModifierKey = 0x10
+	CtrlKey  ModifierKey = 0x11
+)
+
+// Virtual key codes of the keys used to navigate the game menus and dialogs, same values as the win32 VK_ ones.
+const (
+	KeyReturn    = 0x0D
+	KeyEscape    = 0x1B
+	KeySpace     = 0x20
+	KeyEnd       = 0x23
+	KeyHome      = 0x24
+	KeyArrowUp   = 0x26
+	KeyArrowDown = 0x28
+	KeyF1        = 0x70
+	KeyF2        = 0x71
+)
+
+type MouseButton uint
+type ModifierKey byte
+
+func getKeysForKB(kb data.KeyBinding) [2]byte {
+	if kb.Key1[0] == 0 || kb.Key1[0] == 255 {
+		return [2]byte{kb.Key2[0], kb.Key2[1]}
+	}
+
+	return [2]byte{kb.Key1[0], kb.Key1[1]}
+}
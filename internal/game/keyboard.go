@@ -1,3 +1,5 @@
+//go:build windows
+
 package game
 
 import (
@@ -59,14 +61,6 @@ func (hid *HID) KeyUp(kb data.KeyBinding) {
 	win.PostMessage(hid.gr.HWND, win.WM_KEYUP, uintptr(keys[0]), hid.calculatelParam(keys[0], false))
 }
 
-func getKeysForKB(kb data.KeyBinding) [2]byte {
-	if kb.Key1[0] == 0 || kb.Key1[0] == 255 {
-		return [2]byte{kb.Key2[0], kb.Key2[1]}
-	}
-
-	return [2]byte{kb.Key1[0], kb.Key1[1]}
-}
-
 func (hid *HID) GetASCIICode(key string) byte {
 	char, found := specialChars[strings.ToLower(key)]
 	if found {
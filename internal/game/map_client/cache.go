@@ -0,0 +1,168 @@
+package map_client
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hectorgimenez/d2go/pkg/data/difficulty"
+)
+
+const cacheFileExt = ".json.gz"
+
+// CachedProvider keeps the map data of the last games on disk, gzip compressed, so a repeated seed (leveling restarts,
+// companion games) doesn't generate the maps again. Only the MaxEntries most recently used seeds are kept.
+type CachedProvider struct {
+	Provider   Provider
+	Dir        string
+	MaxEntries int
+
+	mu       sync.Mutex
+	inFlight map[string]*fetchCall
+}
+
+// fetchCall is a map generation in progress, the concurrent requests of the same seed wait for it
+type fetchCall struct {
+	done chan struct{}
+	data MapData
+	err  error
+}
+
+func NewCachedProvider(p Provider, dir string, maxEntries int) *CachedProvider {
+	return &CachedProvider{
+		Provider:   p,
+		Dir:        dir,
+		MaxEntries: maxEntries,
+		inFlight:   make(map[string]*fetchCall),
+	}
+}
+
+// GetMapData returns the cached map data, the wrapped provider is only called on a cache miss. Cache errors are not
+// fatal, the map data is generated again when the cache entry can't be read.
+func (c *CachedProvider) GetMapData(seed string, difficulty difficulty.Difficulty) (MapData, error) {
+	key := mapFileName(seed, difficulty)
+
+	c.mu.Lock()
+	if call, found := c.inFlight[key]; found {
+		c.mu.Unlock()
+		<-call.done
+		return call.data, call.err
+	}
+	if data, err := c.read(key); err == nil {
+		c.mu.Unlock()
+		return data, nil
+	}
+	call := &fetchCall{done: make(chan struct{})}
+	c.inFlight[key] = call
+	c.mu.Unlock()
+
+	call.data, call.err = c.Provider.GetMapData(seed, difficulty)
+
+	c.mu.Lock()
+	if call.err == nil {
+		// A failing cache only means the maps will be generated again next time
+		if err := c.write(key, call.data); err == nil {
+			c.evict()
+		}
+	}
+	delete(c.inFlight, key)
+	c.mu.Unlock()
+	close(call.done)
+
+	return call.data, call.err
+}
+
+func (c *CachedProvider) path(key string) string {
+	return filepath.Join(c.Dir, key+cacheFileExt)
+}
+
+func (c *CachedProvider) read(key string) (MapData, error) {
+	f, err := os.Open(c.path(key))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	var data MapData
+	if err = json.NewDecoder(zr).Decode(&data); err != nil {
+		return nil, fmt.Errorf("corrupted map cache entry %s: %w", key, err)
+	}
+
+	// The modification time is the last use, the least recently used entries are evicted first
+	now := time.Now()
+	_ = os.Chtimes(c.path(key), now, now)
+
+	return data, nil
+}
+
+func (c *CachedProvider) write(key string, data MapData) error {
+	if err := os.MkdirAll(c.Dir, os.ModePerm); err != nil {
+		return err
+	}
+
+	// Written to a temporary file first, a crash in the middle never leaves a truncated entry
+	tmp, err := os.CreateTemp(c.Dir, key+"-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	zw := gzip.NewWriter(tmp)
+	if err = json.NewEncoder(zw).Encode(data); err == nil {
+		err = zw.Close()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), c.path(key))
+}
+
+// evict removes the least recently used entries over MaxEntries
+func (c *CachedProvider) evict() {
+	entries, err := os.ReadDir(c.Dir)
+	if err != nil {
+		return
+	}
+
+	type cacheEntry struct {
+		path    string
+		lastUse time.Time
+	}
+	files := make([]cacheEntry, 0, len(entries))
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), cacheFileExt) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, cacheEntry{path: filepath.Join(c.Dir, e.Name()), lastUse: info.ModTime()})
+	}
+	if len(files) <= c.MaxEntries {
+		return
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].lastUse.After(files[j].lastUse)
+	})
+	for _, f := range files[c.MaxEntries:] {
+		_ = os.Remove(f.path)
+	}
+}
@@ -0,0 +1,131 @@
+package map_client
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hectorgimenez/d2go/pkg/data/difficulty"
+)
+
+// countingProvider returns one level named as the seed, it counts the calls
+type countingProvider struct {
+	calls atomic.Int32
+	delay time.Duration
+	err   error
+}
+
+func (p *countingProvider) GetMapData(seed string, _ difficulty.Difficulty) (MapData, error) {
+	p.calls.Add(1)
+	time.Sleep(p.delay)
+	if p.err != nil {
+		return nil, p.err
+	}
+
+	return MapData{{Type: "map", ID: 1, Name: seed, Map: [][]int{{0, 1}}}}, nil
+}
+
+func TestCachedProviderHit(t *testing.T) {
+	p := &countingProvider{}
+	c := NewCachedProvider(p, t.TempDir(), 10)
+
+	for i := 0; i < 3; i++ {
+		lvls, err := c.GetMapData("1234", difficulty.Hell)
+		if err != nil || len(lvls) != 1 || lvls[0].Name != "1234" || len(lvls[0].Map) != 1 {
+			t.Fatalf("Unexpected map data %+v: %v", lvls, err)
+		}
+	}
+	if calls := p.calls.Load(); calls != 1 {
+		t.Errorf("Expected the maps to be generated once, got %d calls", calls)
+	}
+
+	if _, err := c.GetMapData("1234", difficulty.Normal); err != nil || p.calls.Load() != 2 {
+		t.Errorf("Expected another difficulty to be a cache miss")
+	}
+
+	// A new provider with the same folder uses the entries of the previous session
+	if _, err := NewCachedProvider(p, c.Dir, 10).GetMapData("1234", difficulty.Hell); err != nil || p.calls.Load() != 2 {
+		t.Errorf("Expected the cache to be kept on disk")
+	}
+}
+
+func TestCachedProviderEvictsLeastRecentlyUsed(t *testing.T) {
+	p := &countingProvider{}
+	c := NewCachedProvider(p, t.TempDir(), 2)
+
+	c.GetMapData("1", difficulty.Normal)
+	c.GetMapData("2", difficulty.Normal)
+	// Seed 1 is the oldest entry, reading it again makes seed 2 the least recently used one
+	old := time.Now().Add(-time.Hour)
+	os.Chtimes(c.path("1-0"), old, old)
+	os.Chtimes(c.path("2-0"), old.Add(time.Minute), old.Add(time.Minute))
+	c.GetMapData("1", difficulty.Normal)
+	c.GetMapData("3", difficulty.Normal)
+
+	if _, err := os.Stat(c.path("2-0")); !os.IsNotExist(err) {
+		t.Errorf("Expected seed 2 to be evicted")
+	}
+	for _, key := range []string{"1-0", "3-0"} {
+		if _, err := os.Stat(c.path(key)); err != nil {
+			t.Errorf("Expected %s to be cached: %v", key, err)
+		}
+	}
+	if calls := p.calls.Load(); calls != 3 {
+		t.Errorf("Expected 3 calls, got %d", calls)
+	}
+}
+
+func TestCachedProviderCorruptedEntry(t *testing.T) {
+	p := &countingProvider{}
+	c := NewCachedProvider(p, t.TempDir(), 10)
+	if err := os.WriteFile(filepath.Join(c.Dir, "1234-0"+cacheFileExt), []byte("not gzip"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	lvls, err := c.GetMapData("1234", difficulty.Normal)
+	if err != nil || len(lvls) != 1 || p.calls.Load() != 1 {
+		t.Fatalf("Expected the maps to be generated again, got %+v: %v", lvls, err)
+	}
+	if _, err = c.read("1234-0"); err != nil {
+		t.Errorf("Expected the corrupted entry to be replaced: %v", err)
+	}
+}
+
+func TestCachedProviderErrorsAreNotCached(t *testing.T) {
+	p := &countingProvider{err: errors.New("koolo-map.exe failed")}
+	c := NewCachedProvider(p, t.TempDir(), 10)
+
+	for i := 0; i < 2; i++ {
+		if _, err := c.GetMapData("1234", difficulty.Normal); !errors.Is(err, p.err) {
+			t.Errorf("Expected the provider error, got %v", err)
+		}
+	}
+	if calls := p.calls.Load(); calls != 2 {
+		t.Errorf("Expected 2 calls, got %d", calls)
+	}
+}
+
+func TestCachedProviderConcurrentSameSeed(t *testing.T) {
+	p := &countingProvider{delay: 50 * time.Millisecond}
+	c := NewCachedProvider(p, t.TempDir(), 10)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if lvls, err := c.GetMapData("1234", difficulty.Normal); err != nil || len(lvls) != 1 {
+				t.Errorf("Unexpected map data %+v: %v", lvls, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if calls := p.calls.Load(); calls != 1 {
+		t.Errorf("Expected the companion games to share the maps, got %d calls", calls)
+	}
+}
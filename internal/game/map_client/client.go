@@ -1,11 +1,11 @@
 package map_client
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
-	"os/exec"
 	"strings"
-	"syscall"
+	"sync"
 
 	"github.com/hectorgimenez/d2go/pkg/data"
 	"github.com/hectorgimenez/d2go/pkg/data/area"
@@ -15,20 +15,57 @@ import (
 	"github.com/hectorgimenez/koolo/internal/config"
 )
 
+var (
+	providerMu  sync.Mutex
+	provider    Provider
+	providerKey string
+)
+
+// GetMapData returns the map data of the game from the configured provider, a new provider is built when the
+// configuration changes.
 func GetMapData(seed string, difficulty difficulty.Difficulty) (MapData, error) {
-	cmd := exec.Command("./tools/koolo-map.exe", config.Koolo.D2LoDPath, "-s", seed, "-d", getDifficultyAsNum(difficulty))
-	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
-	stdout, err := cmd.Output()
+	p, err := currentProvider()
+	if err != nil {
+		return nil, err
+	}
+
+	return p.GetMapData(seed, difficulty)
+}
+
+func currentProvider() (Provider, error) {
+	providerMu.Lock()
+	defer providerMu.Unlock()
+
+	cfg := config.Koolo.MapProvider
+	key := fmt.Sprintf("%+v|%s", cfg, config.Koolo.D2LoDPath)
+	if provider != nil && key == providerKey {
+		return provider, nil
+	}
+
+	p, err := NewProvider(cfg, config.Koolo.D2LoDPath)
 	if err != nil {
-		return nil, fmt.Errorf("error fetching Map data from Diablo II: LoD 1.13c game: %w", err)
+		return nil, err
 	}
+	provider, providerKey = p, key
 
-	stdoutLines := strings.Split(string(stdout), "\r\n")
+	return provider, nil
+}
+
+// parseMapData reads the levels printed by koolo-map.exe, one JSON object per line, a JSON array is accepted too
+func parseMapData(content []byte) (MapData, error) {
+	content = bytes.TrimSpace(content)
+	if len(content) > 0 && content[0] == '[' {
+		var lvls MapData
+		if err := json.Unmarshal(content, &lvls); err != nil {
+			return nil, fmt.Errorf("error decoding map data: %w", err)
+		}
+		return lvls, nil
+	}
 
-	lvls := make([]serverLevel, 0)
-	for _, line := range stdoutLines {
+	lvls := make(MapData, 0)
+	for _, line := range strings.Split(string(content), "\n") {
 		var lvl serverLevel
-		err = json.Unmarshal([]byte(line), &lvl)
+		err := json.Unmarshal([]byte(strings.TrimSpace(line)), &lvl)
 		// Discard empty lines or lines that don't contain level information
 		if err == nil && lvl.Type != "" && len(lvl.Map) > 0 {
 			lvls = append(lvls, lvl)
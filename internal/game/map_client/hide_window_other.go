@@ -0,0 +1,8 @@
+//go:build !windows
+
+package map_client
+
+import "os/exec"
+
+// hideWindow does nothing, there are no console windows to hide outside Windows
+func hideWindow(cmd *exec.Cmd) {}
@@ -0,0 +1,144 @@
+package map_client
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hectorgimenez/d2go/pkg/data/difficulty"
+	"github.com/hectorgimenez/koolo/internal/config"
+)
+
+const (
+	ProviderExecutable = "executable"
+	ProviderHTTP       = "http"
+	ProviderFiles      = "files"
+
+	DefaultCacheSize = 100
+	DefaultCacheDir  = "cache/maps"
+)
+
+// Provider generates or loads the map data of a game, the maps only depend on the seed and the difficulty.
+type Provider interface {
+	GetMapData(seed string, difficulty difficulty.Difficulty) (MapData, error)
+}
+
+// NewProvider builds the provider selected in the configuration, wrapped with the disk cache unless it's disabled.
+func NewProvider(cfg config.MapProvider, d2LoDPath string) (Provider, error) {
+	var p Provider
+	switch strings.ToLower(cfg.Type) {
+	case "", ProviderExecutable:
+		p = ExecutableProvider{Path: "./tools/koolo-map.exe", D2LoDPath: d2LoDPath}
+	case ProviderHTTP:
+		if cfg.URL == "" {
+			return nil, errors.New("the http map provider needs an url")
+		}
+		p = HTTPProvider{URL: cfg.URL, Client: &http.Client{Timeout: 60 * time.Second}}
+	case ProviderFiles:
+		if cfg.Dir == "" {
+			return nil, errors.New("the files map provider needs a dir")
+		}
+		p = FileProvider{Dir: cfg.Dir}
+	default:
+		return nil, fmt.Errorf("unknown map provider %q, use executable, http or files", cfg.Type)
+	}
+
+	if cfg.CacheSize < 0 {
+		return p, nil
+	}
+	size := cfg.CacheSize
+	if size == 0 {
+		size = DefaultCacheSize
+	}
+	dir := cfg.CacheDir
+	if dir == "" {
+		dir = DefaultCacheDir
+	}
+
+	return NewCachedProvider(p, dir, size), nil
+}
+
+// ExecutableProvider generates the maps running koolo-map.exe, it needs a Diablo II: LoD 1.13c installation.
+type ExecutableProvider struct {
+	Path      string
+	D2LoDPath string
+}
+
+func (p ExecutableProvider) GetMapData(seed string, difficulty difficulty.Difficulty) (MapData, error) {
+	cmd := exec.Command(p.Path, p.D2LoDPath, "-s", seed, "-d", getDifficultyAsNum(difficulty))
+	hideWindow(cmd)
+	stdout, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("error fetching Map data from Diablo II: LoD 1.13c game: %w", err)
+	}
+
+	return parseMapData(stdout)
+}
+
+// HTTPProvider gets the maps from a map server. The {seed} and {difficulty} placeholders of the URL are replaced by the
+// game ones, they are sent as query parameters when the URL has no placeholders. Difficulty is 0, 1 or 2 like the
+// koolo-map.exe argument, the response is the koolo-map.exe output or a JSON array of levels.
+type HTTPProvider struct {
+	URL    string
+	Client *http.Client
+}
+
+func (p HTTPProvider) GetMapData(seed string, difficulty difficulty.Difficulty) (MapData, error) {
+	df := getDifficultyAsNum(difficulty)
+	u := p.URL
+	if strings.Contains(u, "{seed}") || strings.Contains(u, "{difficulty}") {
+		u = strings.NewReplacer("{seed}", url.PathEscape(seed), "{difficulty}", df).Replace(u)
+	} else {
+		sep := "?"
+		if strings.Contains(u, "?") {
+			sep = "&"
+		}
+		u += sep + url.Values{"seed": {seed}, "difficulty": {df}}.Encode()
+	}
+
+	client := p.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Get(u)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching map data from %s: %w", p.URL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error fetching map data from %s: unexpected status %s", p.URL, resp.Status)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading map data: %w", err)
+	}
+
+	return parseMapData(body)
+}
+
+// FileProvider loads pre-generated maps, <seed>-<difficulty>.json files in Dir with the koolo-map.exe output or a JSON
+// array of levels. Difficulty is 0, 1 or 2 like the koolo-map.exe argument.
+type FileProvider struct {
+	Dir string
+}
+
+func (p FileProvider) GetMapData(seed string, difficulty difficulty.Difficulty) (MapData, error) {
+	content, err := os.ReadFile(filepath.Join(p.Dir, mapFileName(seed, difficulty)+".json"))
+	if err != nil {
+		return nil, fmt.Errorf("error reading map file: %w", err)
+	}
+
+	return parseMapData(content)
+}
+
+func mapFileName(seed string, difficulty difficulty.Difficulty) string {
+	return seed + "-" + getDifficultyAsNum(difficulty)
+}
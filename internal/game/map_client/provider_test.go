@@ -0,0 +1,100 @@
+package map_client
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hectorgimenez/d2go/pkg/data/difficulty"
+	"github.com/hectorgimenez/koolo/internal/config"
+)
+
+const testLevels = "{\"type\":\"map\",\"id\":2,\"name\":\"Blood Moor\",\"size\":{\"width\":3,\"height\":1},\"map\":[[1,1]]}\r\n" +
+	"{\"type\":\"map\",\"id\":3,\"name\":\"Cold Plains\",\"size\":{\"width\":2,\"height\":1},\"map\":[[0,2]]}\r\n" +
+	"done\r\n"
+
+func TestParseMapData(t *testing.T) {
+	lvls, err := parseMapData([]byte(testLevels))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(lvls) != 2 || lvls[0].ID != 2 || lvls[1].Name != "Cold Plains" {
+		t.Fatalf("Unexpected levels %+v", lvls)
+	}
+	if cg := lvls[0].CollisionGrid(); len(cg) != 1 || cg[0][0] || !cg[0][1] || cg[0][2] {
+		t.Errorf("Unexpected collision grid %v", cg)
+	}
+
+	array, err := parseMapData([]byte(`[{"type":"map","id":2,"size":{"width":3,"height":1},"map":[[1,1]]}]`))
+	if err != nil || len(array) != 1 || array[0].ID != 2 {
+		t.Errorf("Expected the JSON array to be parsed, got %+v: %v", array, err)
+	}
+}
+
+func TestFileProvider(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "1234-1.json"), []byte(testLevels), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	p := FileProvider{Dir: dir}
+	lvls, err := p.GetMapData("1234", difficulty.Nightmare)
+	if err != nil || len(lvls) != 2 {
+		t.Errorf("Expected 2 levels, got %d: %v", len(lvls), err)
+	}
+	if _, err = p.GetMapData("1234", difficulty.Hell); err == nil {
+		t.Errorf("Expected an error for a missing file")
+	}
+}
+
+func TestHTTPProvider(t *testing.T) {
+	var query string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RequestURI()
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(testLevels))
+	}))
+	defer srv.Close()
+
+	lvls, err := HTTPProvider{URL: srv.URL + "/map"}.GetMapData("1234", difficulty.Hell)
+	if err != nil || len(lvls) != 2 {
+		t.Fatalf("Expected 2 levels, got %d: %v", len(lvls), err)
+	}
+	if query != "/map?difficulty=2&seed=1234" {
+		t.Errorf("Unexpected request %s", query)
+	}
+
+	if _, err = (HTTPProvider{URL: srv.URL + "/v1/{seed}/{difficulty}"}).GetMapData("55", difficulty.Normal); err != nil || query != "/v1/55/0" {
+		t.Errorf("Unexpected request %s: %v", query, err)
+	}
+
+	if _, err = (HTTPProvider{URL: srv.URL + "/missing"}).GetMapData("1234", difficulty.Normal); err == nil {
+		t.Errorf("Expected an error for a not found response")
+	}
+}
+
+func TestNewProvider(t *testing.T) {
+	p, err := NewProvider(config.MapProvider{}, "")
+	if c, ok := p.(*CachedProvider); err != nil || !ok || c.MaxEntries != DefaultCacheSize || c.Dir != DefaultCacheDir {
+		t.Errorf("Expected the cached executable provider by default, got %+v: %v", p, err)
+	} else if _, ok = c.Provider.(ExecutableProvider); !ok {
+		t.Errorf("Expected the executable provider, got %T", c.Provider)
+	}
+
+	p, err = NewProvider(config.MapProvider{Type: "files", Dir: "maps", CacheSize: -1}, "")
+	if _, ok := p.(FileProvider); err != nil || !ok {
+		t.Errorf("Expected the files provider without cache, got %T: %v", p, err)
+	}
+
+	if _, err = NewProvider(config.MapProvider{Type: "http"}, ""); err == nil {
+		t.Errorf("Expected an error without url")
+	}
+	if _, err = NewProvider(config.MapProvider{Type: "wrong"}, ""); err == nil {
+		t.Errorf("Expected an error for an unknown provider")
+	}
+}
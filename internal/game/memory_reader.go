@@ -1,3 +1,5 @@
+//go:build windows
+
 package game
 
 import (
@@ -166,6 +168,29 @@ func (gd *MemoryReader) GetData() Data {
 	}
 }
 
+func (gd *MemoryReader) GameAreaSize() (int, int) {
+	return gd.GameAreaSizeX, gd.GameAreaSizeY
+}
+
+// GetMercList returns the mercenaries listed in the hire menu, only works in legacy graphics mode
+func (gd *MemoryReader) GetMercList() []MercOption {
+	mercs := gd.GameReader.GetMercList()
+	options := make([]MercOption, len(mercs))
+	for i, m := range mercs {
+		options[i] = MercOption{
+			Index:   m.Index,
+			Name:    m.Name,
+			Skill:   m.Skill,
+			Level:   m.Level,
+			Life:    m.Life,
+			Defense: m.Defense,
+			Cost:    m.Cost,
+		}
+	}
+
+	return options
+}
+
 func (gd *MemoryReader) getMapSeed(playerUnit uintptr) (uint, error) {
 	actPtr := uintptr(gd.Process.ReadUInt(playerUnit+0x20, memory.Uint64))
 	actMiscPtr := uintptr(gd.Process.ReadUInt(actPtr+0x78, memory.Uint64))
@@ -11,21 +11,22 @@ type ProcessSender interface {
 	SendPacket([]byte) error
 }
 
-type PacketSender struct {
+// ProcessPacketSender sends the packets through the game process
+type ProcessPacketSender struct {
 	process ProcessSender
 }
 
-func NewPacketSender(process ProcessSender) *PacketSender {
-	return &PacketSender{
+func NewPacketSender(process ProcessSender) *ProcessPacketSender {
+	return &ProcessPacketSender{
 		process: process,
 	}
 }
 
-func (ps *PacketSender) SendPacket(packet []byte) error {
+func (ps *ProcessPacketSender) SendPacket(packet []byte) error {
 	return ps.process.SendPacket(packet)
 }
 
-func (ps *PacketSender) PickUpItem(item data.Item) error {
+func (ps *ProcessPacketSender) PickUpItem(item data.Item) error {
 	err := ps.SendPacket(packet.NewPickUpItem(item).GetPayload())
 	if err != nil {
 		return fmt.Errorf("failed to send pick item packet: %w", err)
@@ -34,14 +35,14 @@ func (ps *PacketSender) PickUpItem(item data.Item) error {
 	return nil
 }
 
-func (ps *PacketSender) InteractWithTp(object data.Object) error {
+func (ps *ProcessPacketSender) InteractWithTp(object data.Object) error {
 	if err := ps.SendPacket(packet.NewTpInteraction(object).GetPayload()); err != nil {
 		return fmt.Errorf("failed to send tp interaction packet: %w", err)
 	}
 	return nil
 }
 
-func (ps *PacketSender) InteractWithEntrance(entrance data.Entrance) error {
+func (ps *ProcessPacketSender) InteractWithEntrance(entrance data.Entrance) error {
 	if err := ps.SendPacket(packet.NewEntranceInteraction(entrance).GetPayload()); err != nil {
 		return fmt.Errorf("failed to send entrance interaction packet: %w", err)
 	}
@@ -0,0 +1,259 @@
+package game
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/area"
+	"github.com/hectorgimenez/koolo/internal/config"
+)
+
+// Recording is a game captured by the Recorder, loaded in memory.
+type Recording struct {
+	Header RecordingHeader
+	Areas  map[area.ID]AreaData
+	Frames []Frame
+}
+
+// LoadRecording reads a recording file. A recording cut by a crash is loaded up to the last complete frame.
+func LoadRecording(path string) (*Recording, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return ReadRecording(f)
+}
+
+// ReadRecording reads a gzip compressed recording.
+func ReadRecording(r io.Reader) (*Recording, error) {
+	gz, err := gzip.NewReader(bufio.NewReader(r))
+	if err != nil {
+		return nil, fmt.Errorf("error reading recording: %w", err)
+	}
+	defer gz.Close()
+
+	rec := &Recording{Areas: make(map[area.ID]AreaData)}
+	dec := json.NewDecoder(gz)
+	for {
+		var entry recordingEntry
+		if err = dec.Decode(&entry); err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				break
+			}
+			return nil, fmt.Errorf("error decoding recording: %w", err)
+		}
+
+		switch {
+		case entry.Header != nil:
+			rec.Header = *entry.Header
+		case entry.Area != nil:
+			rec.Areas[entry.Area.Area] = *entry.Area
+		case entry.Frame != nil:
+			rec.Frames = append(rec.Frames, *entry.Frame)
+		}
+	}
+
+	if rec.Header.Version == 0 {
+		return nil, errors.New("invalid recording, header not found")
+	}
+	if rec.Header.Version > recordingVersion {
+		return nil, fmt.Errorf("unsupported recording version %d", rec.Header.Version)
+	}
+
+	return rec, nil
+}
+
+// Data builds the game data of the frame the same way MemoryReader.GetData does, cfg replaces the character config.
+func (r *Recording) Data(frame int, cfg config.CharacterCfg) Data {
+	f := r.Frames[frame]
+	return Data{
+		Data:                f.Data,
+		CharacterCfg:        cfg,
+		AreaData:            r.Areas[f.Data.PlayerUnit.Area],
+		Areas:               r.Areas,
+		IsLevelingCharacter: f.IsLevelingCharacter,
+	}
+}
+
+// PlaybackReader plays a recording back in place of the MemoryReader. Every GetData call returns the next frame, like
+// the live game would move on between two reads, and the last one is repeated once the recording is over.
+type PlaybackReader struct {
+	rec *Recording
+	cfg config.CharacterCfg
+
+	mu    sync.Mutex
+	frame int
+}
+
+func NewPlaybackReader(rec *Recording, cfg config.CharacterCfg) *PlaybackReader {
+	return &PlaybackReader{rec: rec, cfg: cfg}
+}
+
+// GetData returns the current frame and moves to the next one.
+func (p *PlaybackReader) GetData() Data {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	d := p.rec.Data(p.frame, p.cfg)
+	if p.frame < len(p.rec.Frames)-1 {
+		p.frame++
+	}
+
+	return d
+}
+
+// Peek returns the current frame without moving.
+func (p *PlaybackReader) Peek() Data {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.rec.Data(p.frame, p.cfg)
+}
+
+// GetInventory returns the inventory of the current frame, without moving.
+func (p *PlaybackReader) GetInventory() data.Inventory {
+	return p.Peek().Inventory
+}
+
+func (p *PlaybackReader) MapSeed() uint {
+	return p.rec.Header.MapSeed
+}
+
+// Frame returns the index of the frame the next GetData call will return.
+func (p *PlaybackReader) Frame() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.frame
+}
+
+// Seek moves to the given frame, out of range values are clamped.
+func (p *PlaybackReader) Seek(frame int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.frame = max(0, min(frame, len(p.rec.Frames)-1))
+}
+
+// Done returns true when the last frame was reached.
+func (p *PlaybackReader) Done() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.frame >= len(p.rec.Frames)-1
+}
+
+// InputType is the kind of input sent to the PlaybackHID.
+type InputType string
+
+const (
+	InputMove    InputType = "move"
+	InputClick   InputType = "click"
+	InputKey     InputType = "key"
+	InputKeyDown InputType = "keydown"
+	InputKeyUp   InputType = "keyup"
+)
+
+// Input is a mouse or keyboard input received by the PlaybackHID, with the frame shown when it was sent.
+type Input struct {
+	Type     InputType
+	Frame    int
+	X, Y     int
+	Button   MouseButton
+	Key      byte
+	Modifier ModifierKey
+}
+
+// PlaybackHID has the same methods as HID but only records the inputs, so tests can check what the bot would do in a
+// recorded situation.
+type PlaybackHID struct {
+	reader *PlaybackReader
+
+	mu     sync.Mutex
+	inputs []Input
+}
+
+func NewPlaybackHID(reader *PlaybackReader) *PlaybackHID {
+	return &PlaybackHID{reader: reader}
+}
+
+func (hid *PlaybackHID) MovePointer(x, y int) {
+	hid.add(Input{Type: InputMove, X: x, Y: y})
+}
+
+func (hid *PlaybackHID) Click(btn MouseButton, x, y int) {
+	hid.add(Input{Type: InputClick, X: x, Y: y, Button: btn})
+}
+
+func (hid *PlaybackHID) ClickWithModifier(btn MouseButton, x, y int, modifier ModifierKey) {
+	hid.add(Input{Type: InputClick, X: x, Y: y, Button: btn, Modifier: modifier})
+}
+
+func (hid *PlaybackHID) PressKey(key byte) {
+	hid.add(Input{Type: InputKey, Key: key})
+}
+
+func (hid *PlaybackHID) KeySequence(keysToPress ...byte) {
+	for _, key := range keysToPress {
+		hid.PressKey(key)
+	}
+}
+
+func (hid *PlaybackHID) PressKeyWithModifier(key byte, modifier ModifierKey) {
+	hid.add(Input{Type: InputKey, Key: key, Modifier: modifier})
+}
+
+func (hid *PlaybackHID) PressKeyBinding(kb data.KeyBinding) {
+	keys := getKeysForKB(kb)
+	if keys[1] == 0 || keys[1] == 255 {
+		hid.PressKey(keys[0])
+		return
+	}
+
+	hid.PressKeyWithModifier(keys[0], ModifierKey(keys[1]))
+}
+
+func (hid *PlaybackHID) KeyDown(kb data.KeyBinding) {
+	hid.add(Input{Type: InputKeyDown, Key: getKeysForKB(kb)[0]})
+}
+
+func (hid *PlaybackHID) KeyUp(kb data.KeyBinding) {
+	hid.add(Input{Type: InputKeyUp, Key: getKeysForKB(kb)[0]})
+}
+
+// Inputs returns every input received so far.
+func (hid *PlaybackHID) Inputs() []Input {
+	hid.mu.Lock()
+	defer hid.mu.Unlock()
+
+	return append([]Input(nil), hid.inputs...)
+}
+
+// Clicks returns the click inputs received so far.
+func (hid *PlaybackHID) Clicks() []Input {
+	clicks := make([]Input, 0)
+	for _, in := range hid.Inputs() {
+		if in.Type == InputClick {
+			clicks = append(clicks, in)
+		}
+	}
+
+	return clicks
+}
+
+func (hid *PlaybackHID) add(in Input) {
+	in.Frame = hid.reader.Frame()
+
+	hid.mu.Lock()
+	defer hid.mu.Unlock()
+	hid.inputs = append(hid.inputs, in)
+}
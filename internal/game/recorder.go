@@ -0,0 +1,209 @@
+package game
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/area"
+)
+
+const (
+	recordingVersion   = 1
+	recordingExtension = ".rec.gz"
+
+	DefaultMaxRecordings = 10
+)
+
+// RecordingHeader is the first entry of every recording.
+type RecordingHeader struct {
+	Version    int       `json:"version"`
+	Supervisor string    `json:"supervisor"`
+	MapSeed    uint      `json:"mapSeed"`
+	StartedAt  time.Time `json:"startedAt"`
+}
+
+// Frame is the game state read on a single RefreshGameData tick. The area grids are stored once per recording, not in
+// every frame.
+type Frame struct {
+	Time                time.Time `json:"time"`
+	Data                data.Data `json:"data"`
+	IsLevelingCharacter bool      `json:"isLevelingCharacter,omitempty"`
+}
+
+// recordingEntry is a line of the recording, only one of the fields is set
+type recordingEntry struct {
+	Header *RecordingHeader `json:"header,omitempty"`
+	Area   *AreaData        `json:"area,omitempty"`
+	Frame  *Frame           `json:"frame,omitempty"`
+}
+
+// Recorder writes the game state of a supervisor to a gzip compressed file, a new file is started on every game (map
+// seed change). Only the last MaxRecordings files of the supervisor are kept.
+type Recorder struct {
+	dir           string
+	supervisor    string
+	MaxRecordings int
+
+	mu      sync.Mutex
+	file    *os.File
+	gz      *gzip.Writer
+	enc     *json.Encoder
+	mapSeed uint
+	areas   map[area.ID]bool
+	frames  int
+}
+
+func NewRecorder(dir, supervisor string) *Recorder {
+	return &Recorder{
+		dir:           dir,
+		supervisor:    supervisor,
+		MaxRecordings: DefaultMaxRecordings,
+	}
+}
+
+// Record appends the current game state, starting a new recording when the map seed changed. States outside the game
+// are ignored.
+func (r *Recorder) Record(d Data, mapSeed uint) error {
+	if !d.IsIngame {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file == nil || mapSeed != r.mapSeed {
+		if err := r.start(mapSeed); err != nil {
+			return err
+		}
+	}
+
+	// Areas are written the first time they are available, before the frames needing them
+	for _, id := range sortedAreaIDs(d.Areas) {
+		if r.areas[id] {
+			continue
+		}
+		ad := d.Areas[id]
+		if err := r.enc.Encode(recordingEntry{Area: &ad}); err != nil {
+			return fmt.Errorf("error recording area %d: %w", id, err)
+		}
+		r.areas[id] = true
+	}
+
+	r.frames++
+	return r.enc.Encode(recordingEntry{Frame: &Frame{
+		Time:                time.Now(),
+		Data:                d.Data,
+		IsLevelingCharacter: d.IsLevelingCharacter,
+	}})
+}
+
+// File returns the path of the recording being written, empty when there is none.
+func (r *Recorder) File() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file == nil {
+		return ""
+	}
+
+	return r.file.Name()
+}
+
+// Close flushes and closes the current recording.
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.closeFile()
+}
+
+func (r *Recorder) start(mapSeed uint) error {
+	if err := r.closeFile(); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(r.dir, 0o755); err != nil {
+		return fmt.Errorf("error creating recordings directory: %w", err)
+	}
+
+	now := time.Now()
+	name := fmt.Sprintf("%s-%s-%d%s", r.supervisor, now.Format("20060102-150405"), mapSeed, recordingExtension)
+	f, err := os.Create(filepath.Join(r.dir, name))
+	if err != nil {
+		return fmt.Errorf("error creating recording: %w", err)
+	}
+
+	r.file = f
+	r.gz = gzip.NewWriter(f)
+	r.enc = json.NewEncoder(r.gz)
+	r.mapSeed = mapSeed
+	r.areas = make(map[area.ID]bool)
+	r.frames = 0
+	r.removeOldRecordings()
+
+	return r.enc.Encode(recordingEntry{Header: &RecordingHeader{
+		Version:    recordingVersion,
+		Supervisor: r.supervisor,
+		MapSeed:    mapSeed,
+		StartedAt:  now,
+	}})
+}
+
+func (r *Recorder) closeFile() error {
+	if r.file == nil {
+		return nil
+	}
+
+	gzErr := r.gz.Close()
+	fileErr := r.file.Close()
+	// A recording without frames is useless, don't leave it around
+	if r.frames == 0 {
+		os.Remove(r.file.Name())
+	}
+	r.file, r.gz, r.enc = nil, nil, nil
+
+	if gzErr != nil {
+		return gzErr
+	}
+
+	return fileErr
+}
+
+// removeOldRecordings keeps the last MaxRecordings files of the supervisor, including the one just started
+func (r *Recorder) removeOldRecordings() {
+	if r.MaxRecordings <= 0 {
+		return
+	}
+
+	files, _ := filepath.Glob(filepath.Join(r.dir, r.supervisor+"-*"+recordingExtension))
+	own := files[:0]
+	for _, f := range files {
+		// Another supervisor name may start with this one, the timestamp must follow the name
+		if rest := strings.TrimPrefix(filepath.Base(f), r.supervisor+"-"); len(rest) > 15 && rest[8] == '-' {
+			own = append(own, f)
+		}
+	}
+	sort.Strings(own)
+
+	for len(own) > r.MaxRecordings {
+		os.Remove(own[0])
+		own = own[1:]
+	}
+}
+
+func sortedAreaIDs(areas map[area.ID]AreaData) []area.ID {
+	ids := make([]area.ID, 0, len(areas))
+	for id := range areas {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	return ids
+}
@@ -0,0 +1,198 @@
+package game
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/area"
+	"github.com/hectorgimenez/d2go/pkg/data/item"
+	"github.com/hectorgimenez/d2go/pkg/data/npc"
+	"github.com/hectorgimenez/d2go/pkg/data/stat"
+	"github.com/hectorgimenez/koolo/internal/config"
+)
+
+func testAreas() map[area.ID]AreaData {
+	raw := make([][]CollisionType, 10)
+	for y := range raw {
+		raw[y] = make([]CollisionType, 12)
+		for x := range raw[y] {
+			raw[y][x] = CollisionTypeWalkable
+		}
+	}
+	raw[5][5] = CollisionTypeNonWalkable
+
+	return map[area.ID]AreaData{
+		area.BloodMoor: {Area: area.BloodMoor, Name: "Blood Moor", Grid: NewGrid(raw, 100, 200)},
+		area.ColdPlains: {
+			Area:           area.ColdPlains,
+			Name:           "Cold Plains",
+			AdjacentLevels: []data.Level{{Area: area.BloodMoor, Position: data.Position{X: 105, Y: 205}}},
+			Grid:           NewGrid([][]CollisionType{{CollisionTypeWalkable}}, 0, 0),
+		},
+	}
+}
+
+func testFrame(playerX int, monsters int) Data {
+	d := Data{Areas: testAreas()}
+	d.IsIngame = true
+	d.PlayerUnit.Area = area.BloodMoor
+	d.PlayerUnit.Position = data.Position{X: playerX, Y: 205}
+	for i := 0; i < monsters; i++ {
+		d.Monsters = append(d.Monsters, data.Monster{UnitID: data.UnitID(i + 1), Name: npc.Zombie, Position: data.Position{X: 110, Y: 205 + i}})
+	}
+	d.Inventory.AllItems = []data.Item{
+		{Name: "SuperHealingPotion", Location: item.Location{LocationType: item.LocationInventory}, Position: data.Position{X: 1, Y: 0}},
+		{Name: "SuperHealingPotion", Location: item.Location{LocationType: item.LocationInventory}, Position: data.Position{X: 0, Y: 0}},
+		{Name: "Ring", Quality: item.QualityUnique, Location: item.Location{LocationType: item.LocationGround},
+			Stats: stat.Stats{{ID: stat.MaxMana, Value: 20}}},
+	}
+
+	return d
+}
+
+func TestRecorderRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	r := NewRecorder(dir, "sorc")
+
+	notInGame := testFrame(100, 0)
+	notInGame.IsIngame = false
+	for _, d := range []Data{notInGame, testFrame(101, 2), testFrame(102, 1), testFrame(103, 0)} {
+		if err := r.Record(d, 1234); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	file := r.File()
+	if err := r.Close(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	rec, err := LoadRecording(file)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if rec.Header.Supervisor != "sorc" || rec.Header.MapSeed != 1234 || len(rec.Frames) != 3 || len(rec.Areas) != 2 {
+		t.Fatalf("Unexpected recording %+v, %d frames, %d areas", rec.Header, len(rec.Frames), len(rec.Areas))
+	}
+
+	d := rec.Data(0, config.CharacterCfg{})
+	if d.PlayerUnit.Position.X != 101 || len(d.Monsters) != 2 || d.AreaData.Name != "Blood Moor" {
+		t.Errorf("Unexpected frame %+v", d.PlayerUnit.Position)
+	}
+	if d.AreaData.Grid == nil || d.AreaData.OffsetX != 100 || d.AreaData.IsWalkable(data.Position{X: 105, Y: 205}) ||
+		!d.AreaData.IsWalkable(data.Position{X: 101, Y: 201}) {
+		t.Errorf("Unexpected grid %+v", d.AreaData.Grid)
+	}
+	if ring := d.Inventory.AllItems[2]; ring.Quality != item.QualityUnique || ring.Stats[0].Value != 20 {
+		t.Errorf("Unexpected item %+v", ring)
+	}
+	if levels := d.Areas[area.ColdPlains].AdjacentLevels; len(levels) != 1 || levels[0].Area != area.BloodMoor {
+		t.Errorf("Unexpected adjacent levels %+v", levels)
+	}
+}
+
+func TestRecorderNewFilePerGame(t *testing.T) {
+	dir := t.TempDir()
+	r := NewRecorder(dir, "sorc")
+	r.MaxRecordings = 2
+
+	// Names use the current second, fake older recordings to check the rotation
+	for _, name := range []string{"sorc-20240101-100000-1.rec.gz", "sorc-20240101-110000-2.rec.gz", "sorcerer-20240101-100000-1.rec.gz"} {
+		os.WriteFile(filepath.Join(dir, name), []byte{}, 0o644)
+	}
+
+	r.Record(testFrame(100, 0), 1)
+	first := r.File()
+	r.Record(testFrame(100, 0), 2)
+	second := r.File()
+	r.Close()
+
+	if first == second {
+		t.Fatalf("Expected a new recording for the new game")
+	}
+	own, _ := filepath.Glob(filepath.Join(dir, "sorc-2*.rec.gz"))
+	if len(own) != 2 {
+		t.Errorf("Expected only the last 2 recordings to be kept, got %v", own)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "sorcerer-20240101-100000-1.rec.gz")); err != nil {
+		t.Errorf("Recordings of other supervisors should be kept")
+	}
+}
+
+func TestPlayback(t *testing.T) {
+	dir := t.TempDir()
+	r := NewRecorder(dir, "sorc")
+	for x := 101; x <= 103; x++ {
+		r.Record(testFrame(x, 0), 1)
+	}
+	file := r.File()
+	r.Close()
+
+	rec, err := LoadRecording(file)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	cfg := config.CharacterCfg{}
+	cfg.Inventory.HealingPotionCount = 4
+	reader := NewPlaybackReader(rec, cfg)
+	hid := NewPlaybackHID(reader)
+
+	d := reader.GetData()
+	if d.PlayerUnit.Position.X != 101 || reader.Frame() != 1 {
+		t.Errorf("Unexpected first frame %+v", d.PlayerUnit.Position)
+	}
+	// Logic using game.Data works on the recorded state, with the config given to the reader
+	if potions := d.PotionsInInventory(data.HealingPotion); len(potions) != 2 || potions[0].Position.X != 0 {
+		t.Errorf("Unexpected potions %+v", potions)
+	}
+	if missing := d.MissingPotionCountInInventory(data.HealingPotion); missing != 2 {
+		t.Errorf("Expected 2 missing potions, got %d", missing)
+	}
+
+	hid.Click(LeftButton, 10, 20)
+	reader.GetData()
+	hid.PressKeyBinding(data.KeyBinding{Key1: [2]byte{'T', 0}})
+	if last := reader.GetData(); last.PlayerUnit.Position.X != 103 || !reader.Done() {
+		t.Errorf("Expected the last frame, got %+v", last.PlayerUnit.Position)
+	}
+	if again := reader.GetData(); again.PlayerUnit.Position.X != 103 {
+		t.Errorf("Expected the last frame to be repeated, got %+v", again.PlayerUnit.Position)
+	}
+
+	inputs := hid.Inputs()
+	if len(inputs) != 2 || inputs[0].Frame != 1 || inputs[0].X != 10 || inputs[1].Type != InputKey || inputs[1].Key != 'T' || inputs[1].Frame != 2 {
+		t.Errorf("Unexpected inputs %+v", inputs)
+	}
+	if clicks := hid.Clicks(); len(clicks) != 1 || clicks[0].Button != LeftButton {
+		t.Errorf("Unexpected clicks %+v", clicks)
+	}
+
+	reader.Seek(-5)
+	if reader.Frame() != 0 {
+		t.Errorf("Expected seek to be clamped, got %d", reader.Frame())
+	}
+}
+
+func TestLoadTruncatedRecording(t *testing.T) {
+	dir := t.TempDir()
+	r := NewRecorder(dir, "sorc")
+	for x := 0; x < 50; x++ {
+		r.Record(testFrame(x, 3), 1)
+	}
+	file := r.File()
+	r.Close()
+
+	content, _ := os.ReadFile(file)
+	truncated := filepath.Join(dir, "truncated.rec.gz")
+	os.WriteFile(truncated, content[:len(content)*3/4], 0o644)
+
+	rec, err := LoadRecording(truncated)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(rec.Frames) == 0 || len(rec.Frames) >= 50 {
+		t.Errorf("Expected the complete frames only, got %d", len(rec.Frames))
+	}
+}
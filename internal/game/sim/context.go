@@ -0,0 +1,41 @@
+package sim
+
+import (
+	"io"
+	"log/slog"
+
+	"github.com/hectorgimenez/koolo/internal/config"
+	"github.com/hectorgimenez/koolo/internal/context"
+	"github.com/hectorgimenez/koolo/internal/health"
+	"github.com/hectorgimenez/koolo/internal/pather"
+)
+
+// NewContext builds a bot context attached to the current goroutine, like the supervisor manager does for a real game,
+// with the world as game backend. The character (ctx.Char) is not set, the tests needing it have to provide one.
+func NewContext(name string, w *World, cfg *config.CharacterCfg, logger *slog.Logger) *context.Status {
+	if logger == nil {
+		logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	}
+	w.SetCharacterCfg(*cfg)
+	// The bot code reads the global settings (debug flags, log folders), the defaults are fine without a config file
+	if config.Koolo == nil {
+		config.Koolo = &config.KooloCfg{}
+	}
+
+	ctx := context.NewContext(name)
+	bm := health.NewBeltManager(ctx.Data, w, logger, name)
+
+	ctx.CharacterCfg = cfg
+	ctx.Logger = logger
+	ctx.HID = w
+	ctx.GameReader = w
+	ctx.PacketSender = w
+	ctx.MemoryInjector = w
+	ctx.Manager = w
+	ctx.PathFinder = pather.NewPathFinder(w, ctx.Data, w, cfg)
+	ctx.BeltManager = bm
+	ctx.HealthManager = health.NewHealthManager(bm, ctx.Data)
+	ctx.RefreshGameData()
+
+	return ctx
+}
@@ -0,0 +1,250 @@
+package sim
+
+import (
+	"fmt"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/item"
+	"github.com/hectorgimenez/d2go/pkg/data/skill"
+	"github.com/hectorgimenez/d2go/pkg/data/stat"
+	"github.com/hectorgimenez/koolo/internal/game"
+	"github.com/hectorgimenez/koolo/internal/pather"
+)
+
+// Hover distance, in tiles, between the pointer and a unit
+const hoverDistance = 1
+
+func (w *World) MovePointer(x, y int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.movePointer(x, y)
+	w.addInput(game.Input{Type: game.InputMove, X: x, Y: y})
+}
+
+func (w *World) Click(btn game.MouseButton, x, y int) {
+	w.ClickWithModifier(btn, x, y, 0)
+}
+
+// ClickWithModifier attacks the hovered monster or picks up the hovered item with the left button, the right button
+// teleports to the pointer when the player can teleport and has enough mana.
+func (w *World) ClickWithModifier(btn game.MouseButton, x, y int, modifier game.ModifierKey) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.movePointer(x, y)
+	w.addInput(game.Input{Type: game.InputClick, X: x, Y: y, Button: btn, Modifier: modifier})
+
+	switch btn {
+	case game.LeftButton:
+		if !w.hover.IsHovered {
+			return
+		}
+		if i := w.itemIndex(w.hover.UnitID); i >= 0 {
+			w.pickUp(i)
+			return
+		}
+		for i, m := range w.monsters {
+			if m.UnitID == w.hover.UnitID && m.Stats[stat.Life] > 0 {
+				w.monsters[i].Stats[stat.Life] = max(0, m.Stats[stat.Life]-w.AttackDamage)
+			}
+		}
+	case game.RightButton:
+		if w.cfg.Character.UseTeleport {
+			w.teleport()
+		}
+	}
+}
+
+func (w *World) PressKey(key byte) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.addInput(game.Input{Type: game.InputKey, Key: key})
+}
+
+func (w *World) KeySequence(keysToPress ...byte) {
+	for _, key := range keysToPress {
+		w.PressKey(key)
+	}
+}
+
+func (w *World) PressKeyWithModifier(key byte, modifier game.ModifierKey) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.addInput(game.Input{Type: game.InputKey, Key: key, Modifier: modifier})
+}
+
+// PressKeyBinding moves the player to the pointer for the force move binding, other bindings are only recorded.
+func (w *World) PressKeyBinding(kb data.KeyBinding) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.addInput(game.Input{Type: game.InputKey, Key: keyOf(kb)})
+	if kb == w.keyBindings.ForceMove {
+		w.moveTo(w.pointer)
+	}
+}
+
+func (w *World) KeyDown(kb data.KeyBinding) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.addInput(game.Input{Type: game.InputKeyDown, Key: keyOf(kb)})
+}
+
+func (w *World) KeyUp(kb data.KeyBinding) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.addInput(game.Input{Type: game.InputKeyUp, Key: keyOf(kb)})
+}
+
+func (w *World) SendPacket(packet []byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.packets = append(w.packets, append([]byte(nil), packet...))
+
+	return nil
+}
+
+// PickUpItem picks up the item when it's close enough, like the game does with the pickup packet.
+func (w *World) PickUpItem(it data.Item) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	i := w.itemIndex(it.UnitID)
+	if i >= 0 && pather.DistanceFromPoint(w.player.Position, w.items[i].Position) <= PickupDistance {
+		w.pickUp(i)
+	}
+
+	return nil
+}
+
+func (w *World) InteractWithTp(object data.Object) error {
+	return fmt.Errorf("portal %d not found", object.ID)
+}
+
+func (w *World) InteractWithEntrance(entrance data.Entrance) error {
+	return fmt.Errorf("entrance %d not found", entrance.ID)
+}
+
+// Load, Unload and RestoreMemory do nothing, there is no game process to patch.
+func (w *World) Load() error {
+	return nil
+}
+
+func (w *World) Unload() error {
+	return nil
+}
+
+func (w *World) RestoreMemory() error {
+	return nil
+}
+
+func (w *World) ExitGame() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.inGame = false
+
+	return nil
+}
+
+// NewGame enters a new game, with a new map seed.
+func (w *World) NewGame() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.inGame = true
+	w.mapSeed++
+
+	return nil
+}
+
+func (w *World) CreateLobbyGame(gameCounter int) (string, error) {
+	if err := w.NewGame(); err != nil {
+		return "", err
+	}
+
+	return w.LastGameName(), nil
+}
+
+func (w *World) JoinOnlineGame(gameName, password string) error {
+	return w.NewGame()
+}
+
+// movePointer updates the hovered unit, monsters first like the game does
+func (w *World) movePointer(x, y int) {
+	w.pointer = w.screenToGame(x, y)
+	w.hover = data.HoverData{}
+
+	for _, m := range w.monsters {
+		if m.Stats[stat.Life] > 0 && pather.DistanceFromPoint(m.Position, w.pointer) <= hoverDistance {
+			w.hover = data.HoverData{IsHovered: true, UnitID: m.UnitID, UnitType: 1}
+			return
+		}
+	}
+	for _, it := range w.items {
+		if it.Location.LocationType == item.LocationGround && pather.DistanceFromPoint(it.Position, w.pointer) <= hoverDistance {
+			w.hover = data.HoverData{IsHovered: true, UnitID: it.UnitID, UnitType: 4}
+			return
+		}
+	}
+}
+
+func (w *World) moveTo(pos data.Position) {
+	if w.area.IsWalkable(pos) {
+		w.player.Position = pos
+	}
+}
+
+// teleport moves the player to the pointer using the mana of a cast, over any terrain
+func (w *World) teleport() {
+	cost := pather.TeleportManaCost(w.player.Skills[skill.Teleport].Level)
+	mana, _ := w.player.FindStat(stat.Mana, 0)
+	if mana.Value < cost || !w.area.IsWalkable(w.pointer) {
+		return
+	}
+
+	for i, s := range w.player.Stats {
+		if s.ID == stat.Mana {
+			w.player.Stats[i].Value -= cost
+		}
+	}
+	w.player.Position = w.pointer
+	w.teleports++
+}
+
+func (w *World) itemIndex(id data.UnitID) int {
+	for i, it := range w.items {
+		if it.UnitID == id && it.Location.LocationType == item.LocationGround {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func (w *World) pickUp(i int) {
+	w.items[i].Location = item.Location{LocationType: item.LocationInventory}
+	w.items[i].Position = data.Position{}
+	if w.hover.UnitID == w.items[i].UnitID {
+		w.hover = data.HoverData{}
+	}
+}
+
+func (w *World) addInput(in game.Input) {
+	in.Frame = w.frame
+	w.inputs = append(w.inputs, in)
+}
+
+func keyOf(kb data.KeyBinding) byte {
+	if kb.Key1[0] == 0 || kb.Key1[0] == 255 {
+		return kb.Key2[0]
+	}
+
+	return kb.Key1[0]
+}
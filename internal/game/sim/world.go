@@ -0,0 +1,406 @@
+// Package sim is a simulated game backend: a single area grid with the player, monsters and items on the ground. It
+// implements the game interfaces used by the context, so actions and runs can be tested without a game client.
+package sim
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"sync"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/area"
+	"github.com/hectorgimenez/d2go/pkg/data/item"
+	"github.com/hectorgimenez/d2go/pkg/data/mode"
+	"github.com/hectorgimenez/d2go/pkg/data/npc"
+	"github.com/hectorgimenez/d2go/pkg/data/stat"
+	"github.com/hectorgimenez/koolo/internal/config"
+	"github.com/hectorgimenez/koolo/internal/game"
+)
+
+const (
+	// Same client area size as a 1280x720 game window
+	gameAreaWidth  = 1280
+	gameAreaHeight = 720
+
+	// DefaultAttackDamage is the life removed from a monster on every attack
+	DefaultAttackDamage = 100
+	// PickupDistance is the max distance to pick up an item, like the packet pickup
+	PickupDistance = 6
+)
+
+var (
+	_ game.InputSender  = (*World)(nil)
+	_ game.StateReader  = (*World)(nil)
+	_ game.PacketSender = (*World)(nil)
+	_ game.Injector     = (*World)(nil)
+	_ game.GameManager  = (*World)(nil)
+)
+
+// World is the state of the simulated game. Inputs and packets change it the way the game would: the force move key
+// moves the player to the tile under the pointer, a left click attacks the monster or picks up the item under it.
+type World struct {
+	AttackDamage int
+
+	mu          sync.Mutex
+	cfg         config.CharacterCfg
+	area        game.AreaData
+	player      data.PlayerUnit
+	monsters    data.Monsters
+	items       []data.Item
+	keyBindings data.KeyBindings
+	mapSeed     uint
+	inGame      bool
+	pointer     data.Position // game coords under the mouse pointer
+	hover       data.HoverData
+	frame       int
+	teleports   int
+	nextUnitID  data.UnitID
+	inputs      []game.Input
+	packets     [][]byte
+}
+
+// NewWorld creates a walkable area of the given size with the player in the middle.
+func NewWorld(a area.ID, width, height int) *World {
+	grid := make([][]game.CollisionType, height)
+	for y := range grid {
+		grid[y] = make([]game.CollisionType, width)
+		for x := range grid[y] {
+			grid[y][x] = game.CollisionTypeWalkable
+		}
+	}
+
+	w := &World{
+		AttackDamage: DefaultAttackDamage,
+		area:         game.AreaData{Area: a, Name: a.Area().Name, Grid: game.NewGrid(grid, 0, 0)},
+		mapSeed:      1,
+		inGame:       true,
+		nextUnitID:   1,
+	}
+	w.player = data.PlayerUnit{
+		Name:     "sim",
+		ID:       w.newUnitID(),
+		Area:     a,
+		Position: data.Position{X: width / 2, Y: height / 2},
+		Stats: stat.Stats{
+			{ID: stat.Level, Value: 1},
+			{ID: stat.Life, Value: 500},
+			{ID: stat.MaxLife, Value: 500},
+			{ID: stat.Mana, Value: 200},
+			{ID: stat.MaxMana, Value: 200},
+		},
+	}
+	// Every action needs the force move binding, the other ones can be set with SetKeyBindings
+	w.keyBindings.ForceMove = data.KeyBinding{Key1: [2]byte{'E', 0}}
+
+	return w
+}
+
+// SetCharacterCfg sets the character config returned with the game data.
+func (w *World) SetCharacterCfg(cfg config.CharacterCfg) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.cfg = cfg
+}
+
+func (w *World) SetKeyBindings(kb data.KeyBindings) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.keyBindings = kb
+}
+
+// Block makes the rectangle from (x1, y1) to (x2, y2), both included, non walkable.
+func (w *World) Block(x1, y1, x2, y2 int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for y := max(0, y1); y <= min(y2, w.area.Height-1); y++ {
+		for x := max(0, x1); x <= min(x2, w.area.Width-1); x++ {
+			w.area.CollisionGrid[y][x] = game.CollisionTypeNonWalkable
+		}
+	}
+	// Recalculates the low priority tiles around the new obstacles
+	w.area.Grid = game.NewGrid(w.area.CollisionGrid, w.area.OffsetX, w.area.OffsetY)
+}
+
+func (w *World) SetPlayerPosition(pos data.Position) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.player.Position = pos
+}
+
+func (w *World) PlayerPosition() data.Position {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.player.Position
+}
+
+// SetPlayerStat sets or adds a stat of the player, like the gold needed to teleport.
+func (w *World) SetPlayerStat(id stat.ID, value int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for i, s := range w.player.Stats {
+		if s.ID == id {
+			w.player.Stats[i].Value = value
+			return
+		}
+	}
+	w.player.Stats = append(w.player.Stats, stat.Data{ID: id, Value: value})
+}
+
+// AddMonster places a monster with the given life, it returns the monster UnitID.
+func (w *World) AddMonster(name npc.ID, pos data.Position, life int) data.UnitID {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	id := w.newUnitID()
+	w.monsters = append(w.monsters, data.Monster{
+		UnitID:   id,
+		Name:     name,
+		Position: pos,
+		Stats:    map[stat.ID]int{stat.Life: life, stat.MaxLife: life},
+		Type:     data.MonsterTypeNone,
+		Mode:     mode.NpcStandingStill,
+	})
+
+	return id
+}
+
+// Monster returns the current state of a monster, dead monsters are kept with 0 life.
+func (w *World) Monster(id data.UnitID) (data.Monster, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, m := range w.monsters {
+		if m.UnitID == id {
+			return m, true
+		}
+	}
+
+	return data.Monster{}, false
+}
+
+// AddItem drops an item on the ground, it returns the item UnitID.
+func (w *World) AddItem(name item.Name, quality item.Quality, pos data.Position) data.UnitID {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	id := w.newUnitID()
+	w.items = append(w.items, data.Item{
+		UnitID:   id,
+		Name:     name,
+		Quality:  quality,
+		Position: pos,
+		Location: item.Location{LocationType: item.LocationGround},
+	})
+
+	return id
+}
+
+// Item returns the current state of an item, on the ground or picked up.
+func (w *World) Item(id data.UnitID) (data.Item, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, it := range w.items {
+		if it.UnitID == id {
+			return it, true
+		}
+	}
+
+	return data.Item{}, false
+}
+
+// Inputs returns every input received so far, the frame is the number of GetData calls when it was sent.
+func (w *World) Inputs() []game.Input {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return append([]game.Input(nil), w.inputs...)
+}
+
+// Teleports returns the number of teleport casts, only the ones with enough mana count.
+func (w *World) Teleports() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.teleports
+}
+
+// Packets returns the raw packets sent with SendPacket.
+func (w *World) Packets() [][]byte {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return append([][]byte(nil), w.packets...)
+}
+
+// GetData returns the game data, every call is a new frame.
+func (w *World) GetData() game.Data {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.frame++
+	player := w.player
+	player.Stats = append(stat.Stats(nil), w.player.Stats...)
+	player.Mode = mode.StandingOutsideTown
+	if w.area.Area.IsTown() {
+		player.Mode = mode.StandingInTown
+	}
+
+	return game.Data{
+		Data: data.Data{
+			PlayerUnit:  player,
+			Monsters:    w.aliveMonsters(),
+			Inventory:   w.inventory(),
+			HoverData:   w.hover,
+			KeyBindings: w.keyBindings,
+			IsIngame:    w.inGame,
+			Game:        data.OnlineGame{LastGameName: w.gameName(), FPS: 25},
+		},
+		CharacterCfg: w.cfg,
+		AreaData:     w.area,
+		Areas:        map[area.ID]game.AreaData{w.area.Area: w.area},
+	}
+}
+
+func (w *World) GetInventory() data.Inventory {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.inventory()
+}
+
+func (w *World) HoveredData() data.HoverData {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.hover
+}
+
+func (w *World) MapSeed() uint {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.mapSeed
+}
+
+// FetchMapData does nothing, the area grid is already known.
+func (w *World) FetchMapData() error {
+	return nil
+}
+
+func (w *World) GameAreaSize() (int, int) {
+	return gameAreaWidth, gameAreaHeight
+}
+
+func (w *World) InGame() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.inGame
+}
+
+func (w *World) IsOnline() bool {
+	return false
+}
+
+func (w *World) IsInLobby() bool {
+	return false
+}
+
+func (w *World) IsInCharacterSelectionScreen() bool {
+	return !w.InGame()
+}
+
+func (w *World) IsInCharacterCreationScreen() bool {
+	return false
+}
+
+func (w *World) IsDismissableModalPresent() (bool, string) {
+	return false, ""
+}
+
+func (w *World) LegacyGraphics() bool {
+	return false
+}
+
+func (w *World) GetPanel(panelPath ...string) data.Panel {
+	return data.Panel{}
+}
+
+func (w *World) GetMercList() []game.MercOption {
+	return nil
+}
+
+func (w *World) GetSelectedCharacterName() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.cfg.CharacterName
+}
+
+func (w *World) LastGameName() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.gameName()
+}
+
+func (w *World) LastGamePass() string {
+	return ""
+}
+
+// Screenshot returns a blank image of the game area size.
+func (w *World) Screenshot() image.Image {
+	return image.NewRGBA(image.Rect(0, 0, gameAreaWidth, gameAreaHeight))
+}
+
+func (w *World) newUnitID() data.UnitID {
+	id := w.nextUnitID
+	w.nextUnitID++
+
+	return id
+}
+
+func (w *World) gameName() string {
+	return fmt.Sprintf("sim-%d", w.mapSeed)
+}
+
+func (w *World) aliveMonsters() data.Monsters {
+	monsters := make(data.Monsters, 0, len(w.monsters))
+	for _, m := range w.monsters {
+		if m.Stats[stat.Life] > 0 {
+			m.IsHovered = w.hover.IsHovered && w.hover.UnitID == m.UnitID
+			monsters = append(monsters, m)
+		}
+	}
+
+	return monsters
+}
+
+func (w *World) inventory() data.Inventory {
+	items := make([]data.Item, len(w.items))
+	for i, it := range w.items {
+		it.IsHovered = w.hover.IsHovered && w.hover.UnitID == it.UnitID
+		items[i] = it
+	}
+
+	return data.Inventory{AllItems: items}
+}
+
+// screenToGame is the inverse of the isometric transformation done by the path finder
+func (w *World) screenToGame(x, y int) data.Position {
+	a := float64(x-gameAreaWidth/2) / 19.8
+	b := float64(y-gameAreaHeight/2) / 9.9
+
+	return data.Position{
+		X: w.player.Position.X + int(math.Round((a+b)/2)),
+		Y: w.player.Position.Y + int(math.Round((b-a)/2)),
+	}
+}
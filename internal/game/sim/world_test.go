@@ -0,0 +1,203 @@
+package sim
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/area"
+	"github.com/hectorgimenez/d2go/pkg/data/item"
+	"github.com/hectorgimenez/d2go/pkg/data/npc"
+	"github.com/hectorgimenez/d2go/pkg/data/skill"
+	"github.com/hectorgimenez/d2go/pkg/data/stat"
+	"github.com/hectorgimenez/koolo/internal/action/step"
+	"github.com/hectorgimenez/koolo/internal/config"
+	"github.com/hectorgimenez/koolo/internal/context"
+	"github.com/hectorgimenez/koolo/internal/game"
+)
+
+func TestMoveToAroundWall(t *testing.T) {
+	w := NewWorld(area.BloodMoor, 100, 100)
+	w.SetPlayerPosition(data.Position{X: 20, Y: 30})
+	// A wall between the player and the destination, the way around is at the bottom
+	w.Block(50, 0, 52, 80)
+
+	ctx := NewContext("sim", w, &config.CharacterCfg{}, nil)
+	defer ctx.Detach()
+
+	dest := data.Position{X: 80, Y: 30}
+	if err := step.MoveTo(dest, step.WithIgnoreMonsters()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if pos := w.PlayerPosition(); ctx.PathFinder.DistanceFromMe(dest) > step.DistanceToFinishMoving || pos.X < 53 {
+		t.Errorf("Expected the player at the destination, got %+v", pos)
+	}
+	for _, in := range w.Inputs() {
+		if in.Type == game.InputClick {
+			t.Errorf("Expected to walk with the force move key only, got %+v", in)
+		}
+	}
+}
+
+// teleportWorld returns a world where the player can teleport, with the given mana
+func teleportWorld(width, height, mana int) (*World, *config.CharacterCfg) {
+	w := NewWorld(area.BloodMoor, width, height)
+	w.SetPlayerStat(stat.Gold, 10000)
+	w.SetPlayerStat(stat.Mana, mana)
+	kb := data.KeyBindings{ForceMove: data.KeyBinding{Key1: [2]byte{'E', 0}}}
+	kb.Skills[0] = data.SkillBinding{SkillID: skill.Teleport, KeyBinding: data.KeyBinding{Key1: [2]byte{'F', 0}}}
+	w.SetKeyBindings(kb)
+
+	cfg := &config.CharacterCfg{}
+	cfg.Character.UseTeleport = true
+
+	return w, cfg
+}
+
+func TestMoveToTeleportsOverWall(t *testing.T) {
+	w, cfg := teleportWorld(100, 100, 200)
+	w.SetPlayerPosition(data.Position{X: 20, Y: 30})
+	w.Block(45, 0, 55, 90)
+
+	ctx := NewContext("sim", w, cfg, nil)
+	defer ctx.Detach()
+
+	dest := data.Position{X: 80, Y: 30}
+	if err := step.MoveTo(dest, step.WithIgnoreMonsters()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if ctx.PathFinder.DistanceFromMe(dest) > step.DistanceToFinishMoving {
+		t.Errorf("Expected the player at the destination, got %+v", w.PlayerPosition())
+	}
+	// Following the walking path around the wall would take 5 casts at least
+	if casts := w.Teleports(); casts == 0 || casts > 3 {
+		t.Errorf("Expected 3 casts at most over the wall, got %d", casts)
+	}
+}
+
+func TestMoveToWalksWithoutMana(t *testing.T) {
+	// Enough mana for a single cast
+	w, cfg := teleportWorld(100, 100, 30)
+	w.SetPlayerPosition(data.Position{X: 10, Y: 50})
+
+	ctx := NewContext("sim", w, cfg, nil)
+	defer ctx.Detach()
+
+	dest := data.Position{X: 85, Y: 50}
+	if err := step.MoveTo(dest, step.WithIgnoreMonsters()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if ctx.PathFinder.DistanceFromMe(dest) > step.DistanceToFinishMoving {
+		t.Errorf("Expected the player at the destination, got %+v", w.PlayerPosition())
+	}
+	if casts := w.Teleports(); casts != 1 {
+		t.Errorf("Expected a single cast, got %d", casts)
+	}
+	walked := false
+	for _, in := range w.Inputs() {
+		walked = walked || (in.Type == game.InputKey && in.Key == 'E')
+	}
+	if !walked {
+		t.Errorf("Expected to walk with the force move key")
+	}
+}
+
+// testCharacter only implements what the movement needs, the other methods panic
+type testCharacter struct {
+	context.Character
+}
+
+func (testCharacter) ShouldIgnoreMonster(data.Monster) bool {
+	return false
+}
+
+func TestMoveToStopsOnMonsters(t *testing.T) {
+	w := NewWorld(area.BloodMoor, 100, 100)
+	w.SetPlayerPosition(data.Position{X: 20, Y: 30})
+	w.AddMonster(npc.Zombie, data.Position{X: 40, Y: 30}, 100)
+
+	cfg := &config.CharacterCfg{}
+	cfg.Character.ClearPathDist = 10
+	ctx := NewContext("sim", w, cfg, nil)
+	defer ctx.Detach()
+	ctx.Char = testCharacter{}
+
+	err := step.MoveTo(data.Position{X: 80, Y: 30})
+	if !errors.Is(err, step.ErrMonstersInPath) {
+		t.Fatalf("Expected to stop because of the monster, got %v", err)
+	}
+	if dist := ctx.PathFinder.DistanceFromMe(data.Position{X: 40, Y: 30}); dist > cfg.Character.ClearPathDist {
+		t.Errorf("Expected to stop close to the monster, got %d", dist)
+	}
+}
+
+func TestPickupItemPacket(t *testing.T) {
+	w := NewWorld(area.BloodMoor, 100, 100)
+	w.SetPlayerPosition(data.Position{X: 50, Y: 50})
+	near := w.AddItem("BerRune", item.QualityNormal, data.Position{X: 53, Y: 50})
+	far := w.AddItem("JahRune", item.QualityNormal, data.Position{X: 70, Y: 50})
+
+	ctx := NewContext("sim", w, &config.CharacterCfg{}, nil)
+	defer ctx.Detach()
+
+	ber, _ := w.Item(near)
+	if err := step.PickupItemPacket(ber, 1); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if it, _ := w.Item(near); it.Location.LocationType != item.LocationInventory {
+		t.Errorf("Expected the item in the inventory, got %+v", it.Location)
+	}
+	if _, found := ctx.Data.Inventory.Find("BerRune", item.LocationInventory); !found {
+		t.Errorf("Expected the context inventory to be refreshed")
+	}
+
+	jah, _ := w.Item(far)
+	if err := step.PickupItemPacket(jah, 1); !errors.Is(err, step.ErrItemTooFar) {
+		t.Errorf("Expected the item to be too far, got %v", err)
+	}
+}
+
+func TestClickAttacksHoveredMonster(t *testing.T) {
+	w := NewWorld(area.BloodMoor, 100, 100)
+	w.SetPlayerPosition(data.Position{X: 50, Y: 50})
+	id := w.AddMonster(npc.Zombie, data.Position{X: 55, Y: 48}, 150)
+
+	ctx := NewContext("sim", w, &config.CharacterCfg{}, nil)
+	defer ctx.Detach()
+
+	monster, _ := ctx.Data.Monsters.FindByID(id)
+	x, y := ctx.PathFinder.GameCoordsToScreenCords(monster.Position.X, monster.Position.Y)
+	ctx.HID.MovePointer(x, y)
+	if hover := ctx.GameReader.HoveredData(); !hover.IsHovered || hover.UnitID != id {
+		t.Fatalf("Expected the monster to be hovered, got %+v", hover)
+	}
+
+	ctx.HID.Click(game.LeftButton, x, y)
+	ctx.HID.Click(game.LeftButton, x, y)
+	if m, _ := w.Monster(id); m.Stats[stat.Life] != 0 {
+		t.Errorf("Expected the monster to be dead, got %d life", m.Stats[stat.Life])
+	}
+
+	ctx.RefreshGameData()
+	if _, found := ctx.Data.Monsters.FindByID(id); found {
+		t.Errorf("Dead monsters should not be returned")
+	}
+	if inputs := len(w.Inputs()); inputs != 3 {
+		t.Errorf("Expected 3 inputs, got %d", inputs)
+	}
+}
+
+func TestGameManager(t *testing.T) {
+	w := NewWorld(area.RogueEncampment, 10, 10)
+	if err := w.ExitGame(); err != nil || w.InGame() || !w.IsInCharacterSelectionScreen() {
+		t.Fatalf("Expected to be out of game")
+	}
+
+	name, err := w.CreateLobbyGame(1)
+	if err != nil || !w.InGame() || name != "sim-2" || w.MapSeed() != 2 {
+		t.Errorf("Unexpected new game %q, seed %d: %v", name, w.MapSeed(), err)
+	}
+	if d := w.GetData(); d.PlayerUnit.Area != area.RogueEncampment || !d.IsIngame || d.AreaData.Grid == nil {
+		t.Errorf("Unexpected game data %+v", d.PlayerUnit)
+	}
+}
@@ -33,6 +33,12 @@ func direction(from, to data.Position) (dx, dy int) {
 }
 
 func CalculatePath(g *game.Grid, start, goal data.Position) ([]data.Position, int, bool) {
+	return CalculatePathWithCosts(g, nil, start, goal)
+}
+
+// CalculatePathWithCosts is CalculatePath with an extra cost for entering every tile, indexed like the collision grid
+// ([y][x]). A nil extraCosts adds nothing.
+func CalculatePathWithCosts(g *game.Grid, extraCosts [][]int, start, goal data.Position) ([]data.Position, int, bool) {
 	pq := make(PriorityQueue, 0)
 	heap.Init(&pq)
 
@@ -70,6 +76,9 @@ func CalculatePath(g *game.Grid, start, goal data.Position) ([]data.Position, in
 
 		for _, neighbor := range neighbors {
 			newCost := costSoFar[current.X][current.Y] + getCost(g.CollisionGrid[neighbor.Y][neighbor.X])
+			if extraCosts != nil {
+				newCost += extraCosts[neighbor.Y][neighbor.X]
+			}
 
 			// Handicap for changing direction, this prevents zig-zagging around obstacles
 			//curDirX, curDirY := direction(cameFrom[current.X][current.Y], current.Position)
@@ -9,12 +9,14 @@ import (
 	"github.com/hectorgimenez/koolo/internal/game"
 )
 
+var (
+	start = data.Position{X: 336, Y: 701}
+	goal  = data.Position{X: 11, Y: 330}
+)
+
 func BenchmarkAstar(b *testing.B) {
 	grid := loadGrid()
 
-	start := data.Position{X: 336, Y: 701}
-	goal := data.Position{X: 11, Y: 330}
-
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
 		CalculatePath(grid, start, goal)
@@ -24,15 +26,12 @@ func BenchmarkAstar(b *testing.B) {
 func TestAstar(t *testing.T) {
 	grid := loadGrid()
 
-	start := data.Position{X: 336, Y: 701}
-	goal := data.Position{X: 11, Y: 330}
-
 	p, dist, found := CalculatePath(grid, start, goal)
-	if dist != 546 {
-		t.Errorf("Expected distance to be 546, got %d", dist)
+	if dist != 656 {
+		t.Errorf("Expected distance to be 656, got %d", dist)
 	}
-	if len(p) != 546 {
-		t.Errorf("Expected path length to be 546, got %d", len(p))
+	if len(p) != 656 {
+		t.Errorf("Expected path length to be 656, got %d", len(p))
 	}
 	if !found {
 		t.Errorf("Expected path to be found")
@@ -0,0 +1,412 @@
+package astar
+
+import (
+	"math"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/koolo/internal/game"
+)
+
+// DefaultClusterSize is the side, in tiles, of the square clusters the grid is split into
+const DefaultClusterSize = 32
+
+// Max tiles between two entrances of the same border
+const maxEntranceSpacing = 8
+
+// Abstraction is the precomputed cluster graph of a grid (HPA*): the grid is split in square clusters, the walkable
+// tiles at both sides of the cluster borders are the graph nodes and the edges are the path costs between them. It only
+// depends on the static area grid, so it's built once per area and reused for every path.
+type Abstraction struct {
+	clusterSize  int
+	width        int
+	height       int
+	clustersX    int
+	clustersY    int
+	nodes        []abstractNode
+	clusterNodes [][]int
+}
+
+type abstractNode struct {
+	data.Position
+	cluster int
+	edges   []abstractEdge
+}
+
+type abstractEdge struct {
+	to   int
+	cost int
+}
+
+// NewAbstraction builds the cluster graph of the grid, the cost between the entrances of every cluster is calculated
+// with the same tile costs and moves as CalculatePath.
+func NewAbstraction(g *game.Grid, clusterSize int) *Abstraction {
+	a := &Abstraction{
+		clusterSize: clusterSize,
+		width:       g.Width,
+		height:      g.Height,
+		clustersX:   (g.Width + clusterSize - 1) / clusterSize,
+		clustersY:   (g.Height + clusterSize - 1) / clusterSize,
+	}
+	a.clusterNodes = make([][]int, a.clustersX*a.clustersY)
+
+	nodeAt := make(map[data.Position]int)
+	for cy := 0; cy < a.clustersY; cy++ {
+		for cx := 0; cx < a.clustersX; cx++ {
+			minX, minY, maxX, maxY := a.clusterBounds(cy*a.clustersX + cx)
+			// Border with the cluster at the right
+			if cx < a.clustersX-1 {
+				a.addEntrances(g, nodeAt, minY, maxY, func(i int) (data.Position, data.Position) {
+					return data.Position{X: maxX - 1, Y: i}, data.Position{X: maxX, Y: i}
+				})
+			}
+			// Border with the cluster below
+			if cy < a.clustersY-1 {
+				a.addEntrances(g, nodeAt, minX, maxX, func(i int) (data.Position, data.Position) {
+					return data.Position{X: i, Y: maxY - 1}, data.Position{X: i, Y: maxY}
+				})
+			}
+		}
+	}
+
+	costs := make([]int, clusterSize*clusterSize)
+	for c := range a.clusterNodes {
+		a.connectCluster(g, c, costs)
+	}
+
+	return a
+}
+
+// CalculatePath returns the same kind of path as CalculatePath, but the search only expands the clusters around the
+// route found in the cluster graph. The grid can have more obstacles than the one used to build the abstraction
+// (monsters, objects), when the route is blocked by them it falls back to the plain search on the whole grid.
+func (a *Abstraction) CalculatePath(g *game.Grid, start, goal data.Position) ([]data.Position, int, bool) {
+	if g.Width != a.width || g.Height != a.height || !a.inside(start) || !a.inside(goal) {
+		return CalculatePath(g, start, goal)
+	}
+
+	if corridor, found := a.corridor(g, start, goal); found {
+		if path, distance, found := a.refine(g, start, goal, corridor); found {
+			return path, distance, true
+		}
+	}
+
+	return CalculatePath(g, start, goal)
+}
+
+// addEntrances adds the nodes of a border between two clusters, tiles returns the tiles at both sides of the border
+func (a *Abstraction) addEntrances(g *game.Grid, nodeAt map[data.Position]int, from, to int, tiles func(i int) (data.Position, data.Position)) {
+	runStart := -1
+	for i := from; i <= to; i++ {
+		open := false
+		if i < to {
+			p1, p2 := tiles(i)
+			open = isWalkable(g, p1) && isWalkable(g, p2)
+		}
+		if open {
+			if runStart < 0 {
+				runStart = i
+			}
+			continue
+		}
+		if runStart < 0 {
+			continue
+		}
+
+		// Long runs are split in chunks, the entrance of every chunk is its cheapest tile closest to the middle, so the
+		// routes don't go along the walls (low priority tiles)
+		for chunk := runStart; chunk < i; chunk += maxEntranceSpacing {
+			end := min(chunk+maxEntranceSpacing, i)
+			mid := (chunk + end - 1) / 2
+			best, bestCost := -1, 0
+			for j := chunk; j < end; j++ {
+				p1, p2 := tiles(j)
+				cost := getCost(g.CollisionGrid[p1.Y][p1.X]) + getCost(g.CollisionGrid[p2.Y][p2.X])
+				if best < 0 || cost < bestCost || (cost == bestCost && abs(j-mid) < abs(best-mid)) {
+					best, bestCost = j, cost
+				}
+			}
+			a.addEntrance(g, nodeAt, tiles, best)
+		}
+		runStart = -1
+	}
+}
+
+func (a *Abstraction) addEntrance(g *game.Grid, nodeAt map[data.Position]int, tiles func(i int) (data.Position, data.Position), i int) {
+	p1, p2 := tiles(i)
+	n1 := a.node(nodeAt, p1)
+	n2 := a.node(nodeAt, p2)
+	a.nodes[n1].edges = append(a.nodes[n1].edges, abstractEdge{to: n2, cost: getCost(g.CollisionGrid[p2.Y][p2.X])})
+	a.nodes[n2].edges = append(a.nodes[n2].edges, abstractEdge{to: n1, cost: getCost(g.CollisionGrid[p1.Y][p1.X])})
+}
+
+func (a *Abstraction) node(nodeAt map[data.Position]int, p data.Position) int {
+	if n, found := nodeAt[p]; found {
+		return n
+	}
+
+	n := len(a.nodes)
+	c := a.clusterOf(p)
+	a.nodes = append(a.nodes, abstractNode{Position: p, cluster: c})
+	a.clusterNodes[c] = append(a.clusterNodes[c], n)
+	nodeAt[p] = n
+
+	return n
+}
+
+// connectCluster adds the edges between the entrances of the cluster, with the path cost inside the cluster
+func (a *Abstraction) connectCluster(g *game.Grid, c int, costs []int) {
+	nodes := a.clusterNodes[c]
+	for _, from := range nodes {
+		a.clusterCosts(g, c, a.nodes[from].Position, costs)
+		for _, to := range nodes {
+			if to == from {
+				continue
+			}
+			if cost := costs[a.localIndex(a.nodes[to].Position)]; cost != math.MaxInt32 {
+				a.nodes[from].edges = append(a.nodes[from].edges, abstractEdge{to: to, cost: cost})
+			}
+		}
+	}
+}
+
+// clusterCosts fills costs with the cost from the position to every tile of the cluster without leaving it (Dijkstra),
+// costs is indexed by localIndex
+func (a *Abstraction) clusterCosts(g *game.Grid, c int, from data.Position, costs []int) {
+	minX, minY, maxX, maxY := a.clusterBounds(c)
+	for i := range costs {
+		costs[i] = math.MaxInt32
+	}
+
+	size := a.clusterSize
+	pq := make(routeQueue, 0, 64)
+	pq.push(routeItem{node: (from.Y-minY)*size + from.X - minX})
+	costs[(from.Y-minY)*size+from.X-minX] = 0
+
+	neighbors := make([]data.Position, 0, 8)
+	for len(pq) > 0 {
+		current := pq.pop()
+		if current.cost > costs[current.node] {
+			continue
+		}
+
+		pos := data.Position{X: minX + current.node%size, Y: minY + current.node/size}
+		updateNeighbors(g, &Node{Position: pos}, &neighbors)
+		for _, n := range neighbors {
+			if n.X < minX || n.X >= maxX || n.Y < minY || n.Y >= maxY {
+				continue
+			}
+			newCost := current.cost + getCost(g.CollisionGrid[n.Y][n.X])
+			if i := (n.Y-minY)*size + n.X - minX; newCost < costs[i] {
+				costs[i] = newCost
+				pq.push(routeItem{node: i, cost: newCost, priority: newCost})
+			}
+		}
+	}
+}
+
+// corridor searches the route in the cluster graph, it returns the clusters around it
+func (a *Abstraction) corridor(g *game.Grid, start, goal data.Position) ([]bool, bool) {
+	startCluster, goalCluster := a.clusterOf(start), a.clusterOf(goal)
+	clusters := []int{startCluster, goalCluster}
+
+	if startCluster != goalCluster || !a.connectedInCluster(g, startCluster, start, goal) {
+		route, found := a.route(g, start, goal)
+		if !found {
+			return nil, false
+		}
+		for _, n := range route {
+			clusters = append(clusters, a.nodes[n].cluster)
+		}
+	}
+
+	// The neighbor clusters are included too, the optimal path doesn't always go through the entrances of the route
+	corridor := make([]bool, len(a.clusterNodes))
+	for _, c := range clusters {
+		cx, cy := c%a.clustersX, c/a.clustersX
+		for y := max(0, cy-1); y <= min(a.clustersY-1, cy+1); y++ {
+			for x := max(0, cx-1); x <= min(a.clustersX-1, cx+1); x++ {
+				corridor[y*a.clustersX+x] = true
+			}
+		}
+	}
+
+	return corridor, true
+}
+
+func (a *Abstraction) connectedInCluster(g *game.Grid, c int, from, to data.Position) bool {
+	costs := make([]int, a.clusterSize*a.clusterSize)
+	a.clusterCosts(g, c, from, costs)
+
+	return costs[a.localIndex(to)] != math.MaxInt32
+}
+
+// route runs A* on the cluster graph, start and goal are connected to the entrances of their clusters
+func (a *Abstraction) route(g *game.Grid, start, goal data.Position) ([]int, bool) {
+	startCluster, goalCluster := a.clusterOf(start), a.clusterOf(goal)
+	startCosts := make([]int, a.clusterSize*a.clusterSize)
+	goalCosts := make([]int, a.clusterSize*a.clusterSize)
+	a.clusterCosts(g, startCluster, start, startCosts)
+	a.clusterCosts(g, goalCluster, goal, goalCosts)
+
+	// The goal is the virtual node after the last entrance
+	goalNode := len(a.nodes)
+	costSoFar := make([]int, len(a.nodes)+1)
+	cameFrom := make([]int, len(a.nodes)+1)
+	for i := range costSoFar {
+		costSoFar[i] = math.MaxInt32
+		cameFrom[i] = -1
+	}
+
+	pq := make(routeQueue, 0)
+	push := func(n, cost, from int) {
+		if cost >= costSoFar[n] {
+			return
+		}
+		costSoFar[n] = cost
+		cameFrom[n] = from
+		priority := cost
+		if n != goalNode {
+			priority += int(0.5 * float64(heuristic(a.nodes[n].Position, goal)))
+		}
+		pq.push(routeItem{node: n, cost: cost, priority: priority})
+	}
+
+	for _, n := range a.clusterNodes[startCluster] {
+		if cost := startCosts[a.localIndex(a.nodes[n].Position)]; cost != math.MaxInt32 {
+			push(n, cost, -1)
+		}
+	}
+
+	for len(pq) > 0 {
+		current := pq.pop()
+		if current.cost > costSoFar[current.node] {
+			continue
+		}
+
+		if current.node == goalNode {
+			var route []int
+			for n := cameFrom[goalNode]; n >= 0; n = cameFrom[n] {
+				route = append(route, n)
+			}
+			return route, true
+		}
+
+		node := a.nodes[current.node]
+		for _, e := range node.edges {
+			push(e.to, current.cost+e.cost, current.node)
+		}
+		if node.cluster == goalCluster {
+			// Cost from the goal to the entrance, close enough to the other way around to choose the route
+			if cost := goalCosts[a.localIndex(node.Position)]; cost != math.MaxInt32 {
+				push(goalNode, current.cost+cost, current.node)
+			}
+		}
+	}
+
+	return nil, false
+}
+
+// refine runs the same search as CalculatePath, restricted to the corridor tiles
+func (a *Abstraction) refine(g *game.Grid, start, goal data.Position, corridor []bool) ([]data.Position, int, bool) {
+	// Every corridor cluster gets a slot in the cost arrays, much smaller than the whole grid
+	slots := make([]int, len(corridor))
+	var clusters []int
+	for c, included := range corridor {
+		slots[c] = -1
+		if included {
+			slots[c] = len(clusters)
+			clusters = append(clusters, c)
+		}
+	}
+	size := a.clusterSize
+	tiles := size * size
+	index := func(p data.Position) int {
+		if s := slots[a.clusterOf(p)]; s >= 0 {
+			return s*tiles + a.localIndex(p)
+		}
+		return -1
+	}
+	position := func(i int) data.Position {
+		minX, minY, _, _ := a.clusterBounds(clusters[i/tiles])
+		return data.Position{X: minX + i%tiles%size, Y: minY + i%tiles/size}
+	}
+
+	costSoFar := make([]int, len(clusters)*tiles)
+	cameFrom := make([]int, len(clusters)*tiles)
+	for i := range costSoFar {
+		costSoFar[i] = math.MaxInt32
+	}
+
+	startIndex, goalIndex := index(start), index(goal)
+	pq := make(routeQueue, 0, 256)
+	pq.push(routeItem{node: startIndex, priority: heuristic(start, goal)})
+	costSoFar[startIndex] = 0
+
+	neighbors := make([]data.Position, 0, 8)
+	for len(pq) > 0 {
+		current := pq.pop()
+
+		if current.node == goalIndex {
+			path := []data.Position{goal}
+			for i := goalIndex; i != startIndex; {
+				i = cameFrom[i]
+				path = append(path, position(i))
+			}
+			for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+				path[i], path[j] = path[j], path[i]
+			}
+			return path, len(path), true
+		}
+
+		currentCost := costSoFar[current.node]
+		updateNeighbors(g, &Node{Position: position(current.node)}, &neighbors)
+		for _, neighbor := range neighbors {
+			i := index(neighbor)
+			if i < 0 {
+				continue
+			}
+
+			newCost := currentCost + getCost(g.CollisionGrid[neighbor.Y][neighbor.X])
+			if newCost < costSoFar[i] {
+				costSoFar[i] = newCost
+				priority := newCost + int(0.5*float64(heuristic(neighbor, goal)))
+				pq.push(routeItem{node: i, cost: newCost, priority: priority})
+				cameFrom[i] = current.node
+			}
+		}
+	}
+
+	return nil, 0, false
+}
+
+func (a *Abstraction) clusterOf(p data.Position) int {
+	return (p.Y/a.clusterSize)*a.clustersX + p.X/a.clusterSize
+}
+
+// clusterBounds returns the tiles of the cluster, max values excluded
+func (a *Abstraction) clusterBounds(c int) (minX, minY, maxX, maxY int) {
+	minX = (c % a.clustersX) * a.clusterSize
+	minY = (c / a.clustersX) * a.clusterSize
+
+	return minX, minY, min(minX+a.clusterSize, a.width), min(minY+a.clusterSize, a.height)
+}
+
+// localIndex is the position of the tile inside its cluster
+func (a *Abstraction) localIndex(p data.Position) int {
+	return (p.Y%a.clusterSize)*a.clusterSize + p.X%a.clusterSize
+}
+
+func (a *Abstraction) inside(p data.Position) bool {
+	return p.X >= 0 && p.X < a.width && p.Y >= 0 && p.Y < a.height
+}
+
+func isWalkable(g *game.Grid, p data.Position) bool {
+	return g.CollisionGrid[p.Y][p.X] != game.CollisionTypeNonWalkable
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,203 @@
+package astar
+
+import (
+	"math/rand"
+	"os"
+	"testing"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/koolo/internal/game"
+)
+
+func TestHierarchicalPath(t *testing.T) {
+	grid := loadGrid()
+	a := NewAbstraction(grid, DefaultClusterSize)
+
+	expected, _, _ := CalculatePath(grid, start, goal)
+	p, dist, found := a.CalculatePath(grid, start, goal)
+	if !found {
+		t.Fatalf("Expected path to be found")
+	}
+	if dist != 656 || len(p) != 656 {
+		t.Errorf("Expected distance to be 656, got %d", dist)
+	}
+	if pathCost(grid, p) != pathCost(grid, expected) {
+		t.Errorf("Expected the same cost as the plain search, got %d instead of %d", pathCost(grid, p), pathCost(grid, expected))
+	}
+	if p[0] != start || p[len(p)-1] != goal {
+		t.Errorf("Expected the path from %v to %v, got %v to %v", start, goal, p[0], p[len(p)-1])
+	}
+}
+
+func TestHierarchicalPathRandomPairs(t *testing.T) {
+	grid := loadGrid()
+	a := NewAbstraction(grid, DefaultClusterSize)
+	walkable := walkableTiles(grid)
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 30; i++ {
+		from, to := walkable[r.Intn(len(walkable))], walkable[r.Intn(len(walkable))]
+		expected, _, expectedFound := CalculatePath(grid, from, to)
+		p, _, found := a.CalculatePath(grid, from, to)
+		if found != expectedFound {
+			t.Fatalf("From %v to %v: expected found to be %v", from, to, expectedFound)
+		}
+		if !found {
+			continue
+		}
+		if !isValidPath(grid, p) || p[0] != from || p[len(p)-1] != to {
+			t.Fatalf("From %v to %v: invalid path", from, to)
+		}
+		// HPA* is close to optimal, not always optimal
+		if cost, optimal := pathCost(grid, p), pathCost(grid, expected); cost > optimal+optimal/20 {
+			t.Errorf("From %v to %v: expected a cost close to %d, got %d", from, to, optimal, cost)
+		}
+	}
+}
+
+func TestHierarchicalPathNewObstacles(t *testing.T) {
+	grid := loadGrid()
+	a := NewAbstraction(grid, DefaultClusterSize)
+
+	// Blocks the path found by the abstraction, like a barricade tower would
+	blocked := grid.Copy()
+	p, _, _ := a.CalculatePath(grid, start, goal)
+	for _, pos := range p[len(p)/2-3 : len(p)/2+3] {
+		for y := pos.Y - 3; y <= pos.Y+3; y++ {
+			for x := pos.X - 3; x <= pos.X+3; x++ {
+				blocked.CollisionGrid[y][x] = game.CollisionTypeNonWalkable
+			}
+		}
+	}
+
+	expected, expectedDist, expectedFound := CalculatePath(blocked, start, goal)
+	p, dist, found := a.CalculatePath(blocked, start, goal)
+	if found != expectedFound || dist != expectedDist {
+		t.Fatalf("Expected the plain search result, got found %v and distance %d", found, dist)
+	}
+	if found && (!isValidPath(blocked, p) || pathCost(blocked, p) != pathCost(blocked, expected)) {
+		t.Errorf("Expected a path around the new obstacle")
+	}
+}
+
+func TestHierarchicalPathNotFound(t *testing.T) {
+	cg := make([][]game.CollisionType, 100)
+	for y := range cg {
+		cg[y] = make([]game.CollisionType, 100)
+		for x := range cg[y] {
+			cg[y][x] = game.CollisionTypeWalkable
+			if x == 50 {
+				cg[y][x] = game.CollisionTypeNonWalkable
+			}
+		}
+	}
+	grid := game.NewGrid(cg, 0, 0)
+	a := NewAbstraction(grid, 16)
+
+	if _, _, found := a.CalculatePath(grid, data.Position{X: 10, Y: 10}, data.Position{X: 90, Y: 90}); found {
+		t.Errorf("Expected no path across the wall")
+	}
+	if p, _, found := a.CalculatePath(grid, data.Position{X: 10, Y: 10}, data.Position{X: 40, Y: 90}); !found || !isValidPath(grid, p) {
+		t.Errorf("Expected a path at the same side of the wall")
+	}
+}
+
+func BenchmarkHierarchicalPath(b *testing.B) {
+	grid := loadGrid()
+	a := NewAbstraction(grid, DefaultClusterSize)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		a.CalculatePath(grid, start, goal)
+	}
+}
+
+func BenchmarkNewAbstraction(b *testing.B) {
+	grid := loadGrid()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		NewAbstraction(grid, DefaultClusterSize)
+	}
+}
+
+// BenchmarkRecordedGrids compares both searches on the area grids of a game recording, the file is set with the
+// KOOLO_RECORDING environment variable.
+func BenchmarkRecordedGrids(b *testing.B) {
+	path := os.Getenv("KOOLO_RECORDING")
+	if path == "" {
+		b.Skip("KOOLO_RECORDING is not set")
+	}
+	rec, err := game.LoadRecording(path)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	for _, ad := range rec.Areas {
+		if ad.Grid == nil {
+			continue
+		}
+		walkable := walkableTiles(ad.Grid)
+		if len(walkable) == 0 {
+			continue
+		}
+		r := rand.New(rand.NewSource(1))
+		pairs := make([][2]data.Position, 20)
+		for i := range pairs {
+			pairs[i] = [2]data.Position{walkable[r.Intn(len(walkable))], walkable[r.Intn(len(walkable))]}
+		}
+
+		b.Run(ad.Name+"/astar", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				p := pairs[i%len(pairs)]
+				CalculatePath(ad.Grid, p[0], p[1])
+			}
+		})
+		a := NewAbstraction(ad.Grid, DefaultClusterSize)
+		b.Run(ad.Name+"/hierarchical", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				p := pairs[i%len(pairs)]
+				a.CalculatePath(ad.Grid, p[0], p[1])
+			}
+		})
+	}
+}
+
+func walkableTiles(g *game.Grid) []data.Position {
+	var tiles []data.Position
+	for y := 0; y < g.Height; y++ {
+		for x := 0; x < g.Width; x++ {
+			if g.CollisionGrid[y][x] != game.CollisionTypeNonWalkable {
+				tiles = append(tiles, data.Position{X: x, Y: y})
+			}
+		}
+	}
+
+	return tiles
+}
+
+func pathCost(g *game.Grid, p []data.Position) int {
+	cost := 0
+	for _, pos := range p[1:] {
+		cost += getCost(g.CollisionGrid[pos.Y][pos.X])
+	}
+
+	return cost
+}
+
+// isValidPath checks every step is a move allowed by the search
+func isValidPath(g *game.Grid, p []data.Position) bool {
+	neighbors := make([]data.Position, 0, 8)
+	for i := 1; i < len(p); i++ {
+		updateNeighbors(g, &Node{Position: p[i-1]}, &neighbors)
+		valid := false
+		for _, n := range neighbors {
+			valid = valid || n == p[i]
+		}
+		if !valid {
+			return false
+		}
+	}
+
+	return true
+}
@@ -23,3 +23,50 @@ func (pq *PriorityQueue) Pop() interface{} {
 	*pq = old[0 : n-1]
 	return node
 }
+
+// routeQueue is the priority queue of the cluster graph searches. It's a value heap with the same algorithm as
+// container/heap, without the interface conversions on every push and pop.
+type routeQueue []routeItem
+
+type routeItem struct {
+	node     int
+	cost     int
+	priority int
+}
+
+func (q *routeQueue) push(item routeItem) {
+	*q = append(*q, item)
+	h := *q
+	for j := len(h) - 1; j > 0; {
+		i := (j - 1) / 2
+		if i == j || h[j].priority >= h[i].priority {
+			break
+		}
+		h[i], h[j] = h[j], h[i]
+		j = i
+	}
+}
+
+func (q *routeQueue) pop() routeItem {
+	h := *q
+	n := len(h) - 1
+	h[0], h[n] = h[n], h[0]
+	for i := 0; ; {
+		j := 2*i + 1
+		if j >= n {
+			break
+		}
+		if j2 := j + 1; j2 < n && h[j2].priority < h[j].priority {
+			j = j2
+		}
+		if h[j].priority >= h[i].priority {
+			break
+		}
+		h[i], h[j] = h[j], h[i]
+		i = j
+	}
+	item := h[n]
+	*q = h[:n]
+
+	return item
+}
@@ -0,0 +1,163 @@
+package pather
+
+import (
+	"math"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/npc"
+	"github.com/hectorgimenez/d2go/pkg/data/stat"
+	"github.com/hectorgimenez/d2go/pkg/data/state"
+	"github.com/hectorgimenez/koolo/internal/game"
+)
+
+const (
+	// Tiles around a monster with extra cost, ranged monsters hit from further away
+	dangerRadius       = 8
+	rangedDangerRadius = 14
+	// Max extra cost of a single tile, a path through a dangerous pack is still better than no path at all
+	maxTileDanger = 250
+
+	threatElite     = 4
+	threatAura      = 6
+	threatImmune    = 6
+	threatRanged    = 2
+	threatSealElite = 12
+)
+
+// Aura enchanted monsters have the state of their aura
+var auraStates = []state.State{state.Conviction, state.Might, state.Fanaticism, state.Holyfire, state.Holyshock, state.Holywindcold}
+
+// Monsters hitting from a distance, they are dangerous even when the path doesn't get close to them
+var rangedMonsters = map[npc.ID]bool{
+	npc.VileArcher: true, npc.DarkArcher: true, npc.BlackArcher: true, npc.FleshArcher: true,
+	npc.SkeletonArcher: true, npc.ReturnedArcher: true, npc.BoneArcher: true, npc.BurningDeadArcher: true, npc.HorrorArcher: true,
+	npc.ReturnedMage: true, npc.BoneMage: true, npc.BurningDeadMage: true, npc.HorrorMage: true,
+	npc.ReturnedMage2: true, npc.BoneMage2: true, npc.HorrorMage2: true, npc.ReturnedMage3: true, npc.BoneMage3: true,
+	npc.BurningDeadMage2: true, npc.HorrorMage3: true, npc.ReturnedMage4: true, npc.BoneMage4: true,
+	npc.BurningDeadMage3: true, npc.HorrorMage4: true, npc.BaalColdMage: true,
+	npc.Gloam: true, npc.BurningSoul: true, npc.BlackSoul: true, npc.Gloam2: true, npc.BlackSoul2: true,
+	npc.BurningSoul2: true, npc.BurningSoul3: true,
+	npc.VileWitch: true, npc.BloodWitch: true, npc.HellWitch: true, npc.HellWitch2: true, npc.VileWitch2: true, npc.VileWitch3: true,
+	npc.Slinger: true, npc.NightSlinger: true, npc.HellSlinger: true, npc.NightSlinger2: true,
+	npc.Slinger2: true, npc.Slinger3: true, npc.Slinger4: true,
+	npc.Unraveler: true, npc.Heirophant: true, npc.Heirophant2: true, npc.Heirophant3: true, npc.Heirophant4: true,
+	npc.CouncilMember: true, npc.CouncilMember2: true, npc.CouncilMember3: true,
+	npc.FrozenHorror: true, npc.FrozenHorror2: true, npc.FrozenHorror3: true, npc.FrozenHorror4: true, npc.FrozenHorror5: true,
+	npc.CorpseSpitter: true, npc.NecroMage: true,
+}
+
+// Damage types of every class build BuildCharacter supports, a monster immune to all of them can't be killed by the
+// character. Physical builds and mules have no damage types, no monster is a threat to them because of immunities.
+var classDamageTypes = map[string][]stat.Resist{
+	"sorceress":          {stat.ColdImmune},
+	"nova":               {stat.LightImmune},
+	"lightsorc":          {stat.LightImmune},
+	"fireballsorc":       {stat.FireImmune},
+	"hydraorb":           {stat.FireImmune, stat.ColdImmune},
+	"hammerdin":          {stat.MagicImmune},
+	"foh":                {stat.LightImmune},
+	"trapsin":            {stat.LightImmune, stat.FireImmune},
+	"mosaic":             {stat.FireImmune, stat.ColdImmune, stat.LightImmune},
+	"javazon":            {stat.LightImmune},
+	"berserker":          {stat.MagicImmune},
+	"winddruid":          nil, // Tornado is physical
+	"mule":               nil,
+	"sorceress_leveling": {stat.FireImmune, stat.ColdImmune},
+	"necromancer":        {stat.MagicImmune, stat.FireImmune},
+	"paladin":            {stat.MagicImmune, stat.FireImmune},
+	"assassin":           {stat.FireImmune, stat.LightImmune},
+	"druid_leveling":     {stat.FireImmune, stat.ColdImmune},
+	"amazon_leveling":    {stat.LightImmune},
+}
+
+// DangerMap is the extra cost of walking every tile of a grid, indexed like the collision grid ([y][x]). The cost is
+// higher close to the most threatening monsters, so the paths go around dangerous packs when there is another way.
+type DangerMap [][]int
+
+// MonsterThreat scores how dangerous a monster is for a character dealing the given damage types, 0 for the ones not
+// worth going around.
+func MonsterThreat(m data.Monster, damageTypes []stat.Resist) int {
+	threat := 0
+	if game.IsMonsterSealElite(m) {
+		threat += threatSealElite
+	} else if m.IsElite() {
+		threat += threatElite
+	}
+	for _, s := range auraStates {
+		if m.States.HasState(s) {
+			threat += threatAura
+			break
+		}
+	}
+	if len(damageTypes) > 0 && immuneToAll(m, damageTypes) {
+		threat += threatImmune
+	}
+	if rangedMonsters[m.Name] {
+		threat += threatRanged
+	}
+
+	return threat
+}
+
+// NewDangerMap builds the danger map of the grid, monster positions are absolute. It returns nil when no monster is a
+// threat, the paths don't need any extra cost.
+func NewDangerMap(g *game.Grid, monsters []data.Monster, damageTypes []stat.Resist) DangerMap {
+	var dm DangerMap
+	for _, m := range monsters {
+		threat := MonsterThreat(m, damageTypes)
+		if threat == 0 {
+			continue
+		}
+		if dm == nil {
+			dm = make(DangerMap, g.Height)
+			for y := range dm {
+				dm[y] = make([]int, g.Width)
+			}
+		}
+
+		radius := dangerRadius
+		if rangedMonsters[m.Name] {
+			radius = rangedDangerRadius
+		}
+		dm.add(g, g.RelativePosition(m.Position), threat, radius)
+	}
+
+	return dm
+}
+
+// add raises the cost around the position, it decreases linearly from threat*radius next to the monster to nothing at
+// radius tiles
+func (dm DangerMap) add(g *game.Grid, p data.Position, threat, radius int) {
+	for y := max(0, p.Y-radius); y <= min(g.Height-1, p.Y+radius); y++ {
+		for x := max(0, p.X-radius); x <= min(g.Width-1, p.X+radius); x++ {
+			if g.CollisionGrid[y][x] == game.CollisionTypeNonWalkable {
+				continue
+			}
+			dx, dy := float64(x-p.X), float64(y-p.Y)
+			distance := int(math.Sqrt(dx*dx + dy*dy))
+			if distance >= radius {
+				continue
+			}
+			dm[y][x] = min(maxTileDanger, dm[y][x]+threat*(radius-distance))
+		}
+	}
+}
+
+// Danger returns the extra cost of the tile, the position is relative to the grid
+func (dm DangerMap) Danger(p data.Position) int {
+	if p.Y < 0 || p.Y >= len(dm) || p.X < 0 || p.X >= len(dm[p.Y]) {
+		return 0
+	}
+
+	return dm[p.Y][p.X]
+}
+
+func immuneToAll(m data.Monster, damageTypes []stat.Resist) bool {
+	for _, r := range damageTypes {
+		if !m.IsImmune(r) {
+			return false
+		}
+	}
+
+	return true
+}
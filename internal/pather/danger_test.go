@@ -0,0 +1,191 @@
+package pather
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/npc"
+	"github.com/hectorgimenez/d2go/pkg/data/stat"
+	"github.com/hectorgimenez/d2go/pkg/data/state"
+	"github.com/hectorgimenez/koolo/internal/game"
+	"github.com/hectorgimenez/koolo/internal/pather/astar"
+)
+
+func TestMonsterThreat(t *testing.T) {
+	normal := data.Monster{Name: npc.Zombie, Type: data.MonsterTypeNone}
+	elite := data.Monster{Name: npc.Zombie, Type: data.MonsterTypeChampion}
+	sealElite := data.Monster{Name: npc.VenomLord, Type: data.MonsterTypeSuperUnique}
+	aura := data.Monster{Name: npc.Zombie, Type: data.MonsterTypeUnique, States: state.States{state.Conviction}}
+	coldImmune := data.Monster{Name: npc.Zombie, Stats: map[stat.ID]int{stat.ColdResist: 100}}
+	ranged := data.Monster{Name: npc.SkeletonArcher}
+	sorceress := classDamageTypes["sorceress"]
+
+	if threat := MonsterThreat(normal, sorceress); threat != 0 {
+		t.Errorf("Expected no threat from a normal monster, got %d", threat)
+	}
+	if MonsterThreat(elite, sorceress) == 0 {
+		t.Errorf("Expected elites to be a threat")
+	}
+	if MonsterThreat(sealElite, sorceress) <= MonsterThreat(elite, sorceress) {
+		t.Errorf("Expected seal elites to be a bigger threat than other elites")
+	}
+	if MonsterThreat(aura, sorceress) <= MonsterThreat(elite, sorceress) {
+		t.Errorf("Expected aura enchanted elites to be a bigger threat than other elites")
+	}
+	if MonsterThreat(coldImmune, sorceress) == 0 {
+		t.Errorf("Expected cold immunes to be a threat for a cold sorceress")
+	}
+	if threat := MonsterThreat(coldImmune, classDamageTypes["lightsorc"]); threat != 0 {
+		t.Errorf("Expected cold immunes to be harmless for a lightning sorceress, got %d", threat)
+	}
+	if threat := MonsterThreat(coldImmune, nil); threat != 0 {
+		t.Errorf("Expected no immunity threat for physical builds, got %d", threat)
+	}
+	if MonsterThreat(ranged, sorceress) == 0 {
+		t.Errorf("Expected ranged monsters to be a threat")
+	}
+}
+
+func TestDangerMap(t *testing.T) {
+	grid := game.NewGrid(openCollisionGrid(60, 40), 100, 200)
+
+	harmless := []data.Monster{{Name: npc.Zombie, Position: data.Position{X: 130, Y: 220}}}
+	if dm := NewDangerMap(grid, harmless, nil); dm != nil {
+		t.Fatalf("Expected no danger map without threats")
+	}
+
+	elite := data.Monster{Name: npc.Zombie, Type: data.MonsterTypeUnique, Position: data.Position{X: 130, Y: 220}}
+	dm := NewDangerMap(grid, []data.Monster{elite}, nil)
+	center := grid.RelativePosition(elite.Position)
+
+	previous := math.MaxInt
+	for d := 0; d < dangerRadius; d++ {
+		danger := dm.Danger(data.Position{X: center.X + d, Y: center.Y})
+		if danger <= 0 || danger >= previous {
+			t.Errorf("Expected the danger to decrease with the distance, got %d at %d tiles", danger, d)
+		}
+		previous = danger
+	}
+	if danger := dm.Danger(data.Position{X: center.X + dangerRadius, Y: center.Y}); danger != 0 {
+		t.Errorf("Expected no danger outside the radius, got %d", danger)
+	}
+
+	// A big pack on the same tile doesn't get over the max tile danger
+	pack := make([]data.Monster, 20)
+	for i := range pack {
+		pack[i] = elite
+	}
+	if danger := NewDangerMap(grid, pack, nil).Danger(center); danger != maxTileDanger {
+		t.Errorf("Expected the danger to be capped at %d, got %d", maxTileDanger, danger)
+	}
+}
+
+func TestSafePathAroundDangerousPack(t *testing.T) {
+	grid := game.NewGrid(openCollisionGrid(80, 50), 0, 0)
+	start, goal := data.Position{X: 5, Y: 25}, data.Position{X: 75, Y: 25}
+	pack := []data.Monster{
+		{Name: npc.Zombie, Type: data.MonsterTypeUnique, Position: data.Position{X: 40, Y: 25}},
+		{Name: npc.Zombie, Type: data.MonsterTypeMinion, Position: data.Position{X: 41, Y: 24}},
+		{Name: npc.Zombie, Type: data.MonsterTypeMinion, Position: data.Position{X: 39, Y: 26}},
+	}
+
+	path, _, found := astar.CalculatePath(grid, start, goal)
+	if !found || minDistance(path, pack[0].Position) > 2 {
+		t.Fatalf("Expected the plain path to go through the pack")
+	}
+
+	dm := NewDangerMap(grid, pack, nil)
+	path, _, found = astar.CalculatePathWithCosts(grid, dm, start, goal)
+	if !found {
+		t.Fatalf("Expected a safe path")
+	}
+	if d := minDistance(path, pack[0].Position); d < dangerRadius-2 {
+		t.Errorf("Expected the safe path to keep away from the pack, it gets %.1f tiles close", d)
+	}
+	if path[0] != start || path[len(path)-1] != goal {
+		t.Errorf("Expected the safe path to go from %v to %v", start, goal)
+	}
+}
+
+func TestSafePathThroughDangerousPack(t *testing.T) {
+	// A corridor blocked by the pack, going through it is the only way
+	cg := openCollisionGrid(80, 50)
+	for y := range cg {
+		for x := range cg[y] {
+			if y < 22 || y > 28 {
+				cg[y][x] = game.CollisionTypeNonWalkable
+			}
+		}
+	}
+	grid := game.NewGrid(cg, 0, 0)
+	start, goal := data.Position{X: 5, Y: 25}, data.Position{X: 75, Y: 25}
+	pack := []data.Monster{{Name: npc.VenomLord, Type: data.MonsterTypeSuperUnique, Position: data.Position{X: 40, Y: 25}}}
+
+	if _, _, found := astar.CalculatePathWithCosts(grid, NewDangerMap(grid, pack, nil), start, goal); !found {
+		t.Errorf("Expected a path through the pack when there is no other way")
+	}
+}
+
+func TestClassDamageTypes(t *testing.T) {
+	// The classes are read from the source of BuildCharacter, character can't be imported from pather
+	file, err := parser.ParseFile(token.NewFileSet(), "../character/character.go", nil, 0)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	classes := 0
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Name.Name != "BuildCharacter" {
+			continue
+		}
+		ast.Inspect(fn, func(n ast.Node) bool {
+			clause, ok := n.(*ast.CaseClause)
+			if !ok {
+				return true
+			}
+			for _, expr := range clause.List {
+				lit, ok := expr.(*ast.BasicLit)
+				if !ok || lit.Kind != token.STRING {
+					continue
+				}
+				class, _ := strconv.Unquote(lit.Value)
+				classes++
+				if _, found := classDamageTypes[class]; !found {
+					t.Errorf("Expected damage types for class %s", class)
+				}
+			}
+			return true
+		})
+	}
+	if classes == 0 {
+		t.Errorf("Expected the classes of BuildCharacter, got none")
+	}
+}
+
+func openCollisionGrid(width, height int) [][]game.CollisionType {
+	cg := make([][]game.CollisionType, height)
+	for y := range cg {
+		cg[y] = make([]game.CollisionType, width)
+		for x := range cg[y] {
+			cg[y][x] = game.CollisionTypeWalkable
+		}
+	}
+
+	return cg
+}
+
+func minDistance(path []data.Position, p data.Position) float64 {
+	d := math.MaxFloat64
+	for _, n := range path {
+		dx, dy := float64(n.X-p.X), float64(n.Y-p.Y)
+		d = min(d, math.Sqrt(dx*dx+dy*dy))
+	}
+
+	return d
+}
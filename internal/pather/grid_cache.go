@@ -0,0 +1,88 @@
+package pather
+
+import (
+	"sync"
+
+	"github.com/hectorgimenez/d2go/pkg/data/area"
+	"github.com/hectorgimenez/koolo/internal/game"
+	"github.com/hectorgimenez/koolo/internal/pather/astar"
+)
+
+// Grids with fewer tiles than this are searched with plain A*, the cluster graph is not worth it
+const minHierarchicalGridTiles = 200 * 200
+
+type gridKey struct {
+	origin      area.ID
+	destination area.ID // same as origin for single area grids
+}
+
+// gridCache keeps the merged grids and the cluster graphs (HPA*) of the grids used for pathing, so they are only built
+// once per area. The entries are rebuilt when the area grids change, on every new game.
+type gridCache struct {
+	mu      sync.Mutex
+	entries map[gridKey]*gridEntry
+}
+
+type gridEntry struct {
+	sources     [2]*game.Grid // area grids used to build the entry
+	grid        *game.Grid
+	abstraction *astar.Abstraction // nil until the background build finishes
+	building    bool
+}
+
+func newGridCache() *gridCache {
+	return &gridCache{entries: make(map[gridKey]*gridEntry)}
+}
+
+// grid returns the cached grid built from the sources, build is only called when there is no entry for them.
+func (c *gridCache) grid(key gridKey, sources [2]*game.Grid, build func() (*game.Grid, error)) (*game.Grid, error) {
+	c.mu.Lock()
+	if e, found := c.entries[key]; found && e.sources == sources {
+		c.mu.Unlock()
+		return e.grid, nil
+	}
+	c.mu.Unlock()
+
+	grid, err := build()
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = &gridEntry{sources: sources, grid: grid}
+
+	return grid, nil
+}
+
+// abstraction returns the cluster graph of the grid, nil for small grids or while it's being built. The first call for
+// a grid starts building it in the background, the paths use plain A* in the meantime.
+func (c *gridCache) abstraction(key gridKey, sources [2]*game.Grid, grid *game.Grid) *astar.Abstraction {
+	if grid.Width*grid.Height < minHierarchicalGridTiles {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, found := c.entries[key]
+	if !found || e.sources != sources || e.grid != grid {
+		e = &gridEntry{sources: sources, grid: grid}
+		c.entries[key] = e
+	}
+	if e.abstraction != nil || e.building {
+		return e.abstraction
+	}
+
+	e.building = true
+	go func() {
+		a := astar.NewAbstraction(grid, astar.DefaultClusterSize)
+
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		e.abstraction = a
+		e.building = false
+	}()
+
+	return nil
+}
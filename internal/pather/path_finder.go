@@ -3,41 +3,63 @@ package pather
 import (
 	"fmt"
 	"math"
+	"slices"
+	"sync"
 
 	"github.com/hectorgimenez/d2go/pkg/data"
 	"github.com/hectorgimenez/d2go/pkg/data/area"
 	"github.com/hectorgimenez/d2go/pkg/data/npc"
+	"github.com/hectorgimenez/d2go/pkg/data/stat"
 	"github.com/hectorgimenez/koolo/internal/config"
 	"github.com/hectorgimenez/koolo/internal/game"
 	"github.com/hectorgimenez/koolo/internal/pather/astar"
 )
 
 type PathFinder struct {
-	gr   *game.MemoryReader
-	data *game.Data
-	hid  *game.HID
-	cfg  *config.CharacterCfg
+	gr    game.StateReader
+	data  *game.Data
+	hid   game.InputSender
+	cfg   *config.CharacterCfg
+	grids *gridCache
+
+	teleportMu       sync.Mutex
+	teleportPlanner  *TeleportPlanner
+	teleportAreaSize [2]int
+
+	lastPathMu sync.Mutex
+	lastPath   []data.Position // absolute positions
 }
 
-func NewPathFinder(gr *game.MemoryReader, data *game.Data, hid *game.HID, cfg *config.CharacterCfg) *PathFinder {
+func NewPathFinder(gr game.StateReader, data *game.Data, hid game.InputSender, cfg *config.CharacterCfg) *PathFinder {
 	return &PathFinder{
-		gr:   gr,
-		data: data,
-		hid:  hid,
-		cfg:  cfg,
+		gr:    gr,
+		data:  data,
+		hid:   hid,
+		cfg:   cfg,
+		grids: newGridCache(),
 	}
 }
 
 func (pf *PathFinder) GetPath(to data.Position) (Path, int, bool) {
+	return pf.getPath(to, false)
+}
+
+// GetSafePath is GetPath going around the dangerous monsters (elites, aura enchanted, immunes, ranged) when there is
+// another way, for characters walking through the monsters instead of clearing them.
+func (pf *PathFinder) GetSafePath(to data.Position) (Path, int, bool) {
+	return pf.getPath(to, true)
+}
+
+func (pf *PathFinder) getPath(to data.Position, avoidDanger bool) (Path, int, bool) {
 	// First try direct path
-	if path, distance, found := pf.GetPathFrom(pf.data.PlayerUnit.Position, to); found {
+	if path, distance, found := pf.getPathFrom(pf.data.PlayerUnit.Position, to, avoidDanger); found {
 		return path, distance, true
 	}
 
 	walkableTo, foundTo := pf.findNearbyWalkablePosition(to)
 	// If direct path fails, try to find nearby to walkable position
 	if foundTo {
-		path, distance, found := pf.GetPathFrom(pf.data.PlayerUnit.Position, walkableTo)
+		path, distance, found := pf.getPathFrom(pf.data.PlayerUnit.Position, walkableTo, avoidDanger)
 		if found {
 			return path, distance, true
 		}
@@ -48,13 +70,23 @@ func (pf *PathFinder) GetPath(to data.Position) (Path, int, bool) {
 }
 
 func (pf *PathFinder) GetPathFrom(from, to data.Position) (Path, int, bool) {
+	return pf.getPathFrom(from, to, false)
+}
+
+func (pf *PathFinder) getPathFrom(from, to data.Position, avoidDanger bool) (Path, int, bool) {
 	a := pf.data.AreaData
 
 	// We don't want to modify the original grid
 	grid := a.Grid.Copy()
+	key := gridKey{origin: a.Area, destination: a.Area}
+	sources := [2]*game.Grid{a.Grid}
+	static := a.Grid
+	// The cluster graph is built from the walkable tiles, it can't be used when the grid is changed to teleport
+	useAbstraction := true
 
 	// Special handling for Arcane Sanctuary (to allow pathing with platforms)
 	if pf.data.PlayerUnit.Area == area.ArcaneSanctuary && pf.data.CanTeleport() {
+		useAbstraction = false
 		// Make all non-walkable tiles into low priority tiles for teleport pathing
 		for y := 0; y < len(grid.CollisionGrid); y++ {
 			for x := 0; x < len(grid.CollisionGrid[y]); x++ {
@@ -70,11 +102,14 @@ func (pf *PathFinder) GetPathFrom(from, to data.Position) (Path, int, bool) {
 	}
 
 	if !a.IsInside(to) {
-		expandedGrid, err := pf.mergeGrids(to)
+		var err error
+		key, sources, static, err = pf.areaGrid(to)
 		if err != nil {
 			return nil, 0, false
 		}
-		grid = expandedGrid
+		// The merged grid is cached, it's copied like the area one
+		grid = static.Copy()
+		useAbstraction = true
 	}
 
 	from = grid.RelativePosition(from)
@@ -143,7 +178,22 @@ func (pf *PathFinder) GetPathFrom(from, to data.Position) (Path, int, bool) {
 			}
 		}
 	}
-	path, distance, found := astar.CalculatePath(grid, from, to)
+	calculatePath := astar.CalculatePath
+	if avoidDanger {
+		// The cluster graph doesn't know about the danger, the whole grid is searched
+		if danger := pf.dangerMap(grid); danger != nil {
+			useAbstraction = false
+			calculatePath = func(g *game.Grid, start, goal data.Position) ([]data.Position, int, bool) {
+				return astar.CalculatePathWithCosts(g, danger, start, goal)
+			}
+		}
+	}
+	if useAbstraction {
+		if abstraction := pf.grids.abstraction(key, sources, static); abstraction != nil {
+			calculatePath = abstraction.CalculatePath
+		}
+	}
+	path, distance, found := calculatePath(grid, from, to)
 
 	if config.Koolo.Debug.RenderMap {
 		pf.renderMap(grid, from, to, path)
@@ -152,6 +202,75 @@ func (pf *PathFinder) GetPathFrom(from, to data.Position) (Path, int, bool) {
 	return path, distance, found
 }
 
+// LastPath returns the last path followed with MoveThroughPath, with absolute positions. It's read by the debug map
+// renderer while the bot is running.
+func (pf *PathFinder) LastPath() []data.Position {
+	pf.lastPathMu.Lock()
+	defer pf.lastPathMu.Unlock()
+
+	return slices.Clone(pf.lastPath)
+}
+
+func (pf *PathFinder) setLastPath(p Path) {
+	var path []data.Position
+	if len(p) > 0 {
+		// The path starts at the player position, relative to the grid it was calculated on
+		originX, originY := pf.data.PlayerUnit.Position.X-p.From().X, pf.data.PlayerUnit.Position.Y-p.From().Y
+		path = make([]data.Position, 0, len(p))
+		for _, pos := range p {
+			path = append(path, data.Position{X: pos.X + originX, Y: pos.Y + originY})
+		}
+	}
+
+	pf.lastPathMu.Lock()
+	defer pf.lastPathMu.Unlock()
+	pf.lastPath = path
+}
+
+// dangerMap returns the danger map of the grid with the current monsters, nil when none of them is a threat
+func (pf *PathFinder) dangerMap(grid *game.Grid) DangerMap {
+	var damageTypes []stat.Resist
+	if pf.cfg != nil {
+		damageTypes = classDamageTypes[pf.cfg.Character.Class]
+	}
+
+	return NewDangerMap(grid, pf.data.Monsters.Enemies(), damageTypes)
+}
+
+// areaGrid returns the grid with the current area and the position, merged with the adjacent area when the position is
+// outside the current one. The key and the sources identify the grid in the cache.
+func (pf *PathFinder) areaGrid(to data.Position) (gridKey, [2]*game.Grid, *game.Grid, error) {
+	a := pf.data.AreaData
+	key := gridKey{origin: a.Area, destination: a.Area}
+	sources := [2]*game.Grid{a.Grid}
+	if a.IsInside(to) {
+		return key, sources, a.Grid, nil
+	}
+
+	destination, found := pf.destinationArea(to)
+	if !found {
+		return key, sources, nil, fmt.Errorf("destination grid not found")
+	}
+	key.destination = destination.Area
+	sources[1] = destination.Grid
+	grid, err := pf.grids.grid(key, sources, func() (*game.Grid, error) {
+		return pf.mergeGrids(to)
+	})
+
+	return key, sources, grid, err
+}
+
+// destinationArea returns the adjacent area containing the position
+func (pf *PathFinder) destinationArea(to data.Position) (game.AreaData, bool) {
+	for _, a := range pf.data.AreaData.AdjacentLevels {
+		if destination := pf.data.Areas[a.Area]; destination.IsInside(to) {
+			return destination, true
+		}
+	}
+
+	return game.AreaData{}, false
+}
+
 func (pf *PathFinder) mergeGrids(to data.Position) (*game.Grid, error) {
 	for _, a := range pf.data.AreaData.AdjacentLevels {
 		destination := pf.data.Areas[a.Area]
@@ -0,0 +1,71 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+)
+
+// Image draws the scene on an image, a square of Scale pixels per tile.
+func Image(s Scene, opts Options) (*image.RGBA, error) {
+	v, err := newView(s, opts)
+	if err != nil {
+		return nil, err
+	}
+	img := image.NewRGBA(image.Rect(0, 0, v.width*v.scale, v.height*v.scale))
+
+	fill := func(x, y, size int, c color.RGBA) {
+		draw.Draw(img, image.Rect(x, y, x+size, y+size), &image.Uniform{C: c}, image.Point{}, draw.Src)
+	}
+
+	for y := 0; y < v.height; y++ {
+		for x := 0; x < v.width; x++ {
+			fill(x*v.scale, y*v.scale, v.scale, tileColor(s.Grid.CollisionGrid[v.minY+y][v.minX+x]))
+		}
+	}
+
+	// Visited rooms only tint the walkable tiles, the walls are kept
+	for _, r := range s.VisitedRooms {
+		for y := r.Y; y < r.Y+r.Height; y++ {
+			for x := r.X; x < r.X+r.Width; x++ {
+				px, py, inside := v.pixel(s.Grid, data.Position{X: x, Y: y})
+				if inside && img.RGBAAt(px, py) == colorWalkable {
+					fill(px, py, v.scale, colorVisited)
+				}
+			}
+		}
+	}
+
+	for _, p := range s.Path {
+		if px, py, inside := v.pixel(s.Grid, p); inside {
+			fill(px, py, v.scale, colorPath)
+		}
+	}
+
+	for _, m := range s.markers() {
+		half := m.size / 2
+		for dy := -half; dy <= half; dy++ {
+			for dx := -half; dx <= half; dx++ {
+				if px, py, inside := v.pixel(s.Grid, data.Position{X: m.position.X + dx, Y: m.position.Y + dy}); inside {
+					fill(px, py, v.scale, m.color)
+				}
+			}
+		}
+	}
+
+	return img, nil
+}
+
+// PNG writes the scene as a PNG image.
+func PNG(w io.Writer, s Scene, opts Options) error {
+	img, err := Image(s, opts)
+	if err != nil {
+		return err
+	}
+
+	return png.Encode(w, img)
+}
@@ -0,0 +1,254 @@
+// Package render draws an area grid with the units on it and the path being followed, to PNG or SVG. It works on the
+// live game data and on recordings, so stuck reports can come with a picture of the situation.
+package render
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"strconv"
+	"strings"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/item"
+	"github.com/hectorgimenez/koolo/internal/config"
+	"github.com/hectorgimenez/koolo/internal/game"
+	"github.com/hectorgimenez/koolo/internal/pather/astar"
+)
+
+const (
+	FormatPNG = "png"
+	FormatSVG = "svg"
+
+	// MaxScale is the biggest tile size in pixels
+	MaxScale = 16
+	// maxPixels limits the size of the picture, a PNG this big already takes 128MB of memory while drawn
+	maxPixels = 32 << 20
+)
+
+// ErrTooLarge is returned when the picture would take more than the pixels allowed, use a smaller scale or a radius.
+var ErrTooLarge = errors.New("the picture is too large")
+
+var (
+	colorNonWalkable = color.RGBA{A: 255}
+	colorWalkable    = color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	colorLowPriority = color.RGBA{R: 200, G: 200, B: 200, A: 255} // Gray
+	colorVisited     = color.RGBA{R: 190, G: 220, B: 255, A: 255} // Light blue
+	colorPath        = color.RGBA{R: 36, G: 255, A: 255}          // Green
+	colorRoomCenter  = color.RGBA{R: 204, G: 204, A: 255}         // Dark yellow
+	colorObject      = color.RGBA{R: 160, G: 32, B: 240, A: 255}  // Purple
+	colorItem        = color.RGBA{R: 255, G: 140, A: 255}         // Orange
+	colorMonster     = color.RGBA{R: 255, A: 255}                 // Red
+	colorElite       = color.RGBA{R: 255, B: 200, A: 255}         // Magenta
+	colorExit        = color.RGBA{G: 200, B: 200, A: 255}         // Cyan
+	colorPlayer      = color.RGBA{R: 158, A: 255}                 // Garnet
+	colorDestination = color.RGBA{B: 255, A: 255}                 // Blue
+)
+
+// Scene is everything drawn on the picture, positions are absolute (game coordinates) like in the game data.
+type Scene struct {
+	Grid         *game.Grid
+	Player       data.Position
+	Destination  *data.Position
+	Monsters     []data.Monster
+	Items        []data.Item
+	Objects      []data.Object
+	Exits        []data.Level
+	Rooms        []data.Room
+	VisitedRooms []data.Room
+	Path         []data.Position
+}
+
+// Options of the picture, the zero value draws the whole grid with a pixel per tile.
+type Options struct {
+	// Scale is the size of a tile in pixels, from 1 to MaxScale, 0 is a pixel per tile
+	Scale int
+	// Radius only draws the tiles up to this distance from the player, 0 draws the whole grid
+	Radius int
+}
+
+// FromData builds the scene of the current area, with the ground items.
+func FromData(d game.Data) (Scene, error) {
+	if d.AreaData.Grid == nil {
+		return Scene{}, errors.New("the current area has no grid")
+	}
+
+	return Scene{
+		Grid:     d.AreaData.Grid,
+		Player:   d.PlayerUnit.Position,
+		Monsters: d.Monsters,
+		Items:    d.Inventory.ByLocation(item.LocationGround),
+		Objects:  d.AreaData.Objects,
+		Exits:    d.AreaData.AdjacentLevels,
+		Rooms:    d.AreaData.Rooms,
+	}, nil
+}
+
+// FromRecording builds the scene of a recording frame, negative frames count from the end (-1 is the last one). The
+// visited rooms are the ones of the area the player has been in, from the start of the recording to the frame.
+func FromRecording(rec *game.Recording, frame int) (Scene, error) {
+	if frame < 0 {
+		frame += len(rec.Frames)
+	}
+	if frame < 0 || frame >= len(rec.Frames) {
+		return Scene{}, fmt.Errorf("frame %d not found, the recording has %d frames", frame, len(rec.Frames))
+	}
+
+	d := rec.Data(frame, config.CharacterCfg{})
+	s, err := FromData(d)
+	if err != nil {
+		return Scene{}, err
+	}
+
+	visited := make(map[data.Room]bool)
+	for _, f := range rec.Frames[:frame+1] {
+		if f.Data.PlayerUnit.Area != d.PlayerUnit.Area {
+			continue
+		}
+		for _, r := range s.Rooms {
+			if !visited[r] && r.IsInside(f.Data.PlayerUnit.Position) {
+				visited[r] = true
+				s.VisitedRooms = append(s.VisitedRooms, r)
+			}
+		}
+	}
+
+	return s, nil
+}
+
+// PlanPath sets the destination and the path the bot would walk to it from the player position, monsters are soft
+// obstacles like for the pathfinder. It returns false when there is no path.
+func (s *Scene) PlanPath(to data.Position) bool {
+	s.Destination = &to
+	s.Path = nil
+
+	grid := s.Grid.Copy()
+	for _, m := range s.Monsters {
+		if grid.IsWalkable(m.Position) {
+			p := grid.RelativePosition(m.Position)
+			grid.CollisionGrid[p.Y][p.X] = game.CollisionTypeMonster
+		}
+	}
+
+	from, dest := grid.RelativePosition(s.Player), grid.RelativePosition(to)
+	if !s.inside(from) || !s.inside(dest) {
+		return false
+	}
+	path, _, found := astar.CalculatePath(grid, from, dest)
+	if !found {
+		return false
+	}
+	for _, p := range path {
+		s.Path = append(s.Path, data.Position{X: p.X + grid.OffsetX, Y: p.Y + grid.OffsetY})
+	}
+
+	return true
+}
+
+// ParsePosition parses a position written as x,y, like the path destinations of the map commands.
+func ParsePosition(s string) (data.Position, error) {
+	x, y, found := strings.Cut(s, ",")
+	px, errX := strconv.Atoi(strings.TrimSpace(x))
+	py, errY := strconv.Atoi(strings.TrimSpace(y))
+	if !found || errX != nil || errY != nil {
+		return data.Position{}, fmt.Errorf("invalid position %q, expected x,y", s)
+	}
+
+	return data.Position{X: px, Y: py}, nil
+}
+
+// inside checks a position relative to the grid
+func (s *Scene) inside(p data.Position) bool {
+	return p.X >= 0 && p.Y >= 0 && p.X < s.Grid.Width && p.Y < s.Grid.Height
+}
+
+// view is the part of the grid drawn, relative to the grid
+type view struct {
+	minX, minY    int
+	width, height int
+	scale         int
+}
+
+func newView(s Scene, opts Options) (view, error) {
+	if opts.Scale < 0 || opts.Scale > MaxScale {
+		return view{}, fmt.Errorf("scale %d out of range, it goes from 1 to %d", opts.Scale, MaxScale)
+	}
+
+	v := view{width: s.Grid.Width, height: s.Grid.Height, scale: max(1, opts.Scale)}
+	if opts.Radius > 0 {
+		p := s.Grid.RelativePosition(s.Player)
+		v.minX, v.minY = max(0, p.X-opts.Radius), max(0, p.Y-opts.Radius)
+		v.width = min(s.Grid.Width, p.X+opts.Radius+1) - v.minX
+		v.height = min(s.Grid.Height, p.Y+opts.Radius+1) - v.minY
+	}
+
+	if pixels := v.width * v.height * v.scale * v.scale; pixels > maxPixels {
+		return view{}, fmt.Errorf("%w: %dx%d tiles at scale %d are %d pixels, the maximum is %d", ErrTooLarge, v.width, v.height, v.scale, pixels, maxPixels)
+	}
+
+	return v, nil
+}
+
+// pixel returns the top left pixel of the tile at the absolute position and false when it's outside the view
+func (v view) pixel(g *game.Grid, p data.Position) (int, int, bool) {
+	rel := g.RelativePosition(p)
+	x, y := rel.X-v.minX, rel.Y-v.minY
+	if x < 0 || y < 0 || x >= v.width || y >= v.height {
+		return 0, 0, false
+	}
+
+	return x * v.scale, y * v.scale, true
+}
+
+// marker is a unit drawn on top of the grid, bigger than a tile so it can be seen on the smallest scales
+type marker struct {
+	position data.Position
+	color    color.RGBA
+	size     int // in tiles
+	label    string
+}
+
+// markers returns the units of the scene, drawn in order so the player is always on top
+func (s Scene) markers() []marker {
+	var markers []marker
+	for _, r := range s.Rooms {
+		markers = append(markers, marker{position: r.GetCenter(), color: colorRoomCenter, size: 1})
+	}
+	for _, o := range s.Objects {
+		markers = append(markers, marker{position: o.Position, color: colorObject, size: 3, label: o.Desc().Name})
+	}
+	for _, i := range s.Items {
+		markers = append(markers, marker{position: i.Position, color: colorItem, size: 3, label: string(i.Name)})
+	}
+	for _, m := range s.Monsters {
+		if m.IsElite() {
+			markers = append(markers, marker{position: m.Position, color: colorElite, size: 5, label: fmt.Sprintf("%d (%s)", m.Name, m.Type)})
+		} else {
+			markers = append(markers, marker{position: m.Position, color: colorMonster, size: 3, label: fmt.Sprintf("%d", m.Name)})
+		}
+	}
+	for _, e := range s.Exits {
+		markers = append(markers, marker{position: e.Position, color: colorExit, size: 7, label: e.Area.Area().Name})
+	}
+	if s.Destination != nil {
+		markers = append(markers, marker{position: *s.Destination, color: colorDestination, size: 5, label: "destination"})
+	}
+	markers = append(markers, marker{position: s.Player, color: colorPlayer, size: 5, label: "player"})
+
+	return markers
+}
+
+func tileColor(t game.CollisionType) color.RGBA {
+	switch t {
+	case game.CollisionTypeNonWalkable:
+		return colorNonWalkable
+	case game.CollisionTypeLowPriority:
+		return colorLowPriority
+	case game.CollisionTypeMonster:
+		return colorMonster
+	case game.CollisionTypeObject:
+		return colorObject
+	default:
+		return colorWalkable
+	}
+}
@@ -0,0 +1,185 @@
+package render
+
+import (
+	"bytes"
+	"errors"
+	"image/png"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/area"
+	"github.com/hectorgimenez/d2go/pkg/data/item"
+	"github.com/hectorgimenez/d2go/pkg/data/npc"
+	"github.com/hectorgimenez/koolo/internal/game"
+)
+
+func testScene() Scene {
+	cg := make([][]game.CollisionType, 40)
+	for y := range cg {
+		cg[y] = make([]game.CollisionType, 60)
+		for x := range cg[y] {
+			cg[y][x] = game.CollisionTypeWalkable
+			if x == 30 && y < 30 {
+				cg[y][x] = game.CollisionTypeNonWalkable
+			}
+		}
+	}
+
+	return Scene{
+		Grid:     &game.Grid{OffsetX: 1000, OffsetY: 2000, Width: 60, Height: 40, CollisionGrid: cg},
+		Player:   data.Position{X: 1010, Y: 2010},
+		Monsters: []data.Monster{{Name: npc.Zombie, Position: data.Position{X: 1020, Y: 2020}}, {Name: npc.Zombie, Type: data.MonsterTypeUnique, Position: data.Position{X: 1040, Y: 2005}}},
+		Items:    []data.Item{{Name: "Ring", Position: data.Position{X: 1012, Y: 2030}}},
+		Exits:    []data.Level{{Area: area.ColdPlains, Position: data.Position{X: 1055, Y: 2035}}},
+		Rooms:    []data.Room{{Position: data.Position{X: 1000, Y: 2000}, Width: 20, Height: 20}, {Position: data.Position{X: 1020, Y: 2000}, Width: 20, Height: 20}},
+	}
+}
+
+func TestPNG(t *testing.T) {
+	s := testScene()
+	s.VisitedRooms = s.Rooms[:1]
+	if !s.PlanPath(data.Position{X: 1050, Y: 2010}) {
+		t.Fatalf("Expected a path around the wall")
+	}
+
+	var buf bytes.Buffer
+	if err := PNG(&buf, s, Options{Scale: 2}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != 120 || b.Dy() != 80 {
+		t.Fatalf("Expected a 120x80 image, got %v", b)
+	}
+
+	rgba, err := Image(s, Options{Scale: 2})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	checks := []struct {
+		name string
+		x, y int // tile relative to the grid
+		want any
+	}{
+		{"player", 10, 10, colorPlayer},
+		{"elite", 40, 5, colorElite},
+		{"monster", 20, 20, colorMonster},
+		{"item", 12, 30, colorItem},
+		{"exit", 55, 35, colorExit},
+		{"wall", 30, 25, colorNonWalkable},
+		{"path around the wall", 30, 31, colorPath},
+		{"visited room", 3, 15, colorVisited},
+		{"not visited room", 25, 15, colorWalkable},
+	}
+	for _, c := range checks {
+		if got := rgba.RGBAAt(c.x*2, c.y*2); got != c.want {
+			t.Errorf("Expected the %s color at %d,%d, got %v", c.name, c.x, c.y, got)
+		}
+	}
+}
+
+func TestRadius(t *testing.T) {
+	img, err := Image(testScene(), Options{Scale: 1, Radius: 5})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != 11 || b.Dy() != 11 {
+		t.Fatalf("Expected an 11x11 image around the player, got %v", b)
+	}
+	if got := img.RGBAAt(5, 5); got != colorPlayer {
+		t.Errorf("Expected the player at the center, got %v", got)
+	}
+}
+
+func TestSizeLimit(t *testing.T) {
+	s := testScene()
+	for _, scale := range []int{-1, MaxScale + 1} {
+		if err := SVG(io.Discard, s, Options{Scale: scale}); err == nil {
+			t.Errorf("Expected an error for scale %d", scale)
+		}
+	}
+
+	// A big area at the biggest scale is too many pixels, a radius around the player fits
+	cg := make([][]game.CollisionType, 1000)
+	for y := range cg {
+		cg[y] = make([]game.CollisionType, 1000)
+	}
+	s.Grid = &game.Grid{OffsetX: 1000, OffsetY: 2000, Width: 1000, Height: 1000, CollisionGrid: cg}
+	if _, err := Image(s, Options{Scale: MaxScale}); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("Expected ErrTooLarge, got %v", err)
+	}
+	if err := PNG(io.Discard, s, Options{Scale: MaxScale}); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("Expected ErrTooLarge, got %v", err)
+	}
+	if _, err := Image(s, Options{Scale: MaxScale, Radius: 100}); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestSVG(t *testing.T) {
+	s := testScene()
+	s.VisitedRooms = s.Rooms[:1]
+	s.PlanPath(data.Position{X: 1050, Y: 2010})
+
+	var buf bytes.Buffer
+	if err := SVG(&buf, s, Options{Scale: 3}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		`width="180" height="120"`,
+		`<rect x="90" y="0" width="3" height="3" fill="#000000"/>`, // first wall tile
+		`class="path"`,
+		`class="visited"`,
+		`<title>player (1010, 2010)</title>`,
+		`<title>Cold Plains (1055, 2035)</title>`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %s in the SVG", want)
+		}
+	}
+}
+
+func TestFromRecording(t *testing.T) {
+	s := testScene()
+	areas := map[area.ID]game.AreaData{area.BloodMoor: {Area: area.BloodMoor, Name: "Blood Moor", Rooms: s.Rooms, Grid: s.Grid}}
+	frame := func(x, y int) game.Data {
+		d := game.Data{Areas: areas}
+		d.IsIngame = true
+		d.PlayerUnit.Area = area.BloodMoor
+		d.PlayerUnit.Position = data.Position{X: x, Y: y}
+		d.Inventory.AllItems = []data.Item{{Name: "Ring", Location: item.Location{LocationType: item.LocationGround}}}
+		return d
+	}
+
+	r := game.NewRecorder(t.TempDir(), "sorc")
+	for _, d := range []game.Data{frame(1005, 2005), frame(1010, 2010), frame(1025, 2005)} {
+		if err := r.Record(d, 1); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	file := r.File()
+	r.Close()
+	rec, err := game.LoadRecording(file)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	scene, err := FromRecording(rec, 1)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if scene.Player != (data.Position{X: 1010, Y: 2010}) || len(scene.VisitedRooms) != 1 || len(scene.Items) != 1 {
+		t.Errorf("Unexpected scene of the second frame: player %v, %d visited rooms, %d items", scene.Player, len(scene.VisitedRooms), len(scene.Items))
+	}
+	if scene, _ = FromRecording(rec, -1); len(scene.VisitedRooms) != 2 {
+		t.Errorf("Expected both rooms visited at the last frame, got %d", len(scene.VisitedRooms))
+	}
+	if _, err = FromRecording(rec, 3); err == nil {
+		t.Errorf("Expected an error for a frame out of the recording")
+	}
+}
@@ -0,0 +1,78 @@
+package render
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"image/color"
+	"io"
+	"strings"
+)
+
+// SVG writes the scene as an SVG image. The walls are rectangles merged by rows and every unit has its name as tooltip,
+// so the picture can be zoomed in a browser without losing detail.
+func SVG(w io.Writer, s Scene, opts Options) error {
+	v, err := newView(s, opts)
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintf(bw, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`+"\n",
+		v.width*v.scale, v.height*v.scale, v.width*v.scale, v.height*v.scale)
+	fmt.Fprintf(bw, `<rect width="100%%" height="100%%" fill="%s"/>`+"\n", hexColor(colorWalkable))
+
+	for _, r := range s.VisitedRooms {
+		x, y := r.X-s.Grid.OffsetX-v.minX, r.Y-s.Grid.OffsetY-v.minY
+		fmt.Fprintf(bw, `<rect class="visited" x="%d" y="%d" width="%d" height="%d" fill="%s"/>`+"\n",
+			x*v.scale, y*v.scale, r.Width*v.scale, r.Height*v.scale, hexColor(colorVisited))
+	}
+
+	// Tiles of the same type next to each other in a row are a single rectangle
+	for y := 0; y < v.height; y++ {
+		row := s.Grid.CollisionGrid[v.minY+y][v.minX : v.minX+v.width]
+		for start := 0; start < len(row); {
+			end := start + 1
+			for end < len(row) && row[end] == row[start] {
+				end++
+			}
+			if c := tileColor(row[start]); c != colorWalkable {
+				fmt.Fprintf(bw, `<rect x="%d" y="%d" width="%d" height="%d" fill="%s"/>`+"\n",
+					start*v.scale, y*v.scale, (end-start)*v.scale, v.scale, hexColor(c))
+			}
+			start = end
+		}
+	}
+
+	if len(s.Path) > 0 {
+		points := make([]string, 0, len(s.Path))
+		for _, p := range s.Path {
+			rel := s.Grid.RelativePosition(p)
+			points = append(points, fmt.Sprintf("%.1f,%.1f", (float64(rel.X-v.minX)+0.5)*float64(v.scale), (float64(rel.Y-v.minY)+0.5)*float64(v.scale)))
+		}
+		fmt.Fprintf(bw, `<polyline class="path" points="%s" fill="none" stroke="%s" stroke-width="%d"/>`+"\n",
+			strings.Join(points, " "), hexColor(colorPath), v.scale)
+	}
+
+	for _, m := range s.markers() {
+		px, py, inside := v.pixel(s.Grid, m.position)
+		if !inside {
+			continue
+		}
+		half := m.size / 2
+		fmt.Fprintf(bw, `<rect x="%d" y="%d" width="%d" height="%d" fill="%s">`,
+			px-half*v.scale, py-half*v.scale, m.size*v.scale, m.size*v.scale, hexColor(m.color))
+		if m.label != "" {
+			fmt.Fprintf(bw, `<title>%s (%d, %d)</title>`, html.EscapeString(m.label), m.position.X, m.position.Y)
+		}
+		fmt.Fprintln(bw, `</rect>`)
+	}
+
+	fmt.Fprintln(bw, `</svg>`)
+
+	return bw.Flush()
+}
+
+func hexColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
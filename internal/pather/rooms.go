@@ -0,0 +1,373 @@
+package pather
+
+import (
+	"container/heap"
+	"math"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/koolo/internal/game"
+)
+
+const (
+	// Room distances are calculated on a grid this many times smaller, rooms are big and a few tiles don't matter
+	roomGridScale = 4
+	// Distance multiplier for the rooms without a walkable tile or not connected to the others, they go last
+	unreachableRoomPenalty = 4
+	// The improvement passes stop when nothing changes, or after this many on huge levels
+	maxRoomOrderPasses = 50
+	// Longest chain of rooms moved at once by the Or-opt pass
+	maxRoomChainLength = 3
+)
+
+// RoomsTraverseOrder returns the order to visit all the rooms starting at the given position, the one of the player.
+// The distances between rooms are walking distances on the grid, so rooms close in a straight line but on the other side
+// of a wall are not visited one after the other. When end is set (the exit or the waypoint of the level) the order ends
+// close to it, the distance from the last room to the end counts like the distance between rooms.
+//
+// The order is the nearest neighbour one improved with 2-opt (reversing a part of the order) and Or-opt (moving chains
+// of rooms to another place) until none of them finds a shorter one.
+func RoomsTraverseOrder(g *game.Grid, rooms []data.Room, start data.Position, end *data.Position) []data.Room {
+	if len(rooms) == 0 {
+		return nil
+	}
+
+	// Node 0 is the start, then every room and the end, when there is one
+	points := make([]data.Position, 0, len(rooms)+2)
+	points = append(points, start)
+	for _, r := range rooms {
+		points = append(points, roomTarget(g, r))
+	}
+	if end != nil {
+		points = append(points, *end)
+	}
+
+	rt := roomTour{distances: roomDistances(g, points), end: -1}
+	if end != nil {
+		rt.end = len(points) - 1
+	}
+	rt.nearestNeighbour(len(rooms))
+	for pass := 0; pass < maxRoomOrderPasses; pass++ {
+		improved := rt.twoOpt()
+		improved = rt.orOpt() || improved
+		if !improved {
+			break
+		}
+	}
+
+	order := make([]data.Room, 0, len(rooms))
+	for _, node := range rt.order {
+		order = append(order, rooms[node-1])
+	}
+
+	return order
+}
+
+// OptimizeRoomsTraverseOrder returns the order to visit the rooms of the current level from the player position.
+func (pf *PathFinder) OptimizeRoomsTraverseOrder() []data.Room {
+	return RoomsTraverseOrder(pf.data.AreaData.Grid, pf.data.Rooms, pf.data.PlayerUnit.Position, nil)
+}
+
+// OptimizeRoomsTraverseOrderTo is OptimizeRoomsTraverseOrder ending close to the given position, like the exit to the
+// next level.
+func (pf *PathFinder) OptimizeRoomsTraverseOrderTo(end data.Position) []data.Room {
+	return RoomsTraverseOrder(pf.data.AreaData.Grid, pf.data.Rooms, pf.data.PlayerUnit.Position, &end)
+}
+
+// roomTarget returns the walkable tile of the room closest to its center, the center itself when the whole room is not
+// walkable
+func roomTarget(g *game.Grid, r data.Room) data.Position {
+	center := r.GetCenter()
+	if g == nil {
+		return center
+	}
+
+	target, best := center, math.MaxInt
+	for y := r.Y; y < r.Y+r.Height; y++ {
+		for x := r.X; x < r.X+r.Width; x++ {
+			p := data.Position{X: x, Y: y}
+			rel := g.RelativePosition(p)
+			if rel.X < 0 || rel.Y < 0 || rel.X >= g.Width || rel.Y >= g.Height || g.CollisionGrid[rel.Y][rel.X] == game.CollisionTypeNonWalkable {
+				continue
+			}
+			if d := (x-center.X)*(x-center.X) + (y-center.Y)*(y-center.Y); d < best {
+				target, best = p, d
+			}
+		}
+	}
+
+	return target
+}
+
+// roomDistances returns the walking distance in tiles between every pair of points, positions are absolute. Points not
+// connected get the straight line distance with a penalty, they are still visited.
+func roomDistances(g *game.Grid, points []data.Position) [][]int {
+	distances := make([][]int, len(points))
+	for i := range distances {
+		distances[i] = make([]int, len(points))
+		for j := range points {
+			distances[i][j] = DistanceFromPoint(points[i], points[j]) * unreachableRoomPenalty
+		}
+	}
+	if g == nil {
+		return distances
+	}
+
+	cg := newCoarseGrid(g, roomGridScale)
+	cells := make([]int, len(points))
+	for i, p := range points {
+		cells[i] = cg.cell(g.RelativePosition(p))
+	}
+
+	costs := make([]int, len(cg.walkable))
+	for i, from := range cells {
+		if from < 0 {
+			continue
+		}
+		cg.costsFrom(from, costs)
+		for j, to := range cells {
+			if to >= 0 && costs[to] != math.MaxInt {
+				distances[i][j] = costs[to]
+			}
+		}
+	}
+
+	// Same distance both ways, the 2-opt pass reverses parts of the order
+	for i := range distances {
+		for j := i + 1; j < len(distances); j++ {
+			d := min(distances[i][j], distances[j][i])
+			distances[i][j], distances[j][i] = d, d
+		}
+	}
+
+	return distances
+}
+
+// coarseGrid is a grid where every cell is a square of scale*scale tiles, walkable when any of its tiles is
+type coarseGrid struct {
+	scale         int
+	width, height int
+	walkable      []bool
+}
+
+func newCoarseGrid(g *game.Grid, scale int) coarseGrid {
+	cg := coarseGrid{scale: scale, width: (g.Width + scale - 1) / scale, height: (g.Height + scale - 1) / scale}
+	cg.walkable = make([]bool, cg.width*cg.height)
+	for y := 0; y < g.Height; y++ {
+		for x := 0; x < g.Width; x++ {
+			if g.CollisionGrid[y][x] != game.CollisionTypeNonWalkable {
+				cg.walkable[(y/scale)*cg.width+x/scale] = true
+			}
+		}
+	}
+
+	return cg
+}
+
+// cell returns the cell of a position relative to the grid, -1 when it's outside or not walkable
+func (cg coarseGrid) cell(p data.Position) int {
+	if p.X < 0 || p.Y < 0 {
+		return -1
+	}
+	x, y := p.X/cg.scale, p.Y/cg.scale
+	if x >= cg.width || y >= cg.height || !cg.walkable[y*cg.width+x] {
+		return -1
+	}
+
+	return y*cg.width + x
+}
+
+// costsFrom fills costs with the distance in tiles from the cell to every other one (Dijkstra), math.MaxInt when they
+// are not connected
+func (cg coarseGrid) costsFrom(from int, costs []int) {
+	for i := range costs {
+		costs[i] = math.MaxInt
+	}
+	costs[from] = 0
+
+	straight, diagonal := cg.scale*10, cg.scale*14
+	pq := roomQueue{{cell: from}}
+	for len(pq) > 0 {
+		current := heap.Pop(&pq).(roomQueueItem)
+		if current.cost > costs[current.cell] {
+			continue
+		}
+
+		x, y := current.cell%cg.width, current.cell/cg.width
+		for _, d := range []data.Position{{X: 0, Y: 1}, {X: 1, Y: 0}, {X: 0, Y: -1}, {X: -1, Y: 0}, {X: 1, Y: 1}, {X: -1, Y: 1}, {X: 1, Y: -1}, {X: -1, Y: -1}} {
+			nx, ny := x+d.X, y+d.Y
+			if nx < 0 || ny < 0 || nx >= cg.width || ny >= cg.height || !cg.walkable[ny*cg.width+nx] {
+				continue
+			}
+			cost := straight
+			if d.X != 0 && d.Y != 0 {
+				cost = diagonal
+			}
+			if next := ny*cg.width + nx; current.cost+cost < costs[next] {
+				costs[next] = current.cost + cost
+				heap.Push(&pq, roomQueueItem{cell: next, cost: costs[next]})
+			}
+		}
+	}
+
+	// Back to tiles, the costs are 10 per tile to keep the diagonals as integers
+	for i, c := range costs {
+		if c != math.MaxInt {
+			costs[i] = c / 10
+		}
+	}
+}
+
+// roomTour is the order of the room nodes, between the start node (0) and the end node when there is one
+type roomTour struct {
+	distances [][]int
+	order     []int
+	end       int // -1 without end
+}
+
+func (rt *roomTour) nearestNeighbour(rooms int) {
+	visited := make([]bool, rooms+1)
+	current := 0
+	for len(rt.order) < rooms {
+		next := -1
+		for node := 1; node <= rooms; node++ {
+			if !visited[node] && (next < 0 || rt.distances[current][node] < rt.distances[current][next]) {
+				next = node
+			}
+		}
+		visited[next] = true
+		rt.order = append(rt.order, next)
+		current = next
+	}
+}
+
+// length returns the distance of the whole tour, from the start to the end
+func (rt *roomTour) length() int {
+	total := 0
+	for i, node := range rt.order {
+		total += rt.distances[rt.prev(rt.order, i)][node]
+	}
+
+	return total + rt.next(rt.order, rt.prev(rt.order, len(rt.order)), len(rt.order))
+}
+
+// twoOpt reverses the parts of the order that make it shorter, it returns true when something changed
+func (rt *roomTour) twoOpt() bool {
+	improved := false
+	for i := 0; i < len(rt.order)-1; i++ {
+		for j := i + 1; j < len(rt.order); j++ {
+			p := rt.prev(rt.order, i)
+			before := rt.distances[p][rt.order[i]] + rt.next(rt.order, rt.order[j], j+1)
+			after := rt.distances[p][rt.order[j]] + rt.next(rt.order, rt.order[i], j+1)
+			if after < before {
+				for a, b := i, j; a < b; a, b = a+1, b-1 {
+					rt.order[a], rt.order[b] = rt.order[b], rt.order[a]
+				}
+				improved = true
+			}
+		}
+	}
+
+	return improved
+}
+
+// orOpt moves chains of up to maxRoomChainLength rooms to the place where the order gets shorter, reversed when it's
+// better. It returns true when something changed.
+func (rt *roomTour) orOpt() bool {
+	improved := false
+	for length := 1; length <= maxRoomChainLength; length++ {
+		for i := 0; i+length <= len(rt.order); i++ {
+			if rt.moveChain(i, length) {
+				improved = true
+			}
+		}
+	}
+
+	return improved
+}
+
+// moveChain moves the chain of rooms starting at i to its best place, if any is better than the current one
+func (rt *roomTour) moveChain(i, length int) bool {
+	first, last := rt.order[i], rt.order[i+length-1]
+	// Removing the chain joins the rooms at both sides of it
+	removed := rt.distances[rt.prev(rt.order, i)][first] + rt.next(rt.order, last, i+length)
+	rest := make([]int, 0, len(rt.order)-length)
+	rest = append(rest, rt.order[:i]...)
+	rest = append(rest, rt.order[i+length:]...)
+	joined := rt.next(rest, rt.prev(rest, i), i)
+	gain := removed - joined
+
+	bestPos, bestDelta, bestReversed := -1, 0, false
+	for pos := 0; pos <= len(rest); pos++ {
+		if pos == i {
+			continue
+		}
+		p := rt.prev(rest, pos)
+		current := rt.next(rest, p, pos)
+		forward := rt.distances[p][first] + rt.next(rest, last, pos)
+		backward := rt.distances[p][last] + rt.next(rest, first, pos)
+		if delta := forward - current - gain; delta < bestDelta {
+			bestPos, bestDelta, bestReversed = pos, delta, false
+		}
+		if delta := backward - current - gain; delta < bestDelta {
+			bestPos, bestDelta, bestReversed = pos, delta, true
+		}
+	}
+	if bestPos < 0 {
+		return false
+	}
+
+	chain := append([]int(nil), rt.order[i:i+length]...)
+	if bestReversed {
+		for a, b := 0, len(chain)-1; a < b; a, b = a+1, b-1 {
+			chain[a], chain[b] = chain[b], chain[a]
+		}
+	}
+	order := make([]int, 0, len(rt.order))
+	order = append(order, rest[:bestPos]...)
+	order = append(order, chain...)
+	order = append(order, rest[bestPos:]...)
+	rt.order = order
+
+	return true
+}
+
+// prev returns the node before the position pos of the order, the start for the first one
+func (rt *roomTour) prev(order []int, pos int) int {
+	if pos == 0 {
+		return 0
+	}
+	return order[pos-1]
+}
+
+// next is the distance from a node to the node at the position pos of the order, the end after the last one. Without
+// end, there is nothing to walk after the last room.
+func (rt *roomTour) next(order []int, node, pos int) int {
+	if pos < len(order) {
+		return rt.distances[node][order[pos]]
+	}
+	if rt.end >= 0 {
+		return rt.distances[node][rt.end]
+	}
+	return 0
+}
+
+type roomQueueItem struct {
+	cell int
+	cost int
+}
+
+type roomQueue []roomQueueItem
+
+func (q roomQueue) Len() int           { return len(q) }
+func (q roomQueue) Less(i, j int) bool { return q[i].cost < q[j].cost }
+func (q roomQueue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+func (q *roomQueue) Push(x interface{}) {
+	*q = append(*q, x.(roomQueueItem))
+}
+func (q *roomQueue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	item := old[n-1]
+	*q = old[0 : n-1]
+	return item
+}
@@ -0,0 +1,263 @@
+package pather
+
+import (
+	"encoding/gob"
+	"math"
+	"os"
+	"testing"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/koolo/internal/game"
+	"github.com/hectorgimenez/koolo/internal/pather/astar"
+)
+
+func TestRoomsTraverseOrder(t *testing.T) {
+	grid := loadDuranceGrid(t)
+	start := data.Position{X: grid.OffsetX + duranceStart.X, Y: grid.OffsetY + duranceStart.Y}
+	rooms := reachableRooms(grid, gridRooms(grid, 40), start)
+
+	order := RoomsTraverseOrder(grid, rooms, start, nil)
+	checkVisitsAll(t, rooms, order)
+
+	greedy := walkingLength(t, grid, start, straightLineRoomOrder(rooms, start), nil)
+	planned := walkingLength(t, grid, start, order, nil)
+	t.Logf("%d rooms, straight line nearest neighbour: %d tiles, planned: %d tiles", len(rooms), greedy, planned)
+	if planned >= greedy {
+		t.Errorf("Expected a shorter walk than the straight line order (%d tiles), got %d", greedy, planned)
+	}
+}
+
+func TestRoomsTraverseOrderAroundWall(t *testing.T) {
+	// A wall with a gap at the bottom, the rooms at both sides are close in a straight line but not walking
+	cg := openCollisionGrid(100, 60)
+	for y := 0; y < 50; y++ {
+		for x := 48; x <= 52; x++ {
+			cg[y][x] = game.CollisionTypeNonWalkable
+		}
+	}
+	grid := game.NewGrid(cg, 0, 0)
+	start := data.Position{X: 40, Y: 5}
+	rooms := reachableRooms(grid, gridRooms(grid, 20), start)
+
+	order := RoomsTraverseOrder(grid, rooms, start, nil)
+	checkVisitsAll(t, rooms, order)
+
+	// Every side of the wall is cleared in one go, the wall is only walked around once
+	crossings := 0
+	for i := 1; i < len(order); i++ {
+		if (roomTarget(grid, order[i-1]).X < 50) != (roomTarget(grid, order[i]).X < 50) {
+			crossings++
+		}
+	}
+	if crossings != 1 {
+		t.Errorf("Expected to walk around the wall once, got %d times", crossings)
+	}
+
+	if greedy, planned := walkingLength(t, grid, start, straightLineRoomOrder(rooms, start), nil), walkingLength(t, grid, start, order, nil); planned > greedy {
+		t.Errorf("Expected a walk not longer than the straight line order (%d tiles), got %d", greedy, planned)
+	}
+}
+
+func TestRoomsTraverseOrderEnd(t *testing.T) {
+	grid := loadDuranceGrid(t)
+	start := data.Position{X: grid.OffsetX + duranceStart.X, Y: grid.OffsetY + duranceStart.Y}
+	rooms := reachableRooms(grid, gridRooms(grid, 40), start)
+	end := data.Position{X: grid.OffsetX + duranceGoal.X, Y: grid.OffsetY + duranceGoal.Y}
+
+	order := RoomsTraverseOrder(grid, rooms, start, &end)
+	checkVisitsAll(t, rooms, order)
+
+	free := RoomsTraverseOrder(grid, rooms, start, nil)
+	withEnd, withoutEnd := walkingLength(t, grid, start, order, &end), walkingLength(t, grid, start, free, &end)
+	t.Logf("Walk to the end after the last room: %d tiles ending near it, %d tiles without", withEnd, withoutEnd)
+	if withEnd > withoutEnd {
+		t.Errorf("Expected ending near the end to be shorter (%d tiles), got %d", withoutEnd, withEnd)
+	}
+	last := roomTarget(grid, order[len(order)-1])
+	if d := DistanceFromPoint(last, end); d > 120 {
+		t.Errorf("Expected the last room close to the end, it's %d tiles away", d)
+	}
+}
+
+func TestRoomTourImprovements(t *testing.T) {
+	// Points on a line visited out of order, both passes must sort them
+	positions := []int{0, 50, 10, 40, 20, 30}
+	distances := make([][]int, len(positions))
+	for i := range distances {
+		distances[i] = make([]int, len(positions))
+		for j := range distances[i] {
+			distances[i][j] = abs(positions[i] - positions[j])
+		}
+	}
+
+	rt := roomTour{distances: distances, order: []int{1, 2, 3, 4, 5}, end: -1}
+	for rt.twoOpt() || rt.orOpt() {
+	}
+	if rt.length() != 50 {
+		t.Errorf("Expected to walk the line once (50), got %d with order %v", rt.length(), rt.order)
+	}
+}
+
+func TestRoomsTraverseOrderRecorded(t *testing.T) {
+	// Part of the Durance of Hate split in rooms of 40x40 tiles, the size of the rooms of the maze levels. The areas of a
+	// recording set in KOOLO_RECORDING are checked as well
+	areas := []game.AreaData{loadRecordedArea(t, "testdata/durance_of_hate_rooms.bin")}
+	if path := os.Getenv("KOOLO_RECORDING"); path != "" {
+		rec, err := game.LoadRecording(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, ad := range rec.Areas {
+			areas = append(areas, ad)
+		}
+	}
+
+	for _, ad := range areas {
+		if ad.Grid == nil || len(ad.Rooms) < 2 {
+			continue
+		}
+		start := roomTarget(ad.Grid, ad.Rooms[0])
+		rooms := reachableRooms(ad.Grid, ad.Rooms, start)
+		order := RoomsTraverseOrder(ad.Grid, rooms, start, nil)
+		checkVisitsAll(t, rooms, order)
+
+		greedy := walkingLength(t, ad.Grid, start, straightLineRoomOrder(rooms, start), nil)
+		planned := walkingLength(t, ad.Grid, start, order, nil)
+		t.Logf("%s: %d rooms, straight line nearest neighbour: %d tiles, planned: %d tiles", ad.Name, len(rooms), greedy, planned)
+		if planned > greedy {
+			t.Errorf("%s: expected a walk not longer than the straight line order (%d tiles), got %d", ad.Name, greedy, planned)
+		}
+	}
+}
+
+func BenchmarkRoomsTraverseOrder(b *testing.B) {
+	grid := loadDuranceGrid(b)
+	start := data.Position{X: grid.OffsetX + duranceStart.X, Y: grid.OffsetY + duranceStart.Y}
+	rooms := reachableRooms(grid, gridRooms(grid, 40), start)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		RoomsTraverseOrder(grid, rooms, start, nil)
+	}
+}
+
+// loadRecordedArea reads an area saved with gob, like the ones kept in game recordings
+func loadRecordedArea(tb testing.TB, path string) game.AreaData {
+	file, err := os.Open(path)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	defer file.Close()
+
+	var ad game.AreaData
+	if err = gob.NewDecoder(file).Decode(&ad); err != nil {
+		tb.Fatal(err)
+	}
+
+	return ad
+}
+
+// gridRooms splits the grid in square rooms
+func gridRooms(g *game.Grid, size int) []data.Room {
+	var rooms []data.Room
+	for y := 0; y < g.Height; y += size {
+		for x := 0; x < g.Width; x += size {
+			rooms = append(rooms, data.Room{Position: data.Position{X: g.OffsetX + x, Y: g.OffsetY + y}, Width: min(size, g.Width-x), Height: min(size, g.Height-y)})
+		}
+	}
+
+	return rooms
+}
+
+// reachableRooms returns the rooms that can be walked to from the start
+func reachableRooms(g *game.Grid, rooms []data.Room, start data.Position) []data.Room {
+	reachable := make([][]bool, g.Height)
+	for y := range reachable {
+		reachable[y] = make([]bool, g.Width)
+	}
+	from := g.RelativePosition(start)
+	reachable[from.Y][from.X] = true
+	for queue := []data.Position{from}; len(queue) > 0; queue = queue[1:] {
+		for _, d := range []data.Position{{X: 0, Y: 1}, {X: 1, Y: 0}, {X: 0, Y: -1}, {X: -1, Y: 0}} {
+			n := data.Position{X: queue[0].X + d.X, Y: queue[0].Y + d.Y}
+			if n.X >= 0 && n.Y >= 0 && n.X < g.Width && n.Y < g.Height && !reachable[n.Y][n.X] && g.CollisionGrid[n.Y][n.X] != game.CollisionTypeNonWalkable {
+				reachable[n.Y][n.X] = true
+				queue = append(queue, n)
+			}
+		}
+	}
+
+	var kept []data.Room
+	for _, r := range rooms {
+		target := g.RelativePosition(roomTarget(g, r))
+		if target.X >= 0 && target.Y >= 0 && target.X < g.Width && target.Y < g.Height && reachable[target.Y][target.X] {
+			kept = append(kept, r)
+		}
+	}
+
+	return kept
+}
+
+// straightLineRoomOrder is the nearest neighbour order by straight line distance between room centers
+func straightLineRoomOrder(rooms []data.Room, start data.Position) []data.Room {
+	visited := make([]bool, len(rooms))
+	order := make([]data.Room, 0, len(rooms))
+	current := start
+	for len(order) < len(rooms) {
+		next, best := -1, math.MaxInt
+		for i, r := range rooms {
+			if d := DistanceFromPoint(current, r.GetCenter()); !visited[i] && d < best {
+				next, best = i, d
+			}
+		}
+		visited[next] = true
+		order = append(order, rooms[next])
+		current = rooms[next].GetCenter()
+	}
+
+	return order
+}
+
+// walkingLength returns the tiles walked visiting the rooms in order, from the start to the end when it's set
+func walkingLength(t *testing.T, g *game.Grid, start data.Position, order []data.Room, end *data.Position) int {
+	points := []data.Position{g.RelativePosition(start)}
+	for _, r := range order {
+		points = append(points, g.RelativePosition(roomTarget(g, r)))
+	}
+	if end != nil {
+		points = append(points, g.RelativePosition(*end))
+	}
+
+	total := 0
+	for i := 1; i < len(points); i++ {
+		path, _, found := astar.CalculatePath(g, points[i-1], points[i])
+		if !found {
+			t.Fatalf("Expected a path from %v to %v", points[i-1], points[i])
+		}
+		total += len(path) - 1
+	}
+
+	return total
+}
+
+func checkVisitsAll(t *testing.T, rooms, order []data.Room) {
+	t.Helper()
+
+	if len(order) != len(rooms) {
+		t.Fatalf("Expected %d rooms, got %d", len(rooms), len(order))
+	}
+	seen := make(map[data.Room]bool)
+	for _, r := range order {
+		if seen[r] {
+			t.Errorf("Room %v visited twice", r)
+		}
+		seen[r] = true
+	}
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,252 @@
+package pather
+
+import (
+	"container/heap"
+	"math"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/skill"
+	"github.com/hectorgimenez/d2go/pkg/data/stat"
+	"github.com/hectorgimenez/koolo/internal/game"
+)
+
+const (
+	// MaxTeleportDistance is the max distance of a teleport in tiles, the game area is the limit most of the time
+	MaxTeleportDistance = 40
+	// Number of directions tried from every landing tile
+	teleportDirections = 64
+	// Max landing tiles expanded by a single search, it keeps the worst case (unreachable destinations) bounded
+	maxTeleportExpansions = 10000
+	// Landing tiles closer than this are almost the same, only the first one found of every bucket is expanded
+	teleportBucketSize = 4
+)
+
+// TeleportManaCost returns the mana used by every cast, teleport costs 24 mana at level 1 and one less per level.
+func TeleportManaCost(skillLevel uint) int {
+	return max(1, 25-max(1, int(skillLevel)))
+}
+
+// TeleportPlan is the way to a destination with teleport. Walk is the part of the path walked first, when there is not
+// enough mana to teleport all the way, then every jump is a cast landing on that tile.
+type TeleportPlan struct {
+	Walk  Path
+	Jumps []data.Position
+}
+
+func (tp TeleportPlan) Casts() int {
+	return len(tp.Jumps)
+}
+
+// TeleportPlanner finds the way with the fewest teleport casts. A teleport is a jump over any terrain to any walkable
+// tile inside the grid that can be clicked on the screen, walls and gaps don't matter.
+type TeleportPlanner struct {
+	radius   int
+	reach    float64
+	jumpable [][]bool          // jumpable[dy+radius][dx+radius], the offsets that can be clicked
+	rays     [][]data.Position // offsets of every direction, from the farthest to the closest
+}
+
+// NewTeleportPlanner builds the jumps of a game area of the given size, the position of the player is the center of the
+// game area and the HUD at the bottom can't be clicked.
+func NewTeleportPlanner(gameAreaWidth, gameAreaHeight int) *TeleportPlanner {
+	hudBoundary := int(float32(gameAreaHeight) / 1.21)
+	tp := &TeleportPlanner{radius: MaxTeleportDistance}
+
+	size := 2*tp.radius + 1
+	tp.jumpable = make([][]bool, size)
+	for dy := -tp.radius; dy <= tp.radius; dy++ {
+		tp.jumpable[dy+tp.radius] = make([]bool, size)
+		for dx := -tp.radius; dx <= tp.radius; dx++ {
+			if dx*dx+dy*dy > tp.radius*tp.radius {
+				continue
+			}
+			// Same isometric transformation as gameCoordsToScreenCords
+			screenX := int((float32(dx-dy) * 19.8) + float32(gameAreaWidth/2))
+			screenY := int((float32(dx+dy) * 9.9) + float32(gameAreaHeight/2))
+			if screenX >= 0 && screenY >= 0 && screenX <= gameAreaWidth && screenY <= hudBoundary {
+				tp.jumpable[dy+tp.radius][dx+tp.radius] = true
+				tp.reach = max(tp.reach, math.Sqrt(float64(dx*dx+dy*dy)))
+			}
+		}
+	}
+
+	for i := 0; i < teleportDirections; i++ {
+		angle := 2 * math.Pi * float64(i) / teleportDirections
+		var ray []data.Position
+		for r := tp.radius; r > 0; r-- {
+			off := data.Position{X: int(math.Round(float64(r) * math.Cos(angle))), Y: int(math.Round(float64(r) * math.Sin(angle)))}
+			if tp.canJump(off) && (len(ray) == 0 || ray[len(ray)-1] != off) {
+				ray = append(ray, off)
+			}
+		}
+		tp.rays = append(tp.rays, ray)
+	}
+
+	return tp
+}
+
+// Plan returns the jumps from one position to the other with the fewest casts, positions are relative to the grid. When
+// more than maxCasts are needed, the walking path (ending at the destination) is followed until the rest of the way can
+// be done with maxCasts.
+func (tp *TeleportPlanner) Plan(g *game.Grid, from, to data.Position, maxCasts int, walk Path) (TeleportPlan, bool) {
+	jumps, found := tp.jumps(g, from, to)
+	if !found {
+		return TeleportPlan{}, false
+	}
+	if len(jumps) <= maxCasts || len(walk) < 2 {
+		return TeleportPlan{Jumps: jumps}, true
+	}
+
+	// Not enough mana, walk until the casts left are enough to get there. The casts needed are lower as the walking
+	// path gets closer to the destination, so the earliest point is found with a binary search.
+	lo, hi := 0, len(walk)-1
+	for lo < hi {
+		mid := (lo + hi) / 2
+		if j, found := tp.jumps(g, walk[mid], to); found && len(j) <= maxCasts {
+			hi = mid
+		} else {
+			lo = mid + 1
+		}
+	}
+	jumps, _ = tp.jumps(g, walk[hi], to)
+	if len(jumps) > maxCasts {
+		jumps = nil
+	}
+
+	return TeleportPlan{Walk: walk[:hi+1], Jumps: jumps}, true
+}
+
+// jumps runs A* with the number of casts as cost, the landing tiles of every direction are the farthest walkable ones
+func (tp *TeleportPlanner) jumps(g *game.Grid, from, to data.Position) ([]data.Position, bool) {
+	if from == to {
+		return nil, true
+	}
+	if !tp.inside(g, to) || g.CollisionGrid[to.Y][to.X] == game.CollisionTypeNonWalkable {
+		return nil, false
+	}
+
+	// Landing tile where every jump was cast from
+	cameFrom := map[data.Position]data.Position{from: from}
+	visited := map[data.Position]bool{tp.bucket(from): true}
+
+	pq := teleportQueue{{Position: from, distance: tp.distance(from, to)}}
+	for expansions := 0; len(pq) > 0 && expansions < maxTeleportExpansions; expansions++ {
+		current := heap.Pop(&pq).(teleportNode)
+
+		if tp.canJump(data.Position{X: to.X - current.X, Y: to.Y - current.Y}) {
+			jumps := []data.Position{to}
+			for p := current.Position; p != from; p = cameFrom[p] {
+				jumps = append(jumps, p)
+			}
+			for i, j := 0, len(jumps)-1; i < j; i, j = i+1, j-1 {
+				jumps[i], jumps[j] = jumps[j], jumps[i]
+			}
+			return jumps, true
+		}
+
+		for _, ray := range tp.rays {
+			for _, off := range ray {
+				p := data.Position{X: current.X + off.X, Y: current.Y + off.Y}
+				if !tp.inside(g, p) || g.CollisionGrid[p.Y][p.X] == game.CollisionTypeNonWalkable {
+					continue
+				}
+				if b := tp.bucket(p); !visited[b] {
+					visited[b] = true
+					cameFrom[p] = current.Position
+					heap.Push(&pq, teleportNode{Position: p, casts: current.casts + 1, distance: tp.distance(p, to)})
+				}
+				break
+			}
+		}
+	}
+
+	return nil, false
+}
+
+func (tp *TeleportPlanner) canJump(off data.Position) bool {
+	if off.X < -tp.radius || off.X > tp.radius || off.Y < -tp.radius || off.Y > tp.radius {
+		return false
+	}
+
+	return tp.jumpable[off.Y+tp.radius][off.X+tp.radius]
+}
+
+func (tp *TeleportPlanner) bucket(p data.Position) data.Position {
+	return data.Position{X: p.X / teleportBucketSize, Y: p.Y / teleportBucketSize}
+}
+
+func (tp *TeleportPlanner) inside(g *game.Grid, p data.Position) bool {
+	return p.X >= 0 && p.Y >= 0 && p.X < g.Width && p.Y < g.Height
+}
+
+// distance is the min number of casts to get there, it's the A* heuristic
+func (tp *TeleportPlanner) distance(from, to data.Position) float64 {
+	dx, dy := float64(to.X-from.X), float64(to.Y-from.Y)
+
+	return math.Sqrt(dx*dx+dy*dy) / tp.reach
+}
+
+// PlanTeleport plans the teleports to the end of the walking path p, with the fewest casts the current mana allows.
+// The jumps are relative to the same grid as the path.
+func (pf *PathFinder) PlanTeleport(p Path) (TeleportPlan, bool) {
+	if len(p) == 0 {
+		return TeleportPlan{}, false
+	}
+
+	// The path starts at the player position, relative to the grid it was calculated on: the current area grid or the
+	// one merged with the area of the destination
+	origin := data.Position{X: pf.data.PlayerUnit.Position.X - p.From().X, Y: pf.data.PlayerUnit.Position.Y - p.From().Y}
+	_, _, grid, err := pf.areaGrid(data.Position{X: origin.X + p.To().X, Y: origin.Y + p.To().Y})
+	if err != nil || grid.OffsetX != origin.X || grid.OffsetY != origin.Y {
+		return TeleportPlan{}, false
+	}
+
+	mana, _ := pf.data.PlayerUnit.FindStat(stat.Mana, 0)
+	maxCasts := mana.Value / TeleportManaCost(pf.data.PlayerUnit.Skills[skill.Teleport].Level)
+
+	return pf.getTeleportPlanner().Plan(grid, p.From(), p.To(), maxCasts, p)
+}
+
+// getTeleportPlanner returns the planner of the current game area size, the jumps depend on it
+func (pf *PathFinder) getTeleportPlanner() *TeleportPlanner {
+	w, h := pf.gr.GameAreaSize()
+
+	pf.teleportMu.Lock()
+	defer pf.teleportMu.Unlock()
+
+	if pf.teleportPlanner == nil || pf.teleportAreaSize != [2]int{w, h} {
+		pf.teleportPlanner = NewTeleportPlanner(w, h)
+		pf.teleportAreaSize = [2]int{w, h}
+	}
+
+	return pf.teleportPlanner
+}
+
+type teleportNode struct {
+	data.Position
+	casts    int
+	distance float64
+}
+
+// teleportQueue sorts by the estimated casts, the closest to the destination first on ties
+type teleportQueue []teleportNode
+
+func (q teleportQueue) Len() int { return len(q) }
+func (q teleportQueue) Less(i, j int) bool {
+	fi, fj := float64(q[i].casts)+math.Ceil(q[i].distance), float64(q[j].casts)+math.Ceil(q[j].distance)
+	if fi != fj {
+		return fi < fj
+	}
+	return q[i].distance < q[j].distance
+}
+func (q teleportQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+func (q *teleportQueue) Push(x interface{}) {
+	*q = append(*q, x.(teleportNode))
+}
+func (q *teleportQueue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	node := old[n-1]
+	*q = old[0 : n-1]
+	return node
+}
@@ -0,0 +1,195 @@
+package pather
+
+import (
+	"encoding/gob"
+	"math/rand"
+	"os"
+	"testing"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/koolo/internal/game"
+	"github.com/hectorgimenez/koolo/internal/pather/astar"
+)
+
+var (
+	duranceStart = data.Position{X: 336, Y: 701}
+	duranceGoal  = data.Position{X: 11, Y: 330}
+)
+
+func TestTeleportPlan(t *testing.T) {
+	grid := loadDuranceGrid(t)
+	tp := NewTeleportPlanner(1280, 720)
+
+	plan, found := tp.Plan(grid, duranceStart, duranceGoal, 100, nil)
+	if !found {
+		t.Fatalf("Expected a teleport plan")
+	}
+	checkJumps(t, tp, grid, duranceStart, plan.Jumps)
+	if plan.Jumps[len(plan.Jumps)-1] != duranceGoal || len(plan.Walk) != 0 {
+		t.Errorf("Expected to teleport to the destination, got %+v", plan)
+	}
+
+	// The walking path sampled like before, the farthest point on the screen every time
+	walk, _, _ := astar.CalculatePath(grid, duranceStart, duranceGoal)
+	if sampled := sampledCasts(tp, walk); plan.Casts() > sampled {
+		t.Errorf("Expected at most %d casts, got %d", sampled, plan.Casts())
+	}
+}
+
+func TestTeleportPlanOverWalls(t *testing.T) {
+	// A wall with a gap at the bottom, walking around it is 100+ tiles
+	cg := make([][]game.CollisionType, 60)
+	for y := range cg {
+		cg[y] = make([]game.CollisionType, 100)
+		for x := range cg[y] {
+			cg[y][x] = game.CollisionTypeWalkable
+			if x >= 48 && x <= 52 && y < 55 {
+				cg[y][x] = game.CollisionTypeNonWalkable
+			}
+		}
+	}
+	grid := game.NewGrid(cg, 0, 0)
+	tp := NewTeleportPlanner(1280, 720)
+
+	from, to := data.Position{X: 30, Y: 10}, data.Position{X: 70, Y: 10}
+	plan, found := tp.Plan(grid, from, to, 100, nil)
+	if !found || plan.Casts() > 2 {
+		t.Fatalf("Expected to teleport over the wall with 2 casts at most, got %+v", plan)
+	}
+	checkJumps(t, tp, grid, from, plan.Jumps)
+
+	// Outside the level, or not walkable
+	if _, found = tp.Plan(grid, from, data.Position{X: 120, Y: 10}, 100, nil); found {
+		t.Errorf("Expected no plan outside the grid")
+	}
+	if _, found = tp.Plan(grid, from, data.Position{X: 50, Y: 10}, 100, nil); found {
+		t.Errorf("Expected no plan to a wall")
+	}
+}
+
+func TestTeleportPlanLowMana(t *testing.T) {
+	grid := loadDuranceGrid(t)
+	tp := NewTeleportPlanner(1280, 720)
+	walk, _, _ := astar.CalculatePath(grid, duranceStart, duranceGoal)
+
+	plan, found := tp.Plan(grid, duranceStart, duranceGoal, 0, walk)
+	if !found || plan.Casts() != 0 || len(plan.Walk) != len(walk) {
+		t.Errorf("Expected to walk all the way without mana, got %d casts and %d walking tiles", plan.Casts(), len(plan.Walk))
+	}
+
+	full, _ := tp.Plan(grid, duranceStart, duranceGoal, 100, walk)
+	plan, found = tp.Plan(grid, duranceStart, duranceGoal, 2, walk)
+	if !found || plan.Casts() == 0 || plan.Casts() > 2 || full.Casts() <= 2 {
+		t.Fatalf("Expected 1 or 2 casts, got %d (%d with enough mana)", plan.Casts(), full.Casts())
+	}
+	if len(plan.Walk) < 2 || plan.Walk[0] != duranceStart || len(plan.Walk) >= len(walk) {
+		t.Errorf("Expected to walk part of the path first, got %d of %d tiles", len(plan.Walk), len(walk))
+	}
+	checkJumps(t, tp, grid, plan.Walk.To(), plan.Jumps)
+}
+
+func TestTeleportManaCost(t *testing.T) {
+	for level, expected := range map[uint]int{0: 24, 1: 24, 10: 15, 23: 2, 30: 1} {
+		if cost := TeleportManaCost(level); cost != expected {
+			t.Errorf("Level %d: expected %d mana, got %d", level, expected, cost)
+		}
+	}
+}
+
+func BenchmarkTeleportPlan(b *testing.B) {
+	grid := loadDuranceGrid(b)
+	tp := NewTeleportPlanner(1280, 720)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tp.Plan(grid, duranceStart, duranceGoal, 100, nil)
+	}
+}
+
+// BenchmarkTeleportPlanRecordedGrids plans random teleports on the area grids of a game recording, the file is set
+// with the KOOLO_RECORDING environment variable.
+func BenchmarkTeleportPlanRecordedGrids(b *testing.B) {
+	path := os.Getenv("KOOLO_RECORDING")
+	if path == "" {
+		b.Skip("KOOLO_RECORDING is not set")
+	}
+	rec, err := game.LoadRecording(path)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	tp := NewTeleportPlanner(1280, 720)
+	for _, ad := range rec.Areas {
+		if ad.Grid == nil {
+			continue
+		}
+		var walkable []data.Position
+		for y := 0; y < ad.Height; y++ {
+			for x := 0; x < ad.Width; x++ {
+				if ad.CollisionGrid[y][x] != game.CollisionTypeNonWalkable {
+					walkable = append(walkable, data.Position{X: x, Y: y})
+				}
+			}
+		}
+		if len(walkable) == 0 {
+			continue
+		}
+		r := rand.New(rand.NewSource(1))
+		pairs := make([][2]data.Position, 20)
+		for i := range pairs {
+			pairs[i] = [2]data.Position{walkable[r.Intn(len(walkable))], walkable[r.Intn(len(walkable))]}
+		}
+
+		b.Run(ad.Name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				p := pairs[i%len(pairs)]
+				tp.Plan(ad.Grid, p[0], p[1], 100, nil)
+			}
+		})
+	}
+}
+
+// checkJumps fails when a jump lands on a non walkable tile or can't be clicked from the previous one
+func checkJumps(t *testing.T, tp *TeleportPlanner, g *game.Grid, from data.Position, jumps []data.Position) {
+	t.Helper()
+	for _, j := range jumps {
+		if !tp.inside(g, j) || g.CollisionGrid[j.Y][j.X] == game.CollisionTypeNonWalkable {
+			t.Fatalf("Jump to %v is not walkable", j)
+		}
+		if !tp.canJump(data.Position{X: j.X - from.X, Y: j.Y - from.Y}) {
+			t.Fatalf("Jump from %v to %v is out of the screen", from, j)
+		}
+		from = j
+	}
+}
+
+func sampledCasts(tp *TeleportPlanner, walk []data.Position) int {
+	casts := 0
+	for current := 0; current < len(walk)-1; casts++ {
+		next := current + 1
+		for i := len(walk) - 1; i > current; i-- {
+			if tp.canJump(data.Position{X: walk[i].X - walk[current].X, Y: walk[i].Y - walk[current].Y}) {
+				next = i
+				break
+			}
+		}
+		current = next
+	}
+
+	return casts
+}
+
+func loadDuranceGrid(tb testing.TB) *game.Grid {
+	file, err := os.Open("astar/durance_of_hate_grid.bin")
+	if err != nil {
+		tb.Fatal(err)
+	}
+	defer file.Close()
+
+	var grid game.Grid
+	if err = gob.NewDecoder(file).Decode(&grid); err != nil {
+		tb.Fatal(err)
+	}
+
+	return &grid
+}
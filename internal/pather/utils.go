@@ -13,8 +13,9 @@ import (
 )
 
 func (pf *PathFinder) RandomMovement() {
-	midGameX := pf.gr.GameAreaSizeX / 2
-	midGameY := pf.gr.GameAreaSizeY / 2
+	gameAreaSizeX, gameAreaSizeY := pf.gr.GameAreaSize()
+	midGameX := gameAreaSizeX / 2
+	midGameY := gameAreaSizeY / 2
 	x := midGameX + rand.Intn(midGameX) - (midGameX / 2)
 	y := midGameY + rand.Intn(midGameY) - (midGameY / 2)
 	pf.hid.MovePointer(x, y)
@@ -26,56 +27,10 @@ func (pf *PathFinder) DistanceFromMe(p data.Position) int {
 	return DistanceFromPoint(pf.data.PlayerUnit.Position, p)
 }
 
-func (pf *PathFinder) OptimizeRoomsTraverseOrder() []data.Room {
-	distanceMatrix := make(map[data.Room]map[data.Room]int)
-
-	for _, room1 := range pf.data.Rooms {
-		distanceMatrix[room1] = make(map[data.Room]int)
-		for _, room2 := range pf.data.Rooms {
-			if room1 != room2 {
-				distance := DistanceFromPoint(room1.GetCenter(), room2.GetCenter())
-				distanceMatrix[room1][room2] = distance
-			} else {
-				distanceMatrix[room1][room2] = 0
-			}
-		}
-	}
-
-	currentRoom := data.Room{}
-	for _, r := range pf.data.Rooms {
-		if r.IsInside(pf.data.PlayerUnit.Position) {
-			currentRoom = r
-		}
-	}
-
-	visited := make(map[data.Room]bool)
-	order := []data.Room{currentRoom}
-	visited[currentRoom] = true
-
-	for len(order) < len(pf.data.Rooms) {
-		nextRoom := data.Room{}
-		minDistance := math.MaxInt
-
-		// Find the nearest unvisited room
-		for _, room := range pf.data.Rooms {
-			if !visited[room] && distanceMatrix[currentRoom][room] < minDistance {
-				nextRoom = room
-				minDistance = distanceMatrix[currentRoom][room]
-			}
-		}
-
-		// Add the next room to the order of visit
-		order = append(order, nextRoom)
-		visited[nextRoom] = true
-		currentRoom = nextRoom
-	}
-
-	return order
-}
-
 func (pf *PathFinder) MoveThroughPath(p Path, walkDuration time.Duration) {
+	pf.setLastPath(p)
 	if pf.data.CanTeleport() {
-		pf.moveThroughPathTeleport(p)
+		pf.moveThroughPathTeleport(p, walkDuration)
 	} else {
 		pf.moveThroughPathWalk(p, walkDuration)
 	}
@@ -86,32 +41,50 @@ func (pf *PathFinder) moveThroughPathWalk(p Path, walkDuration time.Duration) {
 	maxDistance := int(float64(25) * walkDuration.Seconds())
 
 	// Let's try to calculate how close to the window border we can go
+	gameAreaSizeX, gameAreaSizeY := pf.gr.GameAreaSize()
 	screenCords := data.Position{}
 	for distance, pos := range p {
 		screenX, screenY := pf.gameCoordsToScreenCords(p.From().X, p.From().Y, pos.X, pos.Y)
 
-		// We reached max distance, let's stop (if we are not teleporting)
-		if !pf.data.CanTeleport() && maxDistance > 0 && distance > maxDistance {
+		// We reached max distance, let's stop
+		if maxDistance > 0 && distance > maxDistance {
 			break
 		}
 
 		// Prevent mouse overlap the HUD
-		if screenY > int(float32(pf.gr.GameAreaSizeY)/1.21) {
+		if screenY > int(float32(gameAreaSizeY)/1.21) {
 			break
 		}
 
 		// We are getting out of the window, let's stop
-		if screenX < 0 || screenY < 0 || screenX > pf.gr.GameAreaSizeX || screenY > pf.gr.GameAreaSizeY {
+		if screenX < 0 || screenY < 0 || screenX > gameAreaSizeX || screenY > gameAreaSizeY {
 			break
 		}
 		screenCords = data.Position{X: screenX, Y: screenY}
 	}
 
-	pf.MoveCharacter(screenCords.X, screenCords.Y)
+	pf.walkCharacter(screenCords.X, screenCords.Y)
+}
+
+// moveThroughPathTeleport casts the first teleport of the plan with the fewest casts, or walks when there is not enough
+// mana to teleport from the current position.
+func (pf *PathFinder) moveThroughPathTeleport(p Path, walkDuration time.Duration) {
+	plan, found := pf.PlanTeleport(p)
+	switch {
+	case !found:
+		pf.teleportThroughPath(p)
+	case len(plan.Walk) > 1:
+		pf.moveThroughPathWalk(plan.Walk, walkDuration)
+	case len(plan.Jumps) > 0:
+		x, y := pf.gameCoordsToScreenCords(p.From().X, p.From().Y, plan.Jumps[0].X, plan.Jumps[0].Y)
+		pf.hid.Click(game.RightButton, x, y)
+	}
 }
 
-func (pf *PathFinder) moveThroughPathTeleport(p Path) {
-	hudBoundary := int(float32(pf.gr.GameAreaSizeY) / 1.21)
+// teleportThroughPath teleports to the farthest point of the path that is on the screen
+func (pf *PathFinder) teleportThroughPath(p Path) {
+	gameAreaSizeX, gameAreaSizeY := pf.gr.GameAreaSize()
+	hudBoundary := int(float32(gameAreaSizeY) / 1.21)
 	fromX, fromY := p.From().X, p.From().Y
 
 	for i := len(p) - 1; i >= 0; i-- {
@@ -124,7 +97,7 @@ func (pf *PathFinder) moveThroughPathTeleport(p Path) {
 		}
 
 		// Check if coordinates are within screen bounds
-		if screenX >= 0 && screenY >= 0 && screenX <= pf.gr.GameAreaSizeX && screenY <= pf.gr.GameAreaSizeY {
+		if screenX >= 0 && screenY >= 0 && screenX <= gameAreaSizeX && screenY <= gameAreaSizeY {
 			pf.MoveCharacter(screenX, screenY)
 			return
 		}
@@ -135,12 +108,16 @@ func (pf *PathFinder) MoveCharacter(x, y int) {
 	if pf.data.CanTeleport() {
 		pf.hid.Click(game.RightButton, x, y)
 	} else {
-		pf.hid.MovePointer(x, y)
-		pf.hid.PressKeyBinding(pf.data.KeyBindings.ForceMove)
-		utils.Sleep(50)
+		pf.walkCharacter(x, y)
 	}
 }
 
+func (pf *PathFinder) walkCharacter(x, y int) {
+	pf.hid.MovePointer(x, y)
+	pf.hid.PressKeyBinding(pf.data.KeyBindings.ForceMove)
+	utils.Sleep(50)
+}
+
 func (pf *PathFinder) GameCoordsToScreenCords(destinationX, destinationY int) (int, int) {
 	return pf.gameCoordsToScreenCords(pf.data.PlayerUnit.Position.X, pf.data.PlayerUnit.Position.Y, destinationX, destinationY)
 }
@@ -152,8 +129,9 @@ func (pf *PathFinder) gameCoordsToScreenCords(playerX, playerY, destinationX, de
 
 	// Transform cartesian movement (World) to isometric (screen)
 	// Helpful documentation: https://clintbellanger.net/articles/isometric_math/
-	screenX := int((float32(diffX-diffY) * 19.8) + float32(pf.gr.GameAreaSizeX/2))
-	screenY := int((float32(diffX+diffY) * 9.9) + float32(pf.gr.GameAreaSizeY/2))
+	gameAreaSizeX, gameAreaSizeY := pf.gr.GameAreaSize()
+	screenX := int((float32(diffX-diffY) * 19.8) + float32(gameAreaSizeX/2))
+	screenY := int((float32(diffX+diffY) * 9.9) + float32(gameAreaSizeY/2))
 
 	return screenX, screenY
 }
@@ -0,0 +1,450 @@
+package pickit
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/hectorgimenez/d2go/pkg/data/item"
+	"github.com/hectorgimenez/d2go/pkg/nip"
+)
+
+var (
+	anaMetaPropRegexp  = regexp.MustCompile(`\[(maxquantity|tier|merctier)]\s*(<=|<|>=|>|!=|==)\s*[0-9]+`)
+	anaFixedTermRegexp = regexp.MustCompile(`^\[([a-z]+)]\s*(<=|<|>=|>|!=|==)\s*([a-z0-9]+)$`)
+	anaStatTermRegexp  = regexp.MustCompile(`^\[([a-z0-9]+)]\s*(<=|<|>=|>|!=|==)\s*(-?[0-9]+)$`)
+	anaStatNameRegexp  = regexp.MustCompile(`\[(.*?)]`)
+
+	// Same values nip uses for the stage1 properties, they are not exported
+	nipQualities = map[string]int{"lowquality": 1, "normal": 2, "superior": 3, "magic": 4, "set": 5, "rare": 6, "unique": 7, "crafted": 8}
+	nipClasses   = map[string]int{"normal": 0, "exceptional": 1, "elite": 2}
+)
+
+// RuleFinding is a problem found by AnalyzeRules in a single NIP rule
+type RuleFinding struct {
+	Type     string `json:"type"`              // shadowed, contradiction, unknown_property, flag, maxquantity or tier_overlap
+	Severity string `json:"severity"`          // error or warning
+	File     string `json:"file"`              // NIP file the rule belongs to
+	Line     int    `json:"line"`              // Line number in the file
+	Rule     string `json:"rule"`              // Raw NIP line
+	Related  string `json:"related,omitempty"` // file:line of the other rule involved, if any
+	Message  string `json:"message"`
+}
+
+// valueRange is the set of values a rule accepts for a property: every integer in [min, max] not excluded by a !=
+type valueRange struct {
+	min, max int
+	not      map[int]bool
+}
+
+func fullRange() *valueRange {
+	return &valueRange{min: math.MinInt, max: math.MaxInt, not: map[int]bool{}}
+}
+
+func (r *valueRange) apply(op string, v int) {
+	switch op {
+	case "==":
+		r.min, r.max = max(r.min, v), min(r.max, v)
+	case "!=":
+		r.not[v] = true
+	case ">=":
+		r.min = max(r.min, v)
+	case ">":
+		if v < math.MaxInt {
+			r.min = max(r.min, v+1)
+		} else {
+			r.min, r.max = 1, 0
+		}
+	case "<=":
+		r.max = min(r.max, v)
+	case "<":
+		if v > math.MinInt {
+			r.max = min(r.max, v-1)
+		} else {
+			r.min, r.max = 1, 0
+		}
+	}
+}
+
+func (r *valueRange) contains(v int) bool {
+	return v >= r.min && v <= r.max && !r.not[v]
+}
+
+func (r *valueRange) empty() bool {
+	if r.min > r.max {
+		return true
+	}
+	// Small ranges may be fully excluded, like [flag] == ethereal && [flag] != ethereal
+	if uint(r.max-r.min) < 64 {
+		for v := r.min; v <= r.max; v++ {
+			if !r.not[v] {
+				return false
+			}
+		}
+		return true
+	}
+
+	return false
+}
+
+// subsetOf returns true when every value accepted by r is accepted by o as well
+func (r *valueRange) subsetOf(o *valueRange) bool {
+	if r.empty() {
+		return true
+	}
+	if r.min < o.min || r.max > o.max {
+		return false
+	}
+	for v := range o.not {
+		if r.contains(v) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (r *valueRange) intersects(o *valueRange) bool {
+	i := &valueRange{min: max(r.min, o.min), max: min(r.max, o.max), not: map[int]bool{}}
+	for v := range r.not {
+		i.not[v] = true
+	}
+	for v := range o.not {
+		i.not[v] = true
+	}
+
+	return !i.empty()
+}
+
+// ruleModel is the rule reduced to the values it accepts for every property, only possible when both stages are a
+// list of conditions joined by &&. Stats are prefixed with # so they don't collide with the stage1 properties.
+type ruleModel struct {
+	rule       nip.Rule
+	file       string
+	simple     bool // Both stages are plain && conditions, so props describes the rule exactly
+	hasStats   bool // Rule has a stage2, so it's a partial match for unidentified items
+	props      map[string]*valueRange
+	unknown    []string
+	flags      []string // [flag] values other than ethereal, nip compares them as ethereal
+	contradict string
+}
+
+func (m ruleModel) location() string {
+	return fmt.Sprintf("%s:%d", m.file, m.rule.LineNumber)
+}
+
+func (m ruleModel) plain() bool {
+	return m.rule.Tier() == 0 && m.rule.MercTier() == 0
+}
+
+func (m ruleModel) usable() bool {
+	return m.rule.Enabled && m.simple && m.contradict == "" && len(m.unknown) == 0
+}
+
+func (m ruleModel) prop(name string) *valueRange {
+	if r, found := m.props[name]; found {
+		return r
+	}
+
+	switch name {
+	case "quality":
+		return &valueRange{min: 1, max: 8, not: map[int]bool{}}
+	case "class":
+		return &valueRange{min: 0, max: 2, not: map[int]bool{}}
+	case "flag":
+		return &valueRange{min: 0, max: 1, not: map[int]bool{}}
+	}
+
+	return fullRange()
+}
+
+func (m ruleModel) propNames(o ruleModel) []string {
+	names := make([]string, 0, len(m.props)+len(o.props))
+	for name := range m.props {
+		names = append(names, name)
+	}
+	for name := range o.props {
+		if _, found := m.props[name]; !found {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+// subsetOf returns true when every item matched by m is matched by o too
+func (m ruleModel) subsetOf(o ruleModel) bool {
+	for _, name := range m.propNames(o) {
+		if !m.prop(name).subsetOf(o.prop(name)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (m ruleModel) overlaps(o ruleModel) bool {
+	for _, name := range m.propNames(o) {
+		if !m.prop(name).intersects(o.prop(name)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// analyzerLine does the same cleanup nip does before compiling a rule, removing the properties it can not evaluate
+func analyzerLine(raw string) string {
+	clean := func(l string) string {
+		l = strings.TrimSpace(strings.Split(l, "//")[0])
+		l = strings.Join(strings.Fields(l), " ")
+		l = strings.ReplaceAll(l, "'", "")
+		l = strings.ReplaceAll(l, "=>", ">=")
+		l = strings.ReplaceAll(l, "=<", "<=")
+		return strings.ToLower(strings.TrimSpace(strings.Trim(l, "&&")))
+	}
+
+	return clean(anaMetaPropRegexp.ReplaceAllString(clean(raw), ""))
+}
+
+func fixedPropValue(prop, value string) (int, bool) {
+	switch prop {
+	case "type":
+		code, found := nip.TypeAliases[value]
+		if !found {
+			return 0, false
+		}
+		return item.ItemTypes[code].ID, true
+	case "quality":
+		v, found := nipQualities[value]
+		return v, found
+	case "class":
+		v, found := nipClasses[value]
+		return v, found
+	case "name":
+		id := item.GetIDByName(value)
+		return id, id >= 0
+	case "flag":
+		// nip compares every flag as ethereal
+		return 1, true
+	case "prefix", "suffix":
+		// Affix ids are compared as they are
+		v, err := strconv.Atoi(value)
+		return v, err == nil
+	}
+
+	return 0, false
+}
+
+func newRuleModel(rule nip.Rule) ruleModel {
+	m := ruleModel{
+		rule:   rule,
+		file:   RuleFileName(rule),
+		simple: true,
+		props:  make(map[string]*valueRange),
+	}
+
+	parts := strings.Split(analyzerLine(rule.RawLine), "#")
+	stage1 := strings.TrimSpace(parts[0])
+	stage2 := ""
+	if len(parts) > 1 {
+		stage2 = strings.TrimSpace(parts[1])
+	}
+	m.hasStats = stage2 != ""
+
+	for _, cond := range simFixedPropRegexp.FindAllStringSubmatch(stage1, -1) {
+		switch {
+		case cond[1] == "color":
+			// Not evaluated by nip, the rule is just not compared with the others
+		case cond[1] == "flag" && cond[3] != "ethereal":
+			m.flags = append(m.flags, cond[3])
+		default:
+			if _, valid := fixedPropValue(cond[1], cond[3]); !valid {
+				m.unknown = append(m.unknown, fmt.Sprintf("%s %s", cond[1], cond[3]))
+			}
+		}
+	}
+	seen := make(map[string]bool)
+	for _, st := range anaStatNameRegexp.FindAllStringSubmatch(stage2, -1) {
+		if _, found := nip.StatAliases[st[1]]; !found && !seen[st[1]] {
+			seen[st[1]] = true
+			m.unknown = append(m.unknown, fmt.Sprintf("stat %s", st[1]))
+		}
+	}
+
+	constrain := func(name, op string, v int) {
+		if _, found := m.props[name]; !found {
+			m.props[name] = m.prop(name)
+		}
+		m.props[name].apply(op, v)
+	}
+
+	for _, term := range conditionTerms(stage1) {
+		match := anaFixedTermRegexp.FindStringSubmatch(term)
+		if match == nil {
+			m.simple = false
+			break
+		}
+		v, valid := fixedPropValue(match[1], match[3])
+		if !valid {
+			m.simple = false
+			break
+		}
+		op := match[2]
+		if match[1] == "flag" && op == "!=" {
+			op, v = "==", 0
+		}
+		constrain(match[1], op, v)
+	}
+
+	for _, term := range conditionTerms(stage2) {
+		match := anaStatTermRegexp.FindStringSubmatch(term)
+		if match == nil {
+			m.simple = false
+			break
+		}
+		v, err := strconv.Atoi(match[3])
+		if err != nil {
+			m.simple = false
+			break
+		}
+		constrain("#"+match[1], match[2], v)
+	}
+
+	if !m.simple {
+		return m
+	}
+
+	// A single base item has a known type and class, so [name] rules can be compared with [type] and [class] ones
+	if name, found := m.props["name"]; found && name.min == name.max && name.contains(name.min) {
+		desc := item.Desc[name.min]
+		constrain("type", "==", desc.GetType().ID)
+		constrain("class", "==", int(desc.Tier()))
+	}
+
+	for name, r := range m.props {
+		if r.empty() {
+			m.contradict = strings.TrimPrefix(name, "#")
+			break
+		}
+	}
+
+	return m
+}
+
+// conditionTerms splits a stage in its && conditions, nil when it's empty
+func conditionTerms(stage string) []string {
+	if stage == "" {
+		return nil
+	}
+
+	terms := strings.Split(stage, "&&")
+	for i, t := range terms {
+		terms[i] = strings.TrimSpace(t)
+	}
+
+	return terms
+}
+
+// AnalyzeRules looks for rules that will never behave as expected: rules that can never match, rules fully shadowed
+// by a broader rule loaded before them, unknown properties, overlapping rules with a different maxquantity and tier
+// rules overlapping keep rules. Only rules made of conditions joined by && are compared between them, the others are
+// just checked for unknown properties. Rules must be in the same order the bot loads them.
+func AnalyzeRules(rules nip.Rules) []RuleFinding {
+	findings := []RuleFinding{}
+
+	models := make([]ruleModel, len(rules))
+	for i, rule := range rules {
+		models[i] = newRuleModel(rule)
+	}
+
+	finding := func(m ruleModel, typ, severity, message string, related *ruleModel) RuleFinding {
+		f := RuleFinding{
+			Type:     typ,
+			Severity: severity,
+			File:     m.file,
+			Line:     m.rule.LineNumber,
+			Rule:     strings.TrimSpace(m.rule.RawLine),
+			Message:  message,
+		}
+		if related != nil {
+			f.Related = related.location()
+		}
+		return f
+	}
+
+	for j, m := range models {
+		if !m.rule.Enabled {
+			continue
+		}
+
+		for _, u := range m.unknown {
+			findings = append(findings, finding(m, "unknown_property", "error",
+				fmt.Sprintf("Unknown %s, the condition will never match", u), nil))
+		}
+		for _, flag := range m.flags {
+			findings = append(findings, finding(m, "flag", "warning",
+				fmt.Sprintf("[flag] %s is compared as [flag] ethereal, it's the only flag nip knows", flag), nil))
+		}
+		if m.contradict != "" {
+			findings = append(findings, finding(m, "contradiction", "error",
+				fmt.Sprintf("Conditions on [%s] contradict each other, the rule can never match", m.contradict), nil))
+		}
+		if !m.usable() {
+			continue
+		}
+
+		if m.plain() {
+			shadowedBy := -1
+			for i := 0; i < j; i++ {
+				o := models[i]
+				if !o.usable() || !o.plain() || (o.hasStats && !m.hasStats) {
+					continue
+				}
+				if m.subsetOf(o) {
+					shadowedBy = i
+					findings = append(findings, finding(m, "shadowed", "warning",
+						fmt.Sprintf("Every item matching this rule is already matched by %s, this rule is never used", o.location()), &o))
+					break
+				}
+			}
+
+			for i := 0; i < j; i++ {
+				o := models[i]
+				if i == shadowedBy || !o.usable() || !o.plain() || o.rule.MaxQuantity() == m.rule.MaxQuantity() {
+					continue
+				}
+				if m.overlaps(o) {
+					findings = append(findings, finding(m, "maxquantity", "warning",
+						fmt.Sprintf("Overlaps with %s but has a different maxquantity (%s vs %s), items matching both use the first matching rule",
+							o.location(), quantityString(m.rule.MaxQuantity()), quantityString(o.rule.MaxQuantity())), &o))
+					break
+				}
+			}
+			continue
+		}
+
+		for i, o := range models {
+			if i == j || !o.usable() || !o.plain() {
+				continue
+			}
+			if m.overlaps(o) {
+				findings = append(findings, finding(m, "tier_overlap", "warning",
+					fmt.Sprintf("Overlaps with the keep rule %s, items matching both are always stashed even when they are not an upgrade", o.location()), &o))
+				break
+			}
+		}
+	}
+
+	return findings
+}
+
+func quantityString(q int) string {
+	if q == 0 {
+		return "unlimited"
+	}
+
+	return strconv.Itoa(q)
+}
@@ -0,0 +1,107 @@
+package pickit
+
+import (
+	"strconv"
+	"testing"
+)
+
+func analyze(t *testing.T, content string) []RuleFinding {
+	t.Helper()
+
+	rules, err := ParseNIPContent(content, "test.nip")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	return AnalyzeRules(rules)
+}
+
+func TestAnalyzeRules(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected []string // type@line>related
+	}{
+		{
+			name: "shadowed by broader rule",
+			content: "[type] == ring && [quality] == unique\n" +
+				"[name] == ring && [quality] == unique # [maxmana] >= 20\n" +
+				"[type] == amulet && [quality] == unique # [itemmaxmanapercent] >= 5",
+			expected: []string{"shadowed@2>test.nip:1"},
+		},
+		{
+			name: "broader rule loaded later is fine",
+			content: "[name] == ring && [quality] == unique # [maxmana] >= 20\n" +
+				"[type] == ring && [quality] == unique",
+			expected: []string{},
+		},
+		{
+			name: "stat rule doesn't shadow unidentified match",
+			content: "[type] == ring && [quality] == unique # [maxmana] >= 0\n" +
+				"[type] == ring && [quality] == unique",
+			expected: []string{},
+		},
+		{
+			name: "contradictions",
+			content: "[type] == ring && [quality] == unique && [quality] == set\n" +
+				"[type] == amulet # [fcr] >= 20 && [fcr] < 10\n" +
+				"[type] == circlet && [flag] == ethereal && [flag] != ethereal\n" +
+				"[type] == ring && ([quality] == unique || [quality] == set)",
+			expected: []string{"contradiction@1", "contradiction@2", "contradiction@3"},
+		},
+		{
+			name:     "unknown properties",
+			content:  "[name] == notanitem && [quality] == unique # [fakestat] >= 1 && [fcr] >= 10\n[type] == weirdtype",
+			expected: []string{"unknown_property@1", "unknown_property@1", "unknown_property@2"},
+		},
+		{
+			name: "affixes",
+			content: "[type] == ring && [quality] == rare && [prefix] == 1190\n" +
+				"[type] == ring && [quality] == rare && [suffix] == 700\n" +
+				"[type] == ring && [quality] == magic && [prefix] == 1190 && [prefix] == 1191",
+			expected: []string{"contradiction@3"},
+		},
+		{
+			name: "flags other than ethereal",
+			content: "[name] == ring && [flag] != runeword\n" +
+				"[type] == armor && [flag] == ethereal\n" +
+				"[type] == armor && [flag] == ethereal && [quality] == unique",
+			expected: []string{"flag@1", "shadowed@3>test.nip:2"},
+		},
+		{
+			name: "maxquantity",
+			content: "[name] == berrune # # [maxquantity] == 2\n" +
+				"[type] == rune\n" +
+				"[name] == jahrune # # [maxquantity] == 1",
+			expected: []string{"maxquantity@2>test.nip:1", "shadowed@3>test.nip:2"},
+		},
+		{
+			name: "tier overlapping keep rule",
+			content: "[type] == ring && [quality] == unique\n" +
+				"[type] == ring && [quality] == rare # [fcr] >= 10 // [tier] == 5\n" +
+				"[type] == ring # [fcr] >= 10 && [tier] == 3",
+			expected: []string{"tier_overlap@3>test.nip:1"},
+		},
+	}
+
+	for _, tt := range tests {
+		findings := analyze(t, tt.content)
+		if len(findings) != len(tt.expected) {
+			t.Errorf("%s: expected %v, got %+v", tt.name, tt.expected, findings)
+			continue
+		}
+		for i, f := range findings {
+			got := f.Type + "@" + strconv.Itoa(f.Line)
+			if f.Related != "" {
+				got += ">" + f.Related
+			}
+			if got != tt.expected[i] {
+				t.Errorf("%s: expected finding %d to be %s, got %s (%s)", tt.name, i, tt.expected[i], got, f.Message)
+			}
+		}
+	}
+
+	if findings := analyze(t, "[name] == ring && [flag] != runeword"); len(findings) != 1 || findings[0].Severity != "warning" {
+		t.Errorf("Expected a warning for the runeword flag, got %+v", findings)
+	}
+}
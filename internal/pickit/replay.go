@@ -0,0 +1,98 @@
+package pickit
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/nip"
+)
+
+// ReplayDiff is the difference between two sets of rules over the same items, like the current pickit rules and an
+// edited version of them replayed over the droplog.
+type ReplayDiff struct {
+	Total      int
+	Unchanged  int
+	NowDropped []ReplayChange // Kept by the current rules, not by the candidate ones
+	NowKept    []ReplayChange // Kept by the candidate rules, not by the current ones
+}
+
+// ReplayChange is an item kept by only one of the sets of rules.
+type ReplayChange struct {
+	Index  int    // Position of the item in the replayed items
+	Before string // file:line of the current rule keeping the item, if any
+	After  string // file:line of the candidate rule keeping the item, if any
+}
+
+// CandidateRules returns the current rules with the given files (name => NIP content) replacing the loaded ones, or
+// only the given files when replaceAll is set. Rules are grouped by file sorted by name, the order nip.ReadDir loads
+// them once the edited files are saved, so the first matching rule is the same one the bot would use.
+func CandidateRules(current nip.Rules, files map[string]string, replaceAll bool) (nip.Rules, error) {
+	// Keyed by the lower case file name, file names are not case sensitive on Windows
+	byFile := make(map[string]nip.Rules)
+	names := make(map[string]string)
+	if !replaceAll {
+		for _, rule := range current {
+			name := RuleFileName(rule)
+			key := strings.ToLower(name)
+			byFile[key] = append(byFile[key], rule)
+			names[key] = name
+		}
+	}
+	for name, content := range files {
+		rules, err := ParseNIPContent(content, name)
+		if err != nil {
+			return nil, err
+		}
+		key := strings.ToLower(name)
+		byFile[key] = rules
+		// Saving over a loaded file keeps the name it has on disk
+		if _, found := names[key]; !found {
+			names[key] = name
+		}
+	}
+
+	keys := make([]string, 0, len(byFile))
+	for key := range byFile {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return names[keys[i]] < names[keys[j]] })
+
+	candidate := nip.Rules{}
+	for _, key := range keys {
+		candidate = append(candidate, byFile[key]...)
+	}
+
+	return candidate, nil
+}
+
+// ReplayItems evaluates every item with both sets of rules and reports the ones kept by only one of them, an item is
+// kept on a full or partial match, the same way it's picked up.
+func ReplayItems(current, candidate nip.Rules, items []data.Item) ReplayDiff {
+	diff := ReplayDiff{
+		Total:      len(items),
+		NowDropped: []ReplayChange{},
+		NowKept:    []ReplayChange{},
+	}
+	for i, itm := range items {
+		beforeRule, before := current.EvaluateAll(itm)
+		afterRule, after := candidate.EvaluateAll(itm)
+		keptBefore := before != nip.RuleResultNoMatch
+		keptAfter := after != nip.RuleResultNoMatch
+		switch {
+		case keptBefore == keptAfter:
+			diff.Unchanged++
+		case keptBefore:
+			diff.NowDropped = append(diff.NowDropped, ReplayChange{Index: i, Before: ruleLocation(beforeRule)})
+		default:
+			diff.NowKept = append(diff.NowKept, ReplayChange{Index: i, After: ruleLocation(afterRule)})
+		}
+	}
+
+	return diff
+}
+
+func ruleLocation(rule nip.Rule) string {
+	return fmt.Sprintf("%s:%d", RuleFileName(rule), rule.LineNumber)
+}
@@ -0,0 +1,92 @@
+package pickit
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/nip"
+)
+
+func fileRules(t *testing.T, file string, lines ...string) nip.Rules {
+	t.Helper()
+
+	rules := make(nip.Rules, 0, len(lines))
+	for i, l := range lines {
+		r, err := nip.NewRule(l, file, i+1)
+		if err != nil {
+			t.Fatalf("Error parsing rule %q: %v", l, err)
+		}
+		rules = append(rules, r)
+	}
+
+	return rules
+}
+
+func ruleLocations(rules nip.Rules) []string {
+	locations := make([]string, 0, len(rules))
+	for _, r := range rules {
+		locations = append(locations, ruleLocation(r))
+	}
+
+	return locations
+}
+
+func TestCandidateRulesFileOrder(t *testing.T) {
+	current := append(fileRules(t, `config\sorc\pickit\a.nip`, "[name] == berrune", "[name] == jahrune"),
+		fileRules(t, `config\sorc\pickit\c.nip`, "[type] == ring && [quality] == unique")...)
+
+	// The new file goes between the loaded ones and the edited one keeps its place, even with another case
+	candidate, err := CandidateRules(current, map[string]string{
+		"b.nip": "[name] == istrune",
+		"C.nip": "[type] == amulet && [quality] == unique\n[type] == ring && [quality] == unique",
+	}, false)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if want := []string{"a.nip:1", "a.nip:2", "b.nip:1", "C.nip:1", "C.nip:2"}; !slices.Equal(ruleLocations(candidate), want) {
+		t.Errorf("Expected %v, got %v", want, ruleLocations(candidate))
+	}
+
+	candidate, err = CandidateRules(current, map[string]string{"b.nip": "[name] == istrune"}, true)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if want := []string{"b.nip:1"}; !slices.Equal(ruleLocations(candidate), want) {
+		t.Errorf("Expected %v, got %v", want, ruleLocations(candidate))
+	}
+
+	if _, err = CandidateRules(current, map[string]string{"broken.nip": "[notaproperty] >= 1 # [fcr] =="}, false); err == nil {
+		t.Errorf("Expected error for broken rule")
+	}
+}
+
+func TestReplayItems(t *testing.T) {
+	items := make([]data.Item, 0, 3)
+	for _, spec := range []SimulatedItem{
+		{Name: "berrune"},
+		{Name: "ring", Quality: "unique"},
+		{Name: "amulet", Quality: "unique"},
+	} {
+		itm, err := BuildItem(spec)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		items = append(items, itm)
+	}
+
+	current := fileRules(t, "runes.nip", "[name] == berrune", "[type] == ring && [quality] == unique")
+	candidate := append(fileRules(t, "runes.nip", "[name] == berrune"),
+		fileRules(t, "uniques.nip", "[type] == amulet && [quality] == unique")...)
+
+	diff := ReplayItems(current, candidate, items)
+	if diff.Total != 3 || diff.Unchanged != 1 {
+		t.Errorf("Expected 3 items and 1 unchanged, got %d and %d", diff.Total, diff.Unchanged)
+	}
+	if want := []ReplayChange{{Index: 1, Before: "runes.nip:2"}}; !slices.Equal(diff.NowDropped, want) {
+		t.Errorf("Expected %v now dropped, got %v", want, diff.NowDropped)
+	}
+	if want := []ReplayChange{{Index: 2, After: "uniques.nip:1"}}; !slices.Equal(diff.NowKept, want) {
+		t.Errorf("Expected %v now kept, got %v", want, diff.NowKept)
+	}
+}
@@ -0,0 +1,412 @@
+package pickit
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/item"
+	"github.com/hectorgimenez/d2go/pkg/data/stat"
+	"github.com/hectorgimenez/d2go/pkg/nip"
+)
+
+var (
+	simFixedPropRegexp = regexp.MustCompile(`\[(type|quality|class|name|flag|color|prefix|suffix)]\s*(<=|<|>=|>|!=|==)\s*([a-z0-9]+)`)
+	simStatCondRegexp  = regexp.MustCompile(`\[([a-z0-9]+)]\s*(<=|<|>=|>|!=|==)\s*(-?[0-9]+)`)
+)
+
+// SimulatedItem describes a synthetic item to be evaluated against NIP rules without the game running
+type SimulatedItem struct {
+	Name         string         `json:"name"`         // Item database ID, NIP name or d2go base name (e.g. "unique_harlequincrest", "berrune", "shako")
+	Quality      string         `json:"quality"`      // NIP quality (normal, superior, magic, set, rare, unique, crafted), defaults to the database quality
+	Ethereal     bool           `json:"ethereal"`     // Whether the item is ethereal
+	Sockets      int            `json:"sockets"`      // Number of sockets
+	Unidentified bool           `json:"unidentified"` // Simulate an unidentified item, stat rules will be partial matches
+	Stats        map[string]int `json:"stats"`        // NIP stat property => value (e.g. "fcr": 20)
+}
+
+// RuleEvaluation is the result of evaluating a single NIP rule against a simulated item
+type RuleEvaluation struct {
+	File   string `json:"file"`   // NIP file the rule belongs to
+	Line   int    `json:"line"`   // Line number in the file
+	Rule   string `json:"rule"`   // Raw NIP line
+	Result string `json:"result"` // match, partial, nomatch, disabled or error
+	Reason string `json:"reason"` // Why the rule matched or failed
+}
+
+// ItemSimulation is the outcome of evaluating a simulated item against a full rule set
+type ItemSimulation struct {
+	Item        SimulatedItem    `json:"item"`
+	ItemName    string           `json:"itemName"`              // Resolved d2go item name
+	Result      string           `json:"result"`                // Result the bot would get: match, partial or nomatch
+	MatchedRule *RuleEvaluation  `json:"matchedRule,omitempty"` // Rule the bot would use, same as nip.Rules.EvaluateAll
+	Evaluations []RuleEvaluation `json:"evaluations"`           // Every rule, in evaluation order
+}
+
+var simQualities = map[string]item.Quality{
+	"lowquality": item.QualityLowQuality,
+	"normal":     item.QualityNormal,
+	"superior":   item.QualitySuperior,
+	"magic":      item.QualityMagic,
+	"set":        item.QualitySet,
+	"rare":       item.QualityRare,
+	"unique":     item.QualityUnique,
+	"crafted":    item.QualityCrafted,
+}
+
+// BuildItem creates a data.Item equivalent to the one the bot reads from memory, so it can be evaluated by nip.Rules
+func BuildItem(spec SimulatedItem) (data.Item, error) {
+	id, def, err := resolveItemID(spec.Name)
+	if err != nil {
+		return data.Item{}, err
+	}
+
+	quality := item.QualityNormal
+	if def != nil && len(def.Quality) > 0 {
+		quality = def.Quality[0]
+	}
+	if spec.Quality != "" {
+		q, found := simQualities[strings.ToLower(strings.TrimSpace(spec.Quality))]
+		if !found {
+			return data.Item{}, fmt.Errorf("unknown quality: %s", spec.Quality)
+		}
+		quality = q
+	}
+
+	itm := data.Item{
+		ID:         id,
+		Name:       item.GetNameByEnum(uint(id)),
+		Quality:    quality,
+		Ethereal:   spec.Ethereal,
+		Identified: !spec.Unidentified,
+	}
+
+	if spec.Sockets > 0 {
+		itm.HasSockets = true
+		itm.Stats = append(itm.Stats, stat.Data{ID: stat.NumSockets, Value: spec.Sockets})
+	}
+
+	for name, value := range spec.Stats {
+		alias, found := nip.StatAliases[strings.ToLower(strings.TrimSpace(name))]
+		if !found {
+			return data.Item{}, fmt.Errorf("unknown stat: %s", name)
+		}
+		layer := 0
+		if len(alias) > 1 {
+			layer = alias[1]
+		}
+		itm.Stats = append(itm.Stats, stat.Data{ID: stat.ID(alias[0]), Value: value, Layer: layer})
+	}
+
+	return itm, nil
+}
+
+// resolveItemID finds the d2go item ID for a name, it can be a d2go base name or an item from the editor database,
+// uniques and sets are resolved to their base item.
+func resolveItemID(name string) (int, *ItemDefinition, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return 0, nil, fmt.Errorf("item name is required")
+	}
+
+	if id := item.GetIDByName(ToNIPName(name)); id >= 0 {
+		return id, nil, nil
+	}
+
+	var def *ItemDefinition
+	if d, found := ItemDatabaseV2[name]; found {
+		def = &d
+	} else {
+		nipName := ToNIPName(name)
+		for _, d := range ItemDatabaseV2 {
+			if d.NIPName == nipName || strings.EqualFold(d.Name, name) {
+				d := d
+				def = &d
+				break
+			}
+		}
+	}
+	if def == nil {
+		return 0, nil, fmt.Errorf("unknown item: %s", name)
+	}
+
+	for _, candidate := range []string{def.BaseItem, def.NIPName, def.InternalName} {
+		if candidate == "" {
+			continue
+		}
+		if id := item.GetIDByName(candidate); id >= 0 {
+			return id, def, nil
+		}
+	}
+
+	return 0, nil, fmt.Errorf("item %s has no known base item", name)
+}
+
+// ParseNIPContent parses the content of a NIP file the same way nip.ParseNIPFile does, so edited files can be
+// evaluated before being saved to disk.
+func ParseNIPContent(content, fileName string) (nip.Rules, error) {
+	dummyItem := data.Item{
+		ID:      516,
+		Name:    "healingpotion",
+		Quality: item.QualityNormal,
+	}
+
+	rules := make(nip.Rules, 0)
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		rule, err := nip.NewRule(scanner.Text(), fileName, lineNumber)
+		if errors.Is(err, nip.ErrEmptyRule) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s file at line %d: %w", fileName, lineNumber, err)
+		}
+		if _, err = rule.Evaluate(dummyItem); err != nil {
+			return nil, fmt.Errorf("error testing rule on [%s:%d]: %w", fileName, lineNumber, err)
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, scanner.Err()
+}
+
+// RuleFileName returns the base name of the file a rule was loaded from, rules read by nip.ReadDir keep the full
+// path, using the OS separator.
+func RuleFileName(rule nip.Rule) string {
+	name := rule.Filename
+	if i := strings.LastIndexAny(name, `/\`); i >= 0 {
+		name = name[i+1:]
+	}
+
+	return name
+}
+
+// SimulateItem evaluates the item against every rule the same way the bot does, explaining the result of each rule
+func SimulateItem(rules nip.Rules, spec SimulatedItem) (ItemSimulation, error) {
+	itm, err := BuildItem(spec)
+	if err != nil {
+		return ItemSimulation{}, err
+	}
+
+	sim := ItemSimulation{
+		Item:        spec,
+		ItemName:    string(itm.Name),
+		Result:      "nomatch",
+		Evaluations: make([]RuleEvaluation, 0, len(rules)),
+	}
+
+	matched := -1
+	for _, rule := range rules {
+		ev := EvaluateRule(rule, itm)
+		sim.Evaluations = append(sim.Evaluations, ev)
+
+		// Same precedence as nip.Rules.EvaluateAll: first full match wins, otherwise the last partial one
+		if sim.Result == "match" {
+			continue
+		}
+		if ev.Result == "match" || ev.Result == "partial" {
+			sim.Result = ev.Result
+			matched = len(sim.Evaluations) - 1
+		}
+	}
+
+	if matched >= 0 {
+		sim.MatchedRule = &sim.Evaluations[matched]
+	}
+
+	return sim, nil
+}
+
+// SimulateDatabase evaluates every item of the editor database against the rules, rolling the item stats at their
+// maximum value, and returns the ones that would be kept.
+func SimulateDatabase(ruleID string, rules nip.Rules) SimulationResult {
+	result := SimulationResult{
+		RuleID:      ruleID,
+		Matches:     []ItemMatch{},
+		Misses:      []ItemMatch{},
+		Performance: "Good",
+		Suggestions: []string{},
+	}
+
+	ids := make([]string, 0, len(ItemDatabaseV2))
+	for id := range ItemDatabaseV2 {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		def := ItemDatabaseV2[id]
+		spec := SimulatedItem{Name: def.ID, Stats: make(map[string]int)}
+		stats := make(map[string]interface{})
+		for _, st := range def.AvailableStats {
+			prop := strings.Trim(st.NipProperty, "[]")
+			if _, found := nip.StatAliases[prop]; found {
+				spec.Stats[prop] = int(st.MaxValue)
+				stats[prop] = int(st.MaxValue)
+			}
+		}
+
+		sim, err := SimulateItem(rules, spec)
+		if err != nil || sim.MatchedRule == nil {
+			continue
+		}
+
+		result.MatchCount++
+		result.Matches = append(result.Matches, ItemMatch{
+			ItemName:  def.Name,
+			ImageIcon: def.ImageIcon,
+			Matched:   sim.Result == "match",
+			Stats:     stats,
+			Reason:    fmt.Sprintf("%s:%d %s", sim.MatchedRule.File, sim.MatchedRule.Line, sim.MatchedRule.Reason),
+		})
+	}
+
+	if result.MatchCount == 0 {
+		result.Suggestions = append(result.Suggestions, "No items matched. Check your item name and conditions.")
+	} else if result.MatchCount > 10 {
+		result.Suggestions = append(result.Suggestions, "Rule matches many items. Consider adding quality or stat filters.")
+	}
+
+	return result
+}
+
+// EvaluateRule evaluates a single rule against the item and explains the result
+func EvaluateRule(rule nip.Rule, itm data.Item) RuleEvaluation {
+	ev := RuleEvaluation{
+		File: rule.Filename,
+		Line: rule.LineNumber,
+		Rule: strings.TrimSpace(rule.RawLine),
+	}
+
+	if !rule.Enabled {
+		ev.Result = "disabled"
+		ev.Reason = "Rule is disabled"
+		return ev
+	}
+
+	res, err := rule.Evaluate(itm)
+	if err != nil {
+		ev.Result = "error"
+		ev.Reason = err.Error()
+		return ev
+	}
+
+	switch res {
+	case nip.RuleResultFullMatch:
+		ev.Result = "match"
+		ev.Reason = "All conditions match"
+	case nip.RuleResultPartial:
+		ev.Result = "partial"
+		ev.Reason = "Item properties match, stats can not be checked until the item is identified"
+	default:
+		ev.Result = "nomatch"
+		ev.Reason = explainNoMatch(rule, itm)
+	}
+
+	return ev
+}
+
+// explainNoMatch finds out which conditions of the rule are not met by the item, it checks every condition on its
+// own, so for rules using || it may list conditions that are not relevant by themselves.
+func explainNoMatch(rule nip.Rule, itm data.Item) string {
+	line := strings.ToLower(strings.Split(rule.RawLine, "//")[0])
+	parts := strings.Split(line, "#")
+
+	var failed []string
+	for _, cond := range simFixedPropRegexp.FindAllStringSubmatch(parts[0], -1) {
+		single, err := nip.NewRule(cond[0], rule.Filename, rule.LineNumber)
+		if err != nil {
+			continue
+		}
+		if res, err := single.Evaluate(itm); err == nil && res == nip.RuleResultNoMatch {
+			failed = append(failed, fmt.Sprintf("%s (item %s is %s)", cond[0], cond[1], describeItemProperty(cond[1], itm)))
+		}
+	}
+	if len(failed) > 0 {
+		return "Item properties don't match: " + strings.Join(failed, ", ")
+	}
+
+	if len(parts) < 2 {
+		return "Item properties don't match"
+	}
+
+	for _, cond := range simStatCondRegexp.FindAllStringSubmatch(parts[1], -1) {
+		expected, err := strconv.Atoi(cond[3])
+		if err != nil {
+			continue
+		}
+		value, found := itemStatValue(cond[1], itm)
+		if !compareStat(value, cond[2], expected) {
+			if found {
+				failed = append(failed, fmt.Sprintf("%s (item has %d)", cond[0], value))
+			} else {
+				failed = append(failed, fmt.Sprintf("%s (item doesn't have it)", cond[0]))
+			}
+		}
+	}
+	if len(failed) > 0 {
+		return "Stats don't match: " + strings.Join(failed, ", ")
+	}
+
+	return "Stat expression evaluates to false"
+}
+
+func describeItemProperty(prop string, itm data.Item) string {
+	switch prop {
+	case "name":
+		return string(itm.Name)
+	case "quality":
+		return strings.ToLower(itm.Quality.ToString())
+	case "type":
+		return itm.Type().Name
+	case "flag":
+		if itm.Ethereal {
+			return "ethereal"
+		}
+		return "not ethereal"
+	case "class":
+		return fmt.Sprintf("%d", itm.Desc().Tier())
+	}
+
+	return "different"
+}
+
+func itemStatValue(name string, itm data.Item) (int, bool) {
+	alias, found := nip.StatAliases[name]
+	if !found {
+		return 0, false
+	}
+	layer := 0
+	if len(alias) > 1 {
+		layer = alias[1]
+	}
+
+	st, found := itm.FindStat(stat.ID(alias[0]), layer)
+	return st.Value, found
+}
+
+func compareStat(value int, operator string, expected int) bool {
+	switch operator {
+	case "==":
+		return value == expected
+	case "!=":
+		return value != expected
+	case ">=":
+		return value >= expected
+	case "<=":
+		return value <= expected
+	case ">":
+		return value > expected
+	case "<":
+		return value < expected
+	}
+
+	return true
+}
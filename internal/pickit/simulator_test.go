@@ -0,0 +1,116 @@
+package pickit
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hectorgimenez/d2go/pkg/nip"
+)
+
+func mustRules(t *testing.T, lines ...string) nip.Rules {
+	t.Helper()
+
+	rules := make(nip.Rules, 0, len(lines))
+	for i, l := range lines {
+		r, err := nip.NewRule(l, "test.nip", i+1)
+		if err != nil {
+			t.Fatalf("Error parsing rule %q: %v", l, err)
+		}
+		rules = append(rules, r)
+	}
+
+	return rules
+}
+
+func TestSimulateItemMatchesStatRule(t *testing.T) {
+	rules := mustRules(t,
+		"[name] == shako && [quality] == unique # [defense] >= 141",
+		"[type] == ring && [quality] == unique # [fcr] == 10",
+	)
+
+	sim, err := SimulateItem(rules, SimulatedItem{Name: "ring", Quality: "unique", Stats: map[string]int{"fcr": 10}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if sim.Result != "match" || sim.MatchedRule == nil || sim.MatchedRule.Line != 2 {
+		t.Fatalf("Expected match on line 2, got %s (%+v)", sim.Result, sim.MatchedRule)
+	}
+	if sim.Evaluations[0].Result != "nomatch" || !strings.Contains(sim.Evaluations[0].Reason, "[name] == shako") {
+		t.Errorf("Expected name mismatch on line 1, got %+v", sim.Evaluations[0])
+	}
+}
+
+func TestSimulateItemExplainsStatMismatch(t *testing.T) {
+	rules := mustRules(t, "[type] == ring && [quality] == rare # [fcr] >= 10 && [strength] >= 5")
+
+	sim, err := SimulateItem(rules, SimulatedItem{Name: "ring", Quality: "rare", Stats: map[string]int{"fcr": 10}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if sim.Result != "nomatch" {
+		t.Fatalf("Expected nomatch, got %s", sim.Result)
+	}
+	if reason := sim.Evaluations[0].Reason; !strings.Contains(reason, "[strength] >= 5") || strings.Contains(reason, "[fcr]") {
+		t.Errorf("Expected only strength to be reported, got %q", reason)
+	}
+}
+
+func TestSimulateItemUnidentifiedIsPartial(t *testing.T) {
+	rules := mustRules(t, "[type] == ring && [quality] == rare # [fcr] >= 10")
+
+	sim, err := SimulateItem(rules, SimulatedItem{Name: "ring", Quality: "rare", Unidentified: true})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if sim.Result != "partial" {
+		t.Errorf("Expected partial match, got %s", sim.Result)
+	}
+}
+
+func TestSimulateItemEtherealAndSockets(t *testing.T) {
+	rules := mustRules(t, "[name] == thresher && [flag] == ethereal && [quality] <= superior # [sockets] == 4")
+
+	sim, err := SimulateItem(rules, SimulatedItem{Name: "thresher", Ethereal: true, Sockets: 4})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if sim.Result != "match" {
+		t.Errorf("Expected match, got %s: %s", sim.Result, sim.Evaluations[0].Reason)
+	}
+
+	sim, _ = SimulateItem(rules, SimulatedItem{Name: "thresher", Sockets: 4})
+	if sim.Result != "nomatch" || !strings.Contains(sim.Evaluations[0].Reason, "[flag] == ethereal") {
+		t.Errorf("Expected flag mismatch, got %s: %s", sim.Result, sim.Evaluations[0].Reason)
+	}
+}
+
+func TestBuildItemResolvesDatabaseItems(t *testing.T) {
+	itm, err := BuildItem(SimulatedItem{Name: "rune_berrune"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if itm.Name != "BerRune" {
+		t.Errorf("Expected BerRune, got %s", itm.Name)
+	}
+
+	if _, err = BuildItem(SimulatedItem{Name: "ring", Stats: map[string]int{"notastat": 1}}); err == nil {
+		t.Errorf("Expected error for unknown stat")
+	}
+}
+
+func TestParseNIPContent(t *testing.T) {
+	rules, err := ParseNIPContent("// comment\n\n[name] == berrune\n[type] == ring && [quality] == unique // soj\n", "runes.nip")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(rules) != 2 || rules[0].LineNumber != 3 || rules[1].LineNumber != 4 {
+		t.Fatalf("Expected rules on lines 3 and 4, got %+v", rules)
+	}
+	if RuleFileName(nip.Rule{Filename: `config\char\pickit\runes.nip`}) != "runes.nip" {
+		t.Errorf("Expected runes.nip file name")
+	}
+
+	if _, err = ParseNIPContent("[name] == berrune\n[notaproperty] >= 1 # [fcr] ==\n", "broken.nip"); err == nil {
+		t.Errorf("Expected error for broken rule")
+	}
+}
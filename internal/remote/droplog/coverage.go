@@ -0,0 +1,159 @@
+package droplog
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hectorgimenez/d2go/pkg/nip"
+)
+
+// RuleCoverage is what a single NIP rule did over the report period.
+type RuleCoverage struct {
+	File        string     `json:"file"`
+	Line        int        `json:"line"`
+	Rule        string     `json:"rule"`
+	Tier        bool       `json:"tier"`    // tier or merctier rule, only stashes upgrades
+	Stashed     int        `json:"stashed"` // items stashed because of this rule
+	Sold        int        `json:"sold"`    // items picked up because of this rule and sold later
+	Dropped     int        `json:"dropped"` // items kept by this rule and dropped later, usually because of maxquantity
+	LastStashed *time.Time `json:"lastStashed,omitempty"`
+}
+
+// Location returns the rule as file:line.
+func (c RuleCoverage) Location() string {
+	return fmt.Sprintf("%s:%d", c.File, c.Line)
+}
+
+// CoverageReport tells which rules keep items and which ones never do, so big pickit files can be trimmed with data.
+type CoverageReport struct {
+	From          time.Time      `json:"from"`
+	To            time.Time      `json:"to"`
+	Stashed       int            `json:"stashed"`       // items stashed in the period
+	Disposed      int            `json:"disposed"`      // items sold or dropped in the period
+	UnknownRules  int            `json:"unknownRules"`  // items recorded for rules that are not loaded anymore
+	Rules         []RuleCoverage `json:"rules"`         // every rule, in load order
+	NeverFired    []string       `json:"neverFired"`    // file:line of the rules that didn't stash anything
+	DisposedRules []string       `json:"disposedRules"` // file:line of the rules keeping items that were sold or dropped
+}
+
+// Coverage matches the stashed and disposed records between from and to with the rules that kept them, a zero from or
+// to means no limit. Records are matched by file name and line, rules edited after the items were recorded may be
+// reported wrongly.
+func Coverage(rules nip.Rules, stashed, disposed []Record, from, to time.Time) CoverageReport {
+	report := CoverageReport{
+		From:          from,
+		To:            to,
+		Rules:         make([]RuleCoverage, 0, len(rules)),
+		NeverFired:    []string{},
+		DisposedRules: []string{},
+	}
+
+	index := make(map[string]int, len(rules))
+	for _, rule := range rules {
+		cov := RuleCoverage{
+			File: fileName(rule.Filename),
+			Line: rule.LineNumber,
+			Rule: strings.TrimSpace(rule.RawLine),
+			Tier: rule.Tier() > 0 || rule.MercTier() > 0,
+		}
+		index[ruleKey(cov.File, cov.Line)] = len(report.Rules)
+		report.Rules = append(report.Rules, cov)
+	}
+
+	inPeriod := func(rec Record) bool {
+		return (from.IsZero() || !rec.Time.Before(from)) && (to.IsZero() || !rec.Time.After(to))
+	}
+	find := func(rec Record) *RuleCoverage {
+		file, line, ok := parseRuleFile(rec.Drop.RuleFile)
+		if !ok {
+			return nil
+		}
+		if i, found := index[ruleKey(file, line)]; found {
+			return &report.Rules[i]
+		}
+		return nil
+	}
+
+	for _, rec := range stashed {
+		if !inPeriod(rec) {
+			continue
+		}
+		report.Stashed++
+		cov := find(rec)
+		if cov == nil {
+			report.UnknownRules++
+			continue
+		}
+		cov.Stashed++
+		if cov.LastStashed == nil || rec.Time.After(*cov.LastStashed) {
+			t := rec.Time
+			cov.LastStashed = &t
+		}
+	}
+
+	for _, rec := range disposed {
+		if !inPeriod(rec) {
+			continue
+		}
+		report.Disposed++
+		cov := find(rec)
+		if cov == nil {
+			report.UnknownRules++
+			continue
+		}
+		if rec.Disposal == "sold" {
+			cov.Sold++
+		} else {
+			cov.Dropped++
+		}
+	}
+
+	disposing := make([]RuleCoverage, 0)
+	for _, cov := range report.Rules {
+		if cov.Stashed == 0 {
+			report.NeverFired = append(report.NeverFired, cov.Location())
+		}
+		if cov.Sold > 0 || cov.Dropped > 0 {
+			disposing = append(disposing, cov)
+		}
+	}
+	// Worst offenders first
+	sort.SliceStable(disposing, func(i, j int) bool {
+		return disposing[i].Sold+disposing[i].Dropped > disposing[j].Sold+disposing[j].Dropped
+	})
+	for _, cov := range disposing {
+		report.DisposedRules = append(report.DisposedRules, cov.Location())
+	}
+
+	return report
+}
+
+// parseRuleFile splits the file:line recorded for a drop, the file may be a full path.
+func parseRuleFile(ruleFile string) (string, int, bool) {
+	i := strings.LastIndex(ruleFile, ":")
+	if i < 0 {
+		return "", 0, false
+	}
+	line, err := strconv.Atoi(ruleFile[i+1:])
+	if err != nil {
+		return "", 0, false
+	}
+
+	return fileName(ruleFile[:i]), line, true
+}
+
+// fileName returns the base name of a rule file, paths may use either separator.
+func fileName(path string) string {
+	if i := strings.LastIndexAny(path, `/\`); i >= 0 {
+		return path[i+1:]
+	}
+
+	return path
+}
+
+func ruleKey(file string, line int) string {
+	return strings.ToLower(fmt.Sprintf("%s:%d", file, line))
+}
@@ -0,0 +1,71 @@
+package droplog
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/nip"
+)
+
+func TestCoverage(t *testing.T) {
+	rules := nip.Rules{}
+	for i, line := range []string{
+		"[type] == ring && [quality] == unique",
+		"[name] == berrune # # [maxquantity] == 1",
+		"[type] == amulet && [quality] == rare # [fcr] >= 10",
+		"[type] == circlet && [quality] == rare # [tier] == 10",
+	} {
+		rule, err := nip.NewRule(line, `C:\koolo\config\sorc\pickit\general.nip`, i+1)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		rules = append(rules, rule)
+	}
+
+	day := func(d int) time.Time { return time.Date(2025, 1, d, 12, 0, 0, 0, time.UTC) }
+	record := func(d int, ruleFile, disposal string) Record {
+		return Record{Time: day(d), Supervisor: "sorc", Drop: data.Drop{RuleFile: ruleFile}, Disposal: disposal}
+	}
+
+	stashed := []Record{
+		record(1, `C:\koolo\config\sorc\pickit\general.nip:1`, ""),
+		record(5, `C:\koolo\config\sorc\pickit\general.nip:1`, ""),
+		record(6, `C:\koolo\config\sorc\pickit\general.nip:2`, ""),
+		record(7, "config/sorc/pickit/General.nip:4", ""),
+		record(8, "old.nip:12", ""),
+		record(20, `C:\koolo\config\sorc\pickit\general.nip:3`, ""),
+	}
+	disposed := []Record{
+		record(6, `C:\koolo\config\sorc\pickit\general.nip:2`, "dropped"),
+		record(6, `C:\koolo\config\sorc\pickit\general.nip:2`, "dropped"),
+		record(7, `C:\koolo\config\sorc\pickit\general.nip:3`, "sold"),
+	}
+
+	report := Coverage(rules, stashed, disposed, day(2), day(10))
+	if report.Stashed != 4 || report.Disposed != 3 || report.UnknownRules != 1 {
+		t.Errorf("Unexpected totals %+v", report)
+	}
+
+	expected := []struct{ stashed, sold, dropped int }{{1, 0, 0}, {1, 0, 2}, {0, 1, 0}, {1, 0, 0}}
+	for i, e := range expected {
+		cov := report.Rules[i]
+		if cov.Stashed != e.stashed || cov.Sold != e.sold || cov.Dropped != e.dropped {
+			t.Errorf("Unexpected coverage for rule %d: %+v", i+1, cov)
+		}
+	}
+	if !report.Rules[0].LastStashed.Equal(day(5)) || !report.Rules[3].Tier {
+		t.Errorf("Unexpected rule details %+v", report.Rules)
+	}
+
+	if len(report.NeverFired) != 1 || report.NeverFired[0] != "general.nip:3" {
+		t.Errorf("Unexpected never fired rules %v", report.NeverFired)
+	}
+	if len(report.DisposedRules) != 2 || report.DisposedRules[0] != "general.nip:2" || report.DisposedRules[1] != "general.nip:3" {
+		t.Errorf("Unexpected disposed rules %v", report.DisposedRules)
+	}
+
+	if all := Coverage(rules, stashed, disposed, time.Time{}, time.Time{}); all.Stashed != len(stashed) {
+		t.Errorf("Expected every record without a period, got %d", all.Stashed)
+	}
+}
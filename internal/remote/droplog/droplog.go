@@ -24,6 +24,7 @@ type Record struct {
 	Character  string    `json:"character"` // in-game character name
 	Profile    string    `json:"profile"`   // config folder name
 	Drop       data.Drop `json:"drop"`
+	Disposal   string    `json:"disposal,omitempty"` // sold or dropped, only set for disposed items
 }
 
 type Writer struct {
@@ -35,10 +36,20 @@ func NewWriter(logDir string, logger *slog.Logger) *Writer {
 	return &Writer{logDir: logDir, logger: logger}
 }
 
-// Handle subscribes to the event bus and persists ItemStashedEvent to a daily JSONL file.
+// Handle subscribes to the event bus and persists ItemStashedEvent to a daily JSONL file, items kept by a pickit rule
+// and later sold or dropped (ItemDisposedEvent) go to their own daily file.
 func (w *Writer) Handle(_ context.Context, e event.Event) error {
-	ist, ok := e.(event.ItemStashedEvent)
-	if !ok {
+	prefix := "droplog"
+	var drop data.Drop
+	disposal := ""
+	switch evt := e.(type) {
+	case event.ItemStashedEvent:
+		drop = evt.Item
+	case event.ItemDisposedEvent:
+		prefix = "disposed"
+		drop = evt.Item
+		disposal = string(evt.Disposal)
+	default:
 		return nil
 	}
 
@@ -46,7 +57,7 @@ func (w *Writer) Handle(_ context.Context, e event.Event) error {
 	sup := e.Supervisor()
 	charName := ""
 	profile := ""
-	if cfg, found := config.GetCharacter(sup); found && cfg != nil { 
+	if cfg, found := config.GetCharacter(sup); found && cfg != nil {
 		charName = cfg.CharacterName
 		profile = cfg.ConfigFolderName
 	}
@@ -56,7 +67,8 @@ func (w *Writer) Handle(_ context.Context, e event.Event) error {
 		Supervisor: sup,
 		Character:  charName,
 		Profile:    profile,
-		Drop:       ist.Item,
+		Drop:       drop,
+		Disposal:   disposal,
 	}
 
 	// Ensure directory exists
@@ -65,8 +77,8 @@ func (w *Writer) Handle(_ context.Context, e event.Event) error {
 		return nil // don't break the bot because of logging errors
 	}
 
-	// Daily rotation by date
-	file := filepath.Join(w.logDir, fmt.Sprintf("droplog-%s.jsonl", time.Now().Format("2006-01-02")))
+	// Daily rotation by the event date, so replayed events land in the file of the day they happened
+	file := filepath.Join(w.logDir, fmt.Sprintf("%s-%s.jsonl", prefix, rec.Time.Format("2006-01-02")))
 	f, err := os.OpenFile(file, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
 	if err != nil {
 		w.logger.Error("Failed to open droplog file", slog.Any("error", err), slog.String("file", file))
@@ -88,7 +100,16 @@ func (w *Writer) Handle(_ context.Context, e event.Event) error {
 
 // ReadAll scans the log directory for droplog-*.jsonl files, parses them, and returns all records.
 func ReadAll(logDir string) ([]Record, error) {
-	files, err := filepath.Glob(filepath.Join(logDir, "droplog-*.jsonl"))
+	return readRecords(logDir, "droplog")
+}
+
+// ReadDisposed returns the records of every item kept by a pickit rule that was later sold or dropped.
+func ReadDisposed(logDir string) ([]Record, error) {
+	return readRecords(logDir, "disposed")
+}
+
+func readRecords(logDir, prefix string) ([]Record, error) {
+	files, err := filepath.Glob(filepath.Join(logDir, prefix+"-*.jsonl"))
 	if err != nil {
 		return nil, err
 	}
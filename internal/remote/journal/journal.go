@@ -0,0 +1,402 @@
+package journal
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hectorgimenez/koolo/internal/config"
+	"github.com/hectorgimenez/koolo/internal/event"
+)
+
+const (
+	filePrefix     = "journal-"
+	screenshotsDir = "screenshots"
+
+	DefaultMaxFileSize   = 50 * 1024 * 1024
+	DefaultRetentionDays = 14
+)
+
+// Dir returns the folder where the event journal is stored, next to the droplogs.
+func Dir() string {
+	base := config.Koolo.LogSaveDirectory
+	if base == "" {
+		base = "logs"
+	}
+
+	return filepath.Join(base, "journal")
+}
+
+// Options tunes the journal size on disk, zero values use the defaults.
+type Options struct {
+	MaxFileSize   int64 // bytes, a new file is started for the same day when the current one is bigger
+	RetentionDays int   // journal files and screenshots older than this are removed, negative keeps everything
+	Screenshots   bool  // save the event screenshots, referenced from the records
+}
+
+// Writer appends every event to a daily JSONL journal, so it can be replayed later to rebuild the other logs or to
+// reproduce notification bugs without running the game.
+type Writer struct {
+	dir    string
+	opts   Options
+	logger *slog.Logger
+
+	mu      sync.Mutex
+	day     string
+	part    int
+	cleaned string
+}
+
+func NewWriter(dir string, opts Options, logger *slog.Logger) *Writer {
+	if opts.MaxFileSize <= 0 {
+		opts.MaxFileSize = DefaultMaxFileSize
+	}
+	if opts.RetentionDays == 0 {
+		opts.RetentionDays = DefaultRetentionDays
+	}
+
+	return &Writer{dir: dir, opts: opts, logger: logger}
+}
+
+// Handle subscribes to the event bus and appends the event to the journal.
+func (w *Writer) Handle(_ context.Context, e event.Event) error {
+	rec, err := event.NewRecord(e)
+	if err != nil {
+		w.logger.Debug("Event not journaled", slog.Any("error", err))
+		return nil
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if err = os.MkdirAll(w.dir, 0o755); err != nil {
+		w.logger.Error("Failed to create journal directory", slog.Any("error", err), slog.String("dir", w.dir))
+		return nil // don't break the bot because of logging errors
+	}
+
+	if w.opts.Screenshots && e.Image() != nil {
+		rec.Screenshot = w.saveScreenshot(e)
+	}
+
+	enc, err := json.Marshal(rec)
+	if err != nil {
+		w.logger.Error("Failed to encode journal record", slog.Any("error", err))
+		return nil
+	}
+
+	file := w.currentFile(e.OccurredAt())
+	f, err := os.OpenFile(file, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		w.logger.Error("Failed to open journal file", slog.Any("error", err), slog.String("file", file))
+		return nil
+	}
+	defer f.Close()
+
+	if _, err = f.Write(append(enc, '\n')); err != nil {
+		w.logger.Error("Failed to write journal record", slog.Any("error", err))
+	}
+
+	return nil
+}
+
+// currentFile returns the file for the event day, starting a new part when the current one reached the max size.
+func (w *Writer) currentFile(t time.Time) string {
+	day := t.Format("2006-01-02")
+	if day != w.day {
+		w.day = day
+		w.part = lastPart(w.dir, day)
+		w.cleanup(t)
+	}
+
+	file := partFile(w.dir, w.day, w.part)
+	if info, err := os.Stat(file); err == nil && info.Size() >= w.opts.MaxFileSize {
+		w.part++
+		file = partFile(w.dir, w.day, w.part)
+	}
+
+	return file
+}
+
+func (w *Writer) saveScreenshot(e event.Event) string {
+	name := fmt.Sprintf("%s-%s.jpeg", e.OccurredAt().Format("2006-01-02_15-04-05.000"), safeName(e.Supervisor()))
+	if err := os.MkdirAll(filepath.Join(w.dir, screenshotsDir), 0o755); err != nil {
+		w.logger.Error("Failed to create journal screenshots directory", slog.Any("error", err))
+		return ""
+	}
+
+	f, err := os.Create(filepath.Join(w.dir, screenshotsDir, name))
+	if err != nil {
+		w.logger.Error("Failed to save journal screenshot", slog.Any("error", err))
+		return ""
+	}
+	defer f.Close()
+
+	if err = jpeg.Encode(f, e.Image(), &jpeg.Options{Quality: 80}); err != nil {
+		w.logger.Error("Failed to save journal screenshot", slog.Any("error", err))
+		return ""
+	}
+
+	return name
+}
+
+// cleanup removes the journal files and screenshots older than the retention, once per day.
+func (w *Writer) cleanup(now time.Time) {
+	if w.opts.RetentionDays < 0 || w.cleaned == w.day {
+		return
+	}
+	w.cleaned = w.day
+
+	limit := now.AddDate(0, 0, -w.opts.RetentionDays).Format("2006-01-02")
+	files, _ := filepath.Glob(filepath.Join(w.dir, filePrefix+"*.jsonl"))
+	screenshots, _ := filepath.Glob(filepath.Join(w.dir, screenshotsDir, "*.jpeg"))
+	for _, file := range files {
+		if day, _, ok := parseFileName(filepath.Base(file)); ok && day < limit {
+			os.Remove(file)
+		}
+	}
+	for _, file := range screenshots {
+		if name := filepath.Base(file); len(name) >= 10 && name[:10] < limit {
+			os.Remove(file)
+		}
+	}
+}
+
+// Filter narrows down the replayed records, zero values match everything.
+type Filter struct {
+	Supervisor string
+	Types      []string // event type names, like ItemStashedEvent
+	From       time.Time
+	To         time.Time
+}
+
+func (f Filter) matches(rec event.Record) bool {
+	if f.Supervisor != "" && !strings.EqualFold(f.Supervisor, rec.Supervisor) {
+		return false
+	}
+	if len(f.Types) > 0 && !containsFold(f.Types, rec.Type) {
+		return false
+	}
+	if !f.From.IsZero() && rec.OccurredAt.Before(f.From) {
+		return false
+	}
+
+	return f.To.IsZero() || !rec.OccurredAt.After(f.To)
+}
+
+// Read returns the journal records matching the filter, oldest first. A missing folder is not an error.
+func Read(dir string, f Filter) ([]event.Record, error) {
+	files, err := filepath.Glob(filepath.Join(dir, filePrefix+"*.jsonl"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(files, func(i, j int) bool {
+		di, pi, _ := parseFileName(filepath.Base(files[i]))
+		dj, pj, _ := parseFileName(filepath.Base(files[j]))
+		if di != dj {
+			return di < dj
+		}
+		return pi < pj
+	})
+
+	records := make([]event.Record, 0)
+	for _, file := range files {
+		day, _, ok := parseFileName(filepath.Base(file))
+		if !ok || !dayInRange(day, f) {
+			continue
+		}
+
+		fileRecords, err := readFile(file)
+		if err != nil {
+			return nil, err
+		}
+		for _, rec := range fileRecords {
+			if f.matches(rec) {
+				records = append(records, rec)
+			}
+		}
+	}
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].OccurredAt.Before(records[j].OccurredAt)
+	})
+
+	return records, nil
+}
+
+func readFile(file string) ([]event.Record, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	records := make([]event.Record, 0)
+	r := bufio.NewReader(f)
+	for {
+		line, err := r.ReadBytes('\n')
+		if len(strings.TrimSpace(string(line))) > 0 {
+			var rec event.Record
+			// Skip lines that can't be parsed, like the last one after a crash
+			if jsonErr := json.Unmarshal(line, &rec); jsonErr == nil {
+				records = append(records, rec)
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return records, nil
+}
+
+// Load rebuilds the event of a record read from dir, including its screenshot when it was saved.
+func Load(dir string, rec event.Record) (event.Event, error) {
+	var img image.Image
+	if rec.Screenshot != "" {
+		f, err := os.Open(filepath.Join(dir, screenshotsDir, filepath.Base(rec.Screenshot)))
+		if err == nil {
+			img, err = jpeg.Decode(f)
+			f.Close()
+		}
+		// A missing screenshot is fine, it may have been removed by the retention
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("error loading screenshot %s: %w", rec.Screenshot, err)
+		}
+	}
+
+	return rec.Event(img)
+}
+
+// ReplayOptions tunes how events are fed to the handlers.
+type ReplayOptions struct {
+	Delay       time.Duration // wait between events, useful when replaying to rate limited services
+	StopOnError bool
+}
+
+// ReplayResult counts what happened during a replay.
+type ReplayResult struct {
+	Events int // events sent to the handlers
+	Errors int // handler calls that returned an error
+}
+
+// Replay feeds the records to every handler in order, like the event bus would but synchronously, so the handlers
+// are done when it returns.
+func Replay(ctx context.Context, dir string, records []event.Record, opts ReplayOptions, handlers ...event.Handler) (ReplayResult, error) {
+	result := ReplayResult{}
+	for i, rec := range records {
+		if i > 0 && opts.Delay > 0 {
+			select {
+			case <-time.After(opts.Delay):
+			case <-ctx.Done():
+				return result, ctx.Err()
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		e, err := Load(dir, rec)
+		if err != nil {
+			return result, err
+		}
+
+		result.Events++
+		for _, h := range handlers {
+			if err = h(ctx, e); err != nil {
+				result.Errors++
+				if opts.StopOnError {
+					return result, fmt.Errorf("error replaying %s from %s: %w", rec.Type, rec.OccurredAt.Format(time.RFC3339), err)
+				}
+			}
+		}
+	}
+
+	return result, nil
+}
+
+func partFile(dir, day string, part int) string {
+	if part == 0 {
+		return filepath.Join(dir, fmt.Sprintf("%s%s.jsonl", filePrefix, day))
+	}
+
+	return filepath.Join(dir, fmt.Sprintf("%s%s.%d.jsonl", filePrefix, day, part))
+}
+
+// parseFileName returns the day and part of a journal file name, like journal-2025-01-02.1.jsonl
+func parseFileName(name string) (string, int, bool) {
+	if !strings.HasPrefix(name, filePrefix) || !strings.HasSuffix(name, ".jsonl") {
+		return "", 0, false
+	}
+	name = strings.TrimSuffix(strings.TrimPrefix(name, filePrefix), ".jsonl")
+
+	day, partStr, hasPart := strings.Cut(name, ".")
+	if _, err := time.Parse("2006-01-02", day); err != nil {
+		return "", 0, false
+	}
+	part := 0
+	if hasPart {
+		if _, err := fmt.Sscanf(partStr, "%d", &part); err != nil {
+			return "", 0, false
+		}
+	}
+
+	return day, part, true
+}
+
+// lastPart returns the last part already written for the day, so a restart keeps appending to it.
+func lastPart(dir, day string) int {
+	last := 0
+	files, _ := filepath.Glob(filepath.Join(dir, filePrefix+day+"*.jsonl"))
+	for _, file := range files {
+		if d, part, ok := parseFileName(filepath.Base(file)); ok && d == day && part > last {
+			last = part
+		}
+	}
+
+	return last
+}
+
+// dayInRange skips whole files outside the filter period, records are written in the file of their local day.
+func dayInRange(day string, f Filter) bool {
+	if !f.From.IsZero() && day < f.From.AddDate(0, 0, -1).Format("2006-01-02") {
+		return false
+	}
+
+	return f.To.IsZero() || day <= f.To.AddDate(0, 0, 1).Format("2006-01-02")
+}
+
+func containsFold(values []string, s string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, s) || strings.EqualFold(v+"Event", s) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func safeName(s string) string {
+	if s == "" {
+		return "koolo"
+	}
+
+	return strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == ':' || r == '*' || r == '?' || r == '"' || r == '<' || r == '>' || r == '|' {
+			return '_'
+		}
+		return r
+	}, s)
+}
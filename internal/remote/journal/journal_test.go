@@ -0,0 +1,170 @@
+package journal
+
+import (
+	"context"
+	"errors"
+	"image"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/koolo/internal/event"
+	"github.com/hectorgimenez/koolo/internal/remote/droplog"
+)
+
+var testLogger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+// at builds an event that happened at a fixed time, going through a record like the journal does
+func at(t *testing.T, e event.Event, occurred time.Time) event.Event {
+	t.Helper()
+
+	rec, err := event.NewRecord(e)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	rec.OccurredAt = occurred
+	out, err := rec.Event(e.Image())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	return out
+}
+
+func TestWriterAndRead(t *testing.T) {
+	dir := t.TempDir()
+	w := NewWriter(dir, Options{Screenshots: true}, testLogger)
+
+	day1 := time.Date(2025, 1, 1, 10, 0, 0, 0, time.Local)
+	day2 := day1.AddDate(0, 0, 1)
+	screenshot := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	events := []event.Event{
+		at(t, event.GameCreated(event.Text("sorc", "New game created"), "game-1", "pass"), day1),
+		at(t, event.ItemStashed(event.WithScreenshot("sorc", "Item stashed", screenshot), data.Drop{Item: data.Item{Name: "BerRune"}, RuleFile: "runes.nip:1"}), day1.Add(time.Minute)),
+		at(t, event.GamePaused(event.Text("pally", ""), true), day2),
+	}
+	for _, e := range events {
+		if err := w.Handle(context.Background(), e); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	records, err := Read(dir, Filter{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(records) != 3 || records[0].Type != "GameCreatedEvent" || records[2].Supervisor != "pally" {
+		t.Fatalf("Unexpected records %+v", records)
+	}
+	if records[1].Screenshot == "" {
+		t.Fatalf("Expected a screenshot reference")
+	}
+
+	e, err := Load(dir, records[1])
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if e.Image() == nil || e.Image().Bounds().Dx() != 4 {
+		t.Errorf("Expected the screenshot to be loaded")
+	}
+	if stashed, ok := e.(event.ItemStashedEvent); !ok || stashed.Item.Item.Name != "BerRune" {
+		t.Errorf("Unexpected event %+v", e)
+	}
+
+	filtered, _ := Read(dir, Filter{Supervisor: "SORC", Types: []string{"ItemStashed"}})
+	if len(filtered) != 1 || filtered[0].Type != "ItemStashedEvent" {
+		t.Errorf("Unexpected filtered records %+v", filtered)
+	}
+	filtered, _ = Read(dir, Filter{From: day2})
+	if len(filtered) != 1 || filtered[0].Supervisor != "pally" {
+		t.Errorf("Unexpected records by date %+v", filtered)
+	}
+
+	if records, err = Read(filepath.Join(dir, "missing"), Filter{}); err != nil || len(records) != 0 {
+		t.Errorf("Expected no records for a missing folder, got %v %v", records, err)
+	}
+}
+
+func TestWriterRollingAndRetention(t *testing.T) {
+	dir := t.TempDir()
+	old := filepath.Join(dir, "journal-2024-01-01.jsonl")
+	if err := os.WriteFile(old, []byte("{}\n"), 0o644); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	w := NewWriter(dir, Options{MaxFileSize: 200, RetentionDays: 7}, testLogger)
+	day := time.Date(2025, 1, 1, 10, 0, 0, 0, time.Local)
+	for i := 0; i < 10; i++ {
+		w.Handle(context.Background(), at(t, event.RunStarted(event.Text("sorc", "Starting run"), "mephisto"), day.Add(time.Duration(i)*time.Second)))
+	}
+
+	if _, err := os.Stat(old); !os.IsNotExist(err) {
+		t.Errorf("Expected the old journal to be removed")
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, "journal-2025-01-01*.jsonl"))
+	if len(files) < 2 {
+		t.Errorf("Expected the journal to be split in several files, got %v", files)
+	}
+
+	records, err := Read(dir, Filter{})
+	if err != nil || len(records) != 10 {
+		t.Fatalf("Expected every record back, got %d %v", len(records), err)
+	}
+	for i := 1; i < len(records); i++ {
+		if records[i].OccurredAt.Before(records[i-1].OccurredAt) {
+			t.Errorf("Records out of order at %d", i)
+		}
+	}
+
+	// A new writer, like after a restart, keeps appending to the last part
+	w = NewWriter(dir, Options{MaxFileSize: 200, RetentionDays: 7}, testLogger)
+	if file := w.currentFile(day); file == partFile(dir, "2025-01-01", 0) {
+		t.Errorf("Expected to keep writing to the last part after a restart, got %s", file)
+	}
+}
+
+func TestReplayBackfillsDroplog(t *testing.T) {
+	dir := t.TempDir()
+	w := NewWriter(dir, Options{}, testLogger)
+
+	day := time.Date(2025, 1, 1, 10, 0, 0, 0, time.Local)
+	w.Handle(context.Background(), at(t, event.ItemStashed(event.Text("sorc", "stashed"), data.Drop{Item: data.Item{Name: "BerRune"}}), day))
+	w.Handle(context.Background(), at(t, event.ItemStashed(event.Text("sorc", "stashed"), data.Drop{Item: data.Item{Name: "JahRune"}}), day.Add(time.Hour)))
+	w.Handle(context.Background(), at(t, event.GameFinished(event.Text("sorc", "done"), event.FinishedOK), day.Add(2*time.Hour)))
+
+	records, err := Read(dir, Filter{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	dropDir := filepath.Join(t.TempDir(), "droplogs")
+	failing := func(_ context.Context, e event.Event) error {
+		if _, ok := e.(event.GameFinishedEvent); ok {
+			return errors.New("notification failed")
+		}
+		return nil
+	}
+	result, err := Replay(context.Background(), dir, records, ReplayOptions{}, droplog.NewWriter(dropDir, testLogger).Handle, failing)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Events != 3 || result.Errors != 1 {
+		t.Errorf("Unexpected result %+v", result)
+	}
+
+	drops, err := droplog.ReadAll(dropDir)
+	if err != nil || len(drops) != 2 || drops[1].Drop.Item.Name != "JahRune" || !drops[0].Time.Equal(day) {
+		t.Errorf("Unexpected backfilled drops %+v %v", drops, err)
+	}
+	if _, err = os.Stat(filepath.Join(dropDir, "droplog-2025-01-01.jsonl")); err != nil {
+		t.Errorf("Expected the drops in the file of the day they happened: %v", err)
+	}
+
+	if _, err = Replay(context.Background(), dir, records, ReplayOptions{StopOnError: true}, failing); err == nil {
+		t.Errorf("Expected the replay to stop on the handler error")
+	}
+}
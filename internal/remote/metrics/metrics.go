@@ -0,0 +1,260 @@
+package metrics
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hectorgimenez/koolo/internal/event"
+)
+
+const (
+	counterType   = "counter"
+	gaugeType     = "gauge"
+	histogramType = "histogram"
+)
+
+// gameDurationBuckets are the upper bounds in seconds of the game length histogram
+var gameDurationBuckets = []float64{60, 120, 180, 300, 450, 600, 900, 1200, 1800, 3600}
+
+type familyDesc struct {
+	help string
+	kind string
+}
+
+var families = map[string]familyDesc{
+	"koolo_games_started_total":   {"Games created", counterType},
+	"koolo_games_finished_total":  {"Games finished by reason", counterType},
+	"koolo_runs_finished_total":   {"Runs finished by run and reason", counterType},
+	"koolo_deaths_total":          {"Games finished because the character died", counterType},
+	"koolo_chickens_total":        {"Games finished because of a chicken, including merc chickens", counterType},
+	"koolo_potions_used_total":    {"Potions used by type and target", counterType},
+	"koolo_items_stashed_total":   {"Items stashed by quality", counterType},
+	"koolo_game_duration_seconds": {"Game length in seconds", histogramType},
+}
+
+// Label is a metric label, labels are rendered in the given order.
+type Label struct {
+	Name  string
+	Value string
+}
+
+// Sample is a value computed at scrape time, like the supervisor status or the character level. It's a gauge unless
+// Counter is set, for counters kept elsewhere like the event bus ones.
+type Sample struct {
+	Name    string
+	Help    string
+	Labels  []Label
+	Value   float64
+	Counter bool
+}
+
+type histogram struct {
+	buckets []uint64
+	count   uint64
+	sum     float64
+}
+
+// Collector aggregates the events in Prometheus counters and histograms and renders them in the text exposition
+// format, together with the gauges provided at scrape time.
+type Collector struct {
+	mu           sync.Mutex
+	counters     map[string]map[string]float64
+	histograms   map[string]map[string]*histogram
+	gamesStarted map[string]time.Time
+}
+
+func NewCollector() *Collector {
+	return &Collector{
+		counters:     make(map[string]map[string]float64),
+		histograms:   make(map[string]map[string]*histogram),
+		gamesStarted: make(map[string]time.Time),
+	}
+}
+
+// Handle subscribes to the event bus, it's meant to be registered once and shared by all the supervisors.
+func (c *Collector) Handle(_ context.Context, e event.Event) error {
+	sup := e.Supervisor()
+	if sup == "" {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	switch evt := e.(type) {
+	case event.GameCreatedEvent:
+		c.inc("koolo_games_started_total", Label{"supervisor", sup})
+		c.gamesStarted[sup] = evt.OccurredAt()
+
+	case event.GameFinishedEvent:
+		c.inc("koolo_games_finished_total", Label{"supervisor", sup}, Label{"reason", string(evt.Reason)})
+		switch evt.Reason {
+		case event.FinishedDied:
+			c.inc("koolo_deaths_total", Label{"supervisor", sup})
+		case event.FinishedChicken, event.FinishedMercChicken:
+			c.inc("koolo_chickens_total", Label{"supervisor", sup})
+		}
+		if started, found := c.gamesStarted[sup]; found {
+			c.observe("koolo_game_duration_seconds", evt.OccurredAt().Sub(started).Seconds(), Label{"supervisor", sup})
+			delete(c.gamesStarted, sup)
+		}
+
+	case event.RunFinishedEvent:
+		c.inc("koolo_runs_finished_total", Label{"supervisor", sup}, Label{"run", evt.RunName}, Label{"reason", string(evt.Reason)})
+
+	case event.UsedPotionEvent:
+		target := "player"
+		if evt.OnMerc {
+			target = "merc"
+		}
+		c.inc("koolo_potions_used_total", Label{"supervisor", sup}, Label{"type", string(evt.PotionType)}, Label{"target", target})
+
+	case event.ItemStashedEvent:
+		c.inc("koolo_items_stashed_total", Label{"supervisor", sup}, Label{"quality", strings.ToLower(evt.Item.Item.Quality.ToString())})
+	}
+
+	return nil
+}
+
+func (c *Collector) inc(name string, labels ...Label) {
+	if c.counters[name] == nil {
+		c.counters[name] = make(map[string]float64)
+	}
+	c.counters[name][renderLabels(labels)]++
+}
+
+func (c *Collector) observe(name string, value float64, labels ...Label) {
+	if c.histograms[name] == nil {
+		c.histograms[name] = make(map[string]*histogram)
+	}
+	key := renderLabels(labels)
+	h, found := c.histograms[name][key]
+	if !found {
+		h = &histogram{buckets: make([]uint64, len(gameDurationBuckets))}
+		c.histograms[name][key] = h
+	}
+
+	h.count++
+	h.sum += value
+	for i, upper := range gameDurationBuckets {
+		if value <= upper {
+			h.buckets[i]++
+		}
+	}
+}
+
+// Write renders every metric in the Prometheus text exposition format, gauges are appended after the counters.
+func (c *Collector) Write(w io.Writer, gauges []Sample) error {
+	bw := bufio.NewWriter(w)
+
+	c.mu.Lock()
+	names := make([]string, 0, len(families))
+	for name := range families {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		desc := families[name]
+		fmt.Fprintf(bw, "# HELP %s %s\n# TYPE %s %s\n", name, desc.help, name, desc.kind)
+
+		if desc.kind == histogramType {
+			for _, key := range sortedKeys(c.histograms[name]) {
+				h := c.histograms[name][key]
+				for i, upper := range gameDurationBuckets {
+					fmt.Fprintf(bw, "%s_bucket%s %d\n", name, withLabel(key, "le", formatFloat(upper)), h.buckets[i])
+				}
+				fmt.Fprintf(bw, "%s_bucket%s %d\n", name, withLabel(key, "le", "+Inf"), h.count)
+				fmt.Fprintf(bw, "%s_sum%s %s\n", name, key, formatFloat(h.sum))
+				fmt.Fprintf(bw, "%s_count%s %d\n", name, key, h.count)
+			}
+			continue
+		}
+
+		for _, key := range sortedKeys(c.counters[name]) {
+			fmt.Fprintf(bw, "%s%s %s\n", name, key, formatFloat(c.counters[name][key]))
+		}
+	}
+	c.mu.Unlock()
+
+	// Group gauges by name, help and type lines must appear only once per metric
+	byName := make(map[string][]Sample)
+	gaugeNames := make([]string, 0)
+	for _, g := range gauges {
+		if _, found := byName[g.Name]; !found {
+			gaugeNames = append(gaugeNames, g.Name)
+		}
+		byName[g.Name] = append(byName[g.Name], g)
+	}
+	sort.Strings(gaugeNames)
+
+	for _, name := range gaugeNames {
+		samples := byName[name]
+		kind := gaugeType
+		if samples[0].Counter {
+			kind = counterType
+		}
+		fmt.Fprintf(bw, "# HELP %s %s\n# TYPE %s %s\n", name, samples[0].Help, name, kind)
+		for _, s := range samples {
+			fmt.Fprintf(bw, "%s%s %s\n", name, renderLabels(s.Labels), formatFloat(s.Value))
+		}
+	}
+
+	return bw.Flush()
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func renderLabels(labels []Label) string {
+	if len(labels) == 0 {
+		return ""
+	}
+
+	parts := make([]string, 0, len(labels))
+	for _, l := range labels {
+		parts = append(parts, l.Name+`="`+escapeLabel(l.Value)+`"`)
+	}
+
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+// withLabel appends a label to an already rendered label set.
+func withLabel(rendered, name, value string) string {
+	l := name + `="` + escapeLabel(value) + `"`
+	if rendered == "" {
+		return "{" + l + "}"
+	}
+
+	return strings.TrimSuffix(rendered, "}") + "," + l + "}"
+}
+
+func escapeLabel(v string) string {
+	v = strings.ReplaceAll(v, `\`, `\\`)
+	v = strings.ReplaceAll(v, "\n", `\n`)
+
+	return strings.ReplaceAll(v, `"`, `\"`)
+}
+
+func formatFloat(v float64) string {
+	if math.IsInf(v, 1) {
+		return "+Inf"
+	}
+
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
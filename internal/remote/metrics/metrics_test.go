@@ -0,0 +1,104 @@
+package metrics
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/item"
+	"github.com/hectorgimenez/koolo/internal/event"
+)
+
+func render(t *testing.T, c *Collector, gauges ...Sample) string {
+	t.Helper()
+
+	sb := &strings.Builder{}
+	if err := c.Write(sb, gauges); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	return sb.String()
+}
+
+func assertLine(t *testing.T, output, line string) {
+	t.Helper()
+
+	for _, l := range strings.Split(output, "\n") {
+		if l == line {
+			return
+		}
+	}
+	t.Errorf("Expected line %q in output:\n%s", line, output)
+}
+
+func TestCollectorCounters(t *testing.T) {
+	c := NewCollector()
+	handle := func(e event.Event) {
+		if err := c.Handle(context.Background(), e); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	handle(event.GameCreated(event.Text("sorc", ""), "game-1", ""))
+	handle(event.RunFinished(event.Text("sorc", ""), "pit", event.FinishedOK))
+	handle(event.RunFinished(event.Text("sorc", ""), "pit", event.FinishedOK))
+	handle(event.RunFinished(event.Text("sorc", ""), "mephisto", event.FinishedChicken))
+	handle(event.UsedPotion(event.Text("sorc", ""), data.HealingPotion, false))
+	handle(event.UsedPotion(event.Text("sorc", ""), data.HealingPotion, true))
+	handle(event.ItemStashed(event.Text("sorc", ""), data.Drop{Item: data.Item{Name: "BerRune", Quality: item.QualityNormal}}))
+	handle(event.GameFinished(event.Text("sorc", ""), event.FinishedChicken))
+	handle(event.GameFinished(event.Text("pally", ""), event.FinishedDied))
+
+	out := render(t, c)
+	assertLine(t, out, "# TYPE koolo_games_started_total counter")
+	assertLine(t, out, `koolo_games_started_total{supervisor="sorc"} 1`)
+	assertLine(t, out, `koolo_runs_finished_total{supervisor="sorc",run="pit",reason="ok"} 2`)
+	assertLine(t, out, `koolo_runs_finished_total{supervisor="sorc",run="mephisto",reason="chicken"} 1`)
+	assertLine(t, out, `koolo_potions_used_total{supervisor="sorc",type="HealingPotion",target="player"} 1`)
+	assertLine(t, out, `koolo_potions_used_total{supervisor="sorc",type="HealingPotion",target="merc"} 1`)
+	assertLine(t, out, `koolo_items_stashed_total{supervisor="sorc",quality="normal"} 1`)
+	assertLine(t, out, `koolo_chickens_total{supervisor="sorc"} 1`)
+	assertLine(t, out, `koolo_deaths_total{supervisor="pally"} 1`)
+	assertLine(t, out, `koolo_game_duration_seconds_count{supervisor="sorc"} 1`)
+
+	// Pally never created a game, so there is no duration to observe
+	if strings.Contains(out, `koolo_game_duration_seconds_count{supervisor="pally"}`) {
+		t.Errorf("Unexpected game duration for pally:\n%s", out)
+	}
+}
+
+func TestCollectorGameDurationHistogram(t *testing.T) {
+	c := NewCollector()
+	c.gamesStarted["sorc"] = time.Now().Add(-250 * time.Second)
+	c.Handle(context.Background(), event.GameFinished(event.Text("sorc", ""), event.FinishedOK))
+
+	out := render(t, c)
+	assertLine(t, out, `koolo_game_duration_seconds_bucket{supervisor="sorc",le="180"} 0`)
+	assertLine(t, out, `koolo_game_duration_seconds_bucket{supervisor="sorc",le="300"} 1`)
+	assertLine(t, out, `koolo_game_duration_seconds_bucket{supervisor="sorc",le="+Inf"} 1`)
+	assertLine(t, out, "# TYPE koolo_game_duration_seconds histogram")
+}
+
+func TestCollectorGauges(t *testing.T) {
+	out := render(t, NewCollector(),
+		Sample{Name: "koolo_character_level", Help: "Current character level", Labels: []Label{{"supervisor", "sorc"}}, Value: 85},
+		Sample{Name: "koolo_character_level", Help: "Current character level", Labels: []Label{{"supervisor", `we"ird`}}, Value: 12},
+	)
+
+	if strings.Count(out, "# TYPE koolo_character_level gauge") != 1 {
+		t.Errorf("Expected a single TYPE line for the gauge:\n%s", out)
+	}
+	assertLine(t, out, `koolo_character_level{supervisor="sorc"} 85`)
+	assertLine(t, out, `koolo_character_level{supervisor="we\"ird"} 12`)
+}
+
+func TestCollectorCounterSamples(t *testing.T) {
+	out := render(t, NewCollector(),
+		Sample{Name: "koolo_event_dropped_total", Help: "Events dropped", Labels: []Label{{"subscriber", "discord"}}, Value: 3, Counter: true},
+	)
+
+	assertLine(t, out, "# TYPE koolo_event_dropped_total counter")
+	assertLine(t, out, `koolo_event_dropped_total{subscriber="discord"} 3`)
+}
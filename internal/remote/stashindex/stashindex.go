@@ -0,0 +1,241 @@
+package stashindex
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/item"
+	"github.com/hectorgimenez/d2go/pkg/nip"
+	"github.com/hectorgimenez/koolo/internal/config"
+	"github.com/hectorgimenez/koolo/internal/event"
+)
+
+// Snapshot is the last known stash and inventory content of a supervisor.
+type Snapshot struct {
+	Supervisor string      `json:"supervisor"`
+	Character  string      `json:"character"` // in-game character name
+	Profile    string      `json:"profile"`   // config folder name
+	TakenAt    time.Time   `json:"takenAt"`
+	Items      []data.Item `json:"items"`
+}
+
+// Dir returns the folder where the stash snapshots are stored, next to the droplogs.
+func Dir() string {
+	base := config.Koolo.LogSaveDirectory
+	if base == "" {
+		base = "logs"
+	}
+
+	return filepath.Join(base, "stash")
+}
+
+// Writer keeps the latest snapshot of every supervisor on disk, one file per supervisor.
+type Writer struct {
+	dir    string
+	logger *slog.Logger
+}
+
+func NewWriter(dir string, logger *slog.Logger) *Writer {
+	return &Writer{dir: dir, logger: logger}
+}
+
+// Handle subscribes to the event bus and persists StashSnapshotEvent.
+func (w *Writer) Handle(_ context.Context, e event.Event) error {
+	evt, ok := e.(event.StashSnapshotEvent)
+	if !ok {
+		return nil
+	}
+
+	snap := Snapshot{
+		Supervisor: e.Supervisor(),
+		TakenAt:    e.OccurredAt(),
+		Items:      evt.Items,
+	}
+	if cfg, found := config.GetCharacter(snap.Supervisor); found && cfg != nil {
+		snap.Character = cfg.CharacterName
+		snap.Profile = cfg.ConfigFolderName
+	}
+
+	if err := Save(w.dir, snap); err != nil {
+		w.logger.Error("Failed to save stash snapshot", slog.Any("error", err))
+	}
+
+	return nil // don't break the bot because of the stash index
+}
+
+// Save replaces the supervisor snapshot, the file is written first to a temporary file so a crash never leaves a
+// half written snapshot.
+func Save(dir string, snap Snapshot) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	d, err := json.Marshal(snap)
+	if err != nil {
+		return err
+	}
+
+	file := filepath.Join(dir, snap.Supervisor+".json")
+	if err = os.WriteFile(file+".tmp", d, 0o644); err != nil {
+		return err
+	}
+
+	return os.Rename(file+".tmp", file)
+}
+
+// ReadAll loads every snapshot, sorted by supervisor. A missing folder is not an error.
+func ReadAll(dir string) ([]Snapshot, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Snapshot{}, nil
+		}
+		return nil, err
+	}
+
+	snapshots := make([]Snapshot, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+
+		d, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		snap := Snapshot{}
+		if err = json.Unmarshal(d, &snap); err != nil {
+			return nil, fmt.Errorf("error reading stash snapshot %s: %w", entry.Name(), err)
+		}
+		snapshots = append(snapshots, snap)
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool { return snapshots[i].Supervisor < snapshots[j].Supervisor })
+
+	return snapshots, nil
+}
+
+// Query filters the items, empty fields match everything. NIP is a pickit rule, like [name] == ring && [quality] == unique.
+type Query struct {
+	Supervisor string `json:"supervisor"`
+	Name       string `json:"name"`    // part of the base, identified or runeword name
+	Quality    string `json:"quality"` // normal, magic, rare, set, unique...
+	Stat       string `json:"stat"`    // part of any stat description
+	NIP        string `json:"nip"`
+}
+
+// Result is a matching item and where to find it.
+type Result struct {
+	Supervisor     string    `json:"supervisor"`
+	Character      string    `json:"character"`
+	Profile        string    `json:"profile"`
+	TakenAt        time.Time `json:"takenAt"`
+	Location       string    `json:"location"`
+	Tab            int       `json:"tab,omitempty"` // stash tab as shown in game, 1 is the personal one
+	Name           string    `json:"name"`
+	IdentifiedName string    `json:"identifiedName,omitempty"`
+	Quality        string    `json:"quality"`
+	Ethereal       bool      `json:"ethereal"`
+	Stats          []string  `json:"stats"`
+}
+
+// Search returns the items matching the query in every snapshot.
+func Search(snapshots []Snapshot, q Query) ([]Result, error) {
+	var rule *nip.Rule
+	if strings.TrimSpace(q.NIP) != "" {
+		r, err := nip.NewRule(q.NIP, "search", 1)
+		if err != nil {
+			return nil, fmt.Errorf("invalid NIP expression: %w", err)
+		}
+		rule = &r
+	}
+
+	results := make([]Result, 0)
+	for _, snap := range snapshots {
+		if q.Supervisor != "" && !strings.EqualFold(snap.Supervisor, q.Supervisor) {
+			continue
+		}
+
+		for _, itm := range snap.Items {
+			if !matches(itm, q, rule) {
+				continue
+			}
+
+			res := Result{
+				Supervisor:     snap.Supervisor,
+				Character:      snap.Character,
+				Profile:        snap.Profile,
+				TakenAt:        snap.TakenAt,
+				Location:       string(itm.Location.LocationType),
+				Name:           string(itm.Name),
+				IdentifiedName: itm.IdentifiedName,
+				Quality:        itm.Quality.ToString(),
+				Ethereal:       itm.Ethereal,
+				Stats:          statStrings(itm),
+			}
+			if itm.Location.LocationType == item.LocationStash || itm.Location.LocationType == item.LocationSharedStash {
+				res.Tab = itm.Location.Page + 1
+			}
+			results = append(results, res)
+		}
+	}
+
+	return results, nil
+}
+
+func matches(itm data.Item, q Query, rule *nip.Rule) bool {
+	if q.Name != "" {
+		name := strings.ToLower(q.Name)
+		if !strings.Contains(strings.ToLower(string(itm.Name)), name) &&
+			!strings.Contains(strings.ToLower(itm.IdentifiedName), name) &&
+			!strings.Contains(strings.ToLower(string(itm.RunewordName)), name) {
+			return false
+		}
+	}
+
+	if q.Quality != "" && !strings.EqualFold(itm.Quality.ToString(), q.Quality) {
+		return false
+	}
+
+	if q.Stat != "" {
+		stat := strings.ToLower(q.Stat)
+		found := false
+		for _, s := range statStrings(itm) {
+			if strings.Contains(strings.ToLower(s), stat) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if rule != nil {
+		res, err := rule.Evaluate(itm)
+		if err != nil || res == nip.RuleResultNoMatch {
+			return false
+		}
+	}
+
+	return true
+}
+
+func statStrings(itm data.Item) []string {
+	stats := make([]string, 0, len(itm.Stats))
+	for _, s := range itm.Stats {
+		if str := strings.TrimSpace(s.String()); str != "" {
+			stats = append(stats, str)
+		}
+	}
+
+	return stats
+}
@@ -0,0 +1,101 @@
+package stashindex
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/item"
+	"github.com/hectorgimenez/d2go/pkg/data/stat"
+)
+
+func testSnapshots(t *testing.T) []Snapshot {
+	t.Helper()
+
+	soj := data.Item{
+		ID:             item.GetIDByName("Ring"),
+		Name:           "Ring",
+		IdentifiedName: "The Stone of Jordan",
+		Quality:        item.QualityUnique,
+		Identified:     true,
+		Location:       item.Location{LocationType: item.LocationSharedStash, Page: 2},
+		Stats:          stat.Stats{{ID: stat.MaxMana, Value: 20}, {ID: stat.AllSkills, Value: 1}},
+	}
+	ber := data.Item{
+		ID:       item.GetIDByName("BerRune"),
+		Name:     "BerRune",
+		Quality:  item.QualityNormal,
+		Location: item.Location{LocationType: item.LocationStash},
+	}
+	shako := data.Item{
+		ID:             item.GetIDByName("Shako"),
+		Name:           "Shako",
+		IdentifiedName: "Harlequin Crest",
+		Quality:        item.QualityUnique,
+		Identified:     true,
+		Location:       item.Location{LocationType: item.LocationInventory},
+	}
+
+	dir := t.TempDir()
+	takenAt := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+	for _, snap := range []Snapshot{
+		{Supervisor: "sorc", TakenAt: takenAt, Items: []data.Item{soj, ber}},
+		{Supervisor: "mule1", TakenAt: takenAt, Items: []data.Item{shako, ber}},
+	} {
+		if err := Save(dir, snap); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	snapshots, err := ReadAll(dir)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(snapshots) != 2 || snapshots[0].Supervisor != "mule1" || !snapshots[1].TakenAt.Equal(takenAt) {
+		t.Fatalf("Unexpected snapshots %+v", snapshots)
+	}
+
+	return snapshots
+}
+
+func TestSearch(t *testing.T) {
+	snapshots := testSnapshots(t)
+
+	tests := []struct {
+		name     string
+		query    Query
+		expected []string
+	}{
+		{"all", Query{}, []string{"mule1/Shako", "mule1/BerRune", "sorc/Ring", "sorc/BerRune"}},
+		{"by name", Query{Name: "ber"}, []string{"mule1/BerRune", "sorc/BerRune"}},
+		{"by identified name", Query{Name: "jordan"}, []string{"sorc/Ring"}},
+		{"by quality", Query{Quality: "unique", Supervisor: "mule1"}, []string{"mule1/Shako"}},
+		{"by stat", Query{Stat: "mana"}, []string{"sorc/Ring"}},
+		{"by nip", Query{NIP: "[type] == ring && [quality] == unique"}, []string{"sorc/Ring"}},
+	}
+
+	for _, tt := range tests {
+		results, err := Search(snapshots, tt.query)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.name, err)
+		}
+		if len(results) != len(tt.expected) {
+			t.Errorf("%s: expected %v, got %+v", tt.name, tt.expected, results)
+			continue
+		}
+		for i, res := range results {
+			if got := res.Supervisor + "/" + res.Name; got != tt.expected[i] {
+				t.Errorf("%s: expected result %d to be %s, got %s", tt.name, i, tt.expected[i], got)
+			}
+		}
+	}
+
+	results, _ := Search(snapshots, Query{Name: "jordan"})
+	if results[0].Location != string(item.LocationSharedStash) || results[0].Tab != 3 {
+		t.Errorf("Unexpected location %s tab %d", results[0].Location, results[0].Tab)
+	}
+
+	if _, err := Search(snapshots, Query{NIP: "[type] == "}); err == nil {
+		t.Errorf("Expected error for invalid NIP expression")
+	}
+}
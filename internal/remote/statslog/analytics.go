@@ -0,0 +1,121 @@
+package statslog
+
+import (
+	"sort"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/item"
+	"github.com/hectorgimenez/koolo/internal/event"
+)
+
+// RunAnalytics contains the efficiency metrics for a single run type, aggregated across every recorded game.
+type RunAnalytics struct {
+	Rank            int                     `json:"rank"`
+	Run             string                  `json:"run"`
+	Runs            int                     `json:"runs"`
+	Deaths          int                     `json:"deaths"`
+	Chickens        int                     `json:"chickens"`
+	Errors          int                     `json:"errors"`
+	Drops           int                     `json:"drops"`
+	ValuableDrops   int                     `json:"valuableDrops"`
+	UsedPotions     map[data.PotionType]int `json:"usedPotions"`
+	MercPotions     int                     `json:"mercPotions"`
+	TotalSeconds    float64                 `json:"totalSeconds"`
+	AverageSeconds  float64                 `json:"averageSeconds"`
+	DeathRate       float64                 `json:"deathRate"`
+	ChickenRate     float64                 `json:"chickenRate"`
+	FailureRate     float64                 `json:"failureRate"`
+	DropsPerHour    float64                 `json:"dropsPerHour"`
+	ValuablePerHour float64                 `json:"valuablePerHour"`
+	PotionsPerRun   float64                 `json:"potionsPerRun"`
+	Score           float64                 `json:"score"`
+}
+
+// IsValuableDrop returns true for drops worth more than a plain magic item: uniques, sets, runes and any
+// rare/crafted item.
+func IsValuableDrop(d data.Drop) bool {
+	switch d.Item.Quality {
+	case item.QualityUnique, item.QualitySet, item.QualityRare, item.QualityCrafted:
+		return true
+	}
+
+	return d.Item.Desc().Type == item.TypeRune
+}
+
+// AnalyzeRuns computes per run metrics and ranks them by score, the most productive run first. The score is the
+// amount of drops per hour (valuable drops count double) penalized by the failure rate, since a run that ends the
+// game early also wastes the rest of the runs scheduled after it.
+func AnalyzeRuns(records []Record) []RunAnalytics {
+	byRun := make(map[string]*RunAnalytics)
+	for _, rec := range records {
+		for _, r := range rec.Runs {
+			// Unfinished runs (Koolo closed mid run) would skew durations
+			if r.FinishedAt.IsZero() {
+				continue
+			}
+
+			a, found := byRun[r.Name]
+			if !found {
+				a = &RunAnalytics{Run: r.Name, UsedPotions: make(map[data.PotionType]int)}
+				byRun[r.Name] = a
+			}
+
+			a.Runs++
+			a.TotalSeconds += r.Duration().Seconds()
+			a.Drops += len(r.Drops)
+			for _, d := range r.Drops {
+				if IsValuableDrop(d) {
+					a.ValuableDrops++
+				}
+			}
+			for pt, count := range r.UsedPotions {
+				a.UsedPotions[pt] += count
+			}
+			a.MercPotions += r.MercPotions
+
+			switch r.Reason {
+			case event.FinishedDied:
+				a.Deaths++
+			case event.FinishedChicken, event.FinishedMercChicken:
+				a.Chickens++
+			case event.FinishedError:
+				a.Errors++
+			}
+		}
+	}
+
+	out := make([]RunAnalytics, 0, len(byRun))
+	for _, a := range byRun {
+		runs := float64(a.Runs)
+		a.AverageSeconds = a.TotalSeconds / runs
+		a.DeathRate = float64(a.Deaths) / runs
+		a.ChickenRate = float64(a.Chickens) / runs
+		a.FailureRate = float64(a.Deaths+a.Chickens+a.Errors) / runs
+
+		potions := a.MercPotions
+		for _, count := range a.UsedPotions {
+			potions += count
+		}
+		a.PotionsPerRun = float64(potions) / runs
+
+		if hours := a.TotalSeconds / 3600; hours > 0 {
+			a.DropsPerHour = float64(a.Drops) / hours
+			a.ValuablePerHour = float64(a.ValuableDrops) / hours
+		}
+		a.Score = (a.DropsPerHour + a.ValuablePerHour) * (1 - a.FailureRate)
+
+		out = append(out, *a)
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Score != out[j].Score {
+			return out[i].Score > out[j].Score
+		}
+		return out[i].Run < out[j].Run
+	})
+	for i := range out {
+		out[i].Rank = i + 1
+	}
+
+	return out
+}
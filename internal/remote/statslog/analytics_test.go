@@ -0,0 +1,133 @@
+package statslog
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/item"
+	"github.com/hectorgimenez/koolo/internal/event"
+)
+
+func testDrop(name string, quality item.Quality) data.Drop {
+	return data.Drop{Item: data.Item{ID: item.GetIDByName(name), Name: item.Name(name), Quality: quality}}
+}
+
+func TestIsValuableDrop(t *testing.T) {
+	tests := []struct {
+		name     string
+		drop     data.Drop
+		expected bool
+	}{
+		{"unique", testDrop("Ring", item.QualityUnique), true},
+		{"set", testDrop("Amulet", item.QualitySet), true},
+		{"rare", testDrop("Ring", item.QualityRare), true},
+		{"crafted", testDrop("Amulet", item.QualityCrafted), true},
+		{"rune", testDrop("BerRune", item.QualityNormal), true},
+		{"magic", testDrop("Ring", item.QualityMagic), false},
+		{"superior", testDrop("Shako", item.QualitySuperior), false},
+		{"gem", testDrop("PerfectAmethyst", item.QualityNormal), false},
+	}
+
+	for _, tt := range tests {
+		if got := IsValuableDrop(tt.drop); got != tt.expected {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.expected, got)
+		}
+	}
+}
+
+func TestAnalyzeRuns(t *testing.T) {
+	start := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+	run := func(name string, minutes int, reason event.FinishReason, drops ...data.Drop) RunRecord {
+		return RunRecord{
+			Name:       name,
+			Reason:     reason,
+			StartedAt:  start,
+			FinishedAt: start.Add(time.Duration(minutes) * time.Minute),
+			Drops:      drops,
+		}
+	}
+
+	pindle := run("Pindleskin", 30, event.FinishedOK, testDrop("BerRune", item.QualityNormal), testDrop("Ring", item.QualityMagic))
+	pindle.UsedPotions = map[data.PotionType]int{data.HealingPotion: 2}
+	pindle.MercPotions = 1
+	// Koolo was closed mid run, it's not counted
+	unfinished := run("Cows", 0, event.FinishedOK, testDrop("Ring", item.QualityUnique))
+	unfinished.FinishedAt = time.Time{}
+
+	records := []Record{
+		{Supervisor: "sorc", Runs: []RunRecord{
+			pindle,
+			run("Mephisto", 15, event.FinishedOK, testDrop("Amulet", item.QualityRare)),
+			run("Cows", 0, event.FinishedOK, testDrop("Ring", item.QualityMagic)),
+		}},
+		{Supervisor: "sorc", Runs: []RunRecord{
+			run("Pindleskin", 30, event.FinishedChicken, testDrop("Ring", item.QualityUnique)),
+			run("Mephisto", 15, event.FinishedDied),
+			unfinished,
+		}},
+		{Supervisor: "pala", Runs: []RunRecord{
+			run("Mephisto", 15, event.FinishedError),
+			run("Mephisto", 15, event.FinishedMercChicken),
+		}},
+	}
+
+	tests := []struct {
+		run             string
+		rank            int
+		runs            int
+		drops           int
+		valuableDrops   int
+		averageSeconds  float64
+		failureRate     float64
+		deathRate       float64
+		chickenRate     float64
+		dropsPerHour    float64
+		valuablePerHour float64
+		potionsPerRun   float64
+		score           float64
+	}{
+		// 1 hour, 3 drops and 2 valuable ones, half of the runs chickened: (3 + 2) * 0.5
+		{"Pindleskin", 1, 2, 3, 2, 1800, 0.5, 0, 0.5, 3, 2, 1.5, 2.5},
+		// 1 hour, a rare, 3 failures out of 4 runs: (1 + 1) * 0.25
+		{"Mephisto", 2, 4, 1, 1, 900, 0.75, 0.25, 0.25, 1, 1, 0, 0.5},
+		// Zero duration runs don't divide by zero, they have no rate per hour
+		{"Cows", 3, 1, 1, 0, 0, 0, 0, 0, 0, 0, 0, 0},
+	}
+
+	analytics := AnalyzeRuns(records)
+	if len(analytics) != len(tests) {
+		t.Fatalf("Expected %d runs, got %+v", len(tests), analytics)
+	}
+	near := func(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
+	for i, tt := range tests {
+		a := analytics[i]
+		if a.Run != tt.run || a.Rank != tt.rank {
+			t.Errorf("Expected %s ranked %d, got %s ranked %d", tt.run, tt.rank, a.Run, a.Rank)
+			continue
+		}
+		if a.Runs != tt.runs || a.Drops != tt.drops || a.ValuableDrops != tt.valuableDrops {
+			t.Errorf("%s: expected %d runs, %d drops and %d valuable, got %d, %d and %d", tt.run, tt.runs, tt.drops, tt.valuableDrops, a.Runs, a.Drops, a.ValuableDrops)
+		}
+		if !near(a.AverageSeconds, tt.averageSeconds) || !near(a.PotionsPerRun, tt.potionsPerRun) {
+			t.Errorf("%s: expected %v seconds and %v potions per run, got %v and %v", tt.run, tt.averageSeconds, tt.potionsPerRun, a.AverageSeconds, a.PotionsPerRun)
+		}
+		if !near(a.FailureRate, tt.failureRate) || !near(a.DeathRate, tt.deathRate) || !near(a.ChickenRate, tt.chickenRate) {
+			t.Errorf("%s: expected failure, death and chicken rates %v, %v and %v, got %v, %v and %v", tt.run, tt.failureRate, tt.deathRate, tt.chickenRate, a.FailureRate, a.DeathRate, a.ChickenRate)
+		}
+		if !near(a.DropsPerHour, tt.dropsPerHour) || !near(a.ValuablePerHour, tt.valuablePerHour) {
+			t.Errorf("%s: expected %v drops and %v valuable per hour, got %v and %v", tt.run, tt.dropsPerHour, tt.valuablePerHour, a.DropsPerHour, a.ValuablePerHour)
+		}
+		if !near(a.Score, tt.score) {
+			t.Errorf("%s: expected score %v, got %v", tt.run, tt.score, a.Score)
+		}
+	}
+
+	if pots := analytics[0].UsedPotions[data.HealingPotion]; pots != 2 || analytics[0].MercPotions != 1 {
+		t.Errorf("Expected 2 healing and 1 merc potion, got %d and %d", pots, analytics[0].MercPotions)
+	}
+	if got := AnalyzeRuns(nil); len(got) != 0 {
+		t.Errorf("Expected no runs, got %+v", got)
+	}
+}
@@ -0,0 +1,106 @@
+package statslog
+
+import (
+	"sort"
+
+	"github.com/hectorgimenez/koolo/internal/event"
+)
+
+type GroupBy string
+
+const (
+	GroupByDay     GroupBy = "day"
+	GroupByRun     GroupBy = "run"
+	GroupByProfile GroupBy = "profile"
+)
+
+// Summary aggregates the games (or runs, when grouping by run) sharing the same key.
+type Summary struct {
+	Key            string  `json:"key"`
+	Games          int     `json:"games"`
+	Runs           int     `json:"runs"`
+	Deaths         int     `json:"deaths"`
+	Chickens       int     `json:"chickens"`
+	Errors         int     `json:"errors"`
+	Drops          int     `json:"drops"`
+	UsedPotions    int     `json:"usedPotions"`
+	TotalSeconds   float64 `json:"totalSeconds"`
+	AverageSeconds float64 `json:"averageSeconds"`
+	DropsPerHour   float64 `json:"dropsPerHour"`
+	GamesPerHour   float64 `json:"gamesPerHour"`
+}
+
+// Summarize groups the records by day, run name or profile. Runs are always counted individually, so grouping by
+// run only takes into account the time spent on each run instead of the whole game.
+func Summarize(records []Record, groupBy GroupBy) []Summary {
+	byKey := make(map[string]*Summary)
+	get := func(key string) *Summary {
+		s, found := byKey[key]
+		if !found {
+			s = &Summary{Key: key}
+			byKey[key] = s
+		}
+		return s
+	}
+
+	for _, rec := range records {
+		if groupBy == GroupByRun {
+			for _, r := range rec.Runs {
+				s := get(r.Name)
+				s.Games++
+				s.addRun(r)
+				s.TotalSeconds += r.Duration().Seconds()
+			}
+			continue
+		}
+
+		key := rec.StartedAt.Format("2006-01-02")
+		if groupBy == GroupByProfile {
+			key = rec.Profile
+			if key == "" {
+				key = rec.Supervisor
+			}
+		}
+
+		s := get(key)
+		s.Games++
+		s.TotalSeconds += rec.Duration().Seconds()
+		for _, r := range rec.Runs {
+			s.addRun(r)
+		}
+	}
+
+	out := make([]Summary, 0, len(byKey))
+	for _, s := range byKey {
+		if s.Games > 0 {
+			s.AverageSeconds = s.TotalSeconds / float64(s.Games)
+		}
+		if hours := s.TotalSeconds / 3600; hours > 0 {
+			s.DropsPerHour = float64(s.Drops) / hours
+			s.GamesPerHour = float64(s.Games) / hours
+		}
+		out = append(out, *s)
+	}
+
+	sort.Slice(out, func(i, j int) bool { return out[i].Key < out[j].Key })
+
+	return out
+}
+
+func (s *Summary) addRun(r RunRecord) {
+	s.Runs++
+	s.Drops += len(r.Drops)
+	s.UsedPotions += r.MercPotions
+	for _, count := range r.UsedPotions {
+		s.UsedPotions += count
+	}
+
+	switch r.Reason {
+	case event.FinishedDied:
+		s.Deaths++
+	case event.FinishedChicken, event.FinishedMercChicken:
+		s.Chickens++
+	case event.FinishedError:
+		s.Errors++
+	}
+}
@@ -0,0 +1,264 @@
+package statslog
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/koolo/internal/config"
+	"github.com/hectorgimenez/koolo/internal/event"
+)
+
+// Record is the persisted representation of a finished game, including every run played on it.
+type Record struct {
+	Supervisor string             `json:"supervisor"`
+	Character  string             `json:"character"` // in-game character name
+	Profile    string             `json:"profile"`   // config folder name
+	StartedAt  time.Time          `json:"startedAt"`
+	FinishedAt time.Time          `json:"finishedAt"`
+	Reason     event.FinishReason `json:"reason"`
+	Runs       []RunRecord        `json:"runs"`
+}
+
+type RunRecord struct {
+	Name        string                  `json:"name"`
+	Reason      event.FinishReason      `json:"reason"`
+	StartedAt   time.Time               `json:"startedAt"`
+	FinishedAt  time.Time               `json:"finishedAt"`
+	UsedPotions map[data.PotionType]int `json:"usedPotions,omitempty"`
+	MercPotions int                     `json:"mercPotions,omitempty"`
+	Drops       []data.Drop             `json:"drops,omitempty"`
+}
+
+func (r Record) Duration() time.Duration {
+	return durationBetween(r.StartedAt, r.FinishedAt)
+}
+
+func (r RunRecord) Duration() time.Duration {
+	return durationBetween(r.StartedAt, r.FinishedAt)
+}
+
+func durationBetween(start, end time.Time) time.Duration {
+	if start.IsZero() || end.IsZero() || end.Before(start) {
+		return 0
+	}
+
+	return end.Sub(start)
+}
+
+// Dir returns the folder where the game history is stored, next to the droplogs.
+func Dir() string {
+	base := config.Koolo.LogSaveDirectory
+	if base == "" {
+		base = "logs"
+	}
+
+	return filepath.Join(base, "stats")
+}
+
+// Writer builds a Record per supervisor from the event bus and appends it to disk once the game is finished.
+type Writer struct {
+	logDir  string
+	logger  *slog.Logger
+	current map[string]*Record
+}
+
+func NewWriter(logDir string, logger *slog.Logger) *Writer {
+	return &Writer{
+		logDir:  logDir,
+		logger:  logger,
+		current: make(map[string]*Record),
+	}
+}
+
+// Handle subscribes to the event bus, it's meant to be registered once and shared by all the supervisors.
+func (w *Writer) Handle(_ context.Context, e event.Event) error {
+	sup := e.Supervisor()
+	if sup == "" {
+		return nil
+	}
+
+	switch evt := e.(type) {
+	case event.GameCreatedEvent:
+		rec := &Record{
+			Supervisor: sup,
+			StartedAt:  evt.OccurredAt(),
+		}
+		if cfg, found := config.GetCharacter(sup); found && cfg != nil {
+			rec.Character = cfg.CharacterName
+			rec.Profile = cfg.ConfigFolderName
+		}
+		w.current[sup] = rec
+
+	case event.RunStartedEvent:
+		if rec, found := w.current[sup]; found {
+			rec.Runs = append(rec.Runs, RunRecord{
+				Name:      evt.RunName,
+				StartedAt: evt.OccurredAt(),
+			})
+		}
+
+	case event.RunFinishedEvent:
+		if run := w.lastRun(sup); run != nil {
+			run.FinishedAt = evt.OccurredAt()
+			run.Reason = evt.Reason
+		}
+
+	case event.UsedPotionEvent:
+		if run := w.lastRun(sup); run != nil {
+			if evt.OnMerc {
+				run.MercPotions++
+				break
+			}
+			if run.UsedPotions == nil {
+				run.UsedPotions = make(map[data.PotionType]int)
+			}
+			run.UsedPotions[evt.PotionType]++
+		}
+
+	case event.ItemStashedEvent:
+		// Items are stashed during PostRun or when going back to town, so they belong to the last started run
+		if run := w.lastRun(sup); run != nil {
+			run.Drops = append(run.Drops, evt.Item)
+		}
+
+	case event.GameFinishedEvent:
+		rec, found := w.current[sup]
+		if !found {
+			return nil
+		}
+		delete(w.current, sup)
+
+		rec.FinishedAt = evt.OccurredAt()
+		rec.Reason = evt.Reason
+		w.write(*rec)
+	}
+
+	return nil
+}
+
+func (w *Writer) lastRun(supervisor string) *RunRecord {
+	rec, found := w.current[supervisor]
+	if !found || len(rec.Runs) == 0 {
+		return nil
+	}
+
+	return &rec.Runs[len(rec.Runs)-1]
+}
+
+func (w *Writer) write(rec Record) {
+	dir := filepath.Join(w.logDir, rec.Supervisor)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		w.logger.Error("Failed to create stats directory", slog.Any("error", err), slog.String("dir", dir))
+		return // don't break the bot because of logging errors
+	}
+
+	// Daily rotation by the date the game started
+	file := filepath.Join(dir, fmt.Sprintf("stats-%s.jsonl", rec.StartedAt.Format("2006-01-02")))
+	f, err := os.OpenFile(file, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		w.logger.Error("Failed to open stats file", slog.Any("error", err), slog.String("file", file))
+		return
+	}
+	defer f.Close()
+
+	enc, err := json.Marshal(rec)
+	if err != nil {
+		w.logger.Error("Failed to encode stats record", slog.Any("error", err))
+		return
+	}
+	if _, err = f.Write(append(enc, '\n')); err != nil {
+		w.logger.Error("Failed to write stats record", slog.Any("error", err))
+	}
+}
+
+// Filter narrows down the records returned by ReadAll, zero values match everything.
+type Filter struct {
+	Supervisor string
+	Profile    string
+	Run        string
+	From       time.Time
+	To         time.Time
+}
+
+func (f Filter) matches(rec Record) bool {
+	if f.Supervisor != "" && !strings.EqualFold(f.Supervisor, rec.Supervisor) {
+		return false
+	}
+	if f.Profile != "" && !strings.EqualFold(f.Profile, rec.Profile) {
+		return false
+	}
+	if !f.From.IsZero() && rec.StartedAt.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && !rec.StartedAt.Before(f.To) {
+		return false
+	}
+	if f.Run != "" {
+		for _, r := range rec.Runs {
+			if strings.EqualFold(f.Run, r.Name) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return true
+}
+
+// ReadAll scans every supervisor folder for stats-*.jsonl files and returns the records matching the filter,
+// sorted by game start time.
+func ReadAll(logDir string, f Filter) ([]Record, error) {
+	pattern := filepath.Join(logDir, "*", "stats-*.jsonl")
+	if f.Supervisor != "" {
+		pattern = filepath.Join(logDir, f.Supervisor, "stats-*.jsonl")
+	}
+
+	files, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]Record, 0)
+	for _, fpath := range files {
+		// Skip files for days out of the requested range, the file date is the day the game started
+		if day, err := time.ParseInLocation("2006-01-02", strings.TrimSuffix(strings.TrimPrefix(filepath.Base(fpath), "stats-"), ".jsonl"), time.Local); err == nil {
+			if !f.From.IsZero() && day.AddDate(0, 0, 1).Before(f.From) {
+				continue
+			}
+			if !f.To.IsZero() && day.After(f.To) {
+				continue
+			}
+		}
+
+		file, err := os.Open(fpath)
+		if err != nil {
+			continue
+		}
+		scanner := bufio.NewScanner(file)
+		scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" {
+				continue
+			}
+			var rec Record
+			if err := json.Unmarshal([]byte(line), &rec); err == nil && f.matches(rec) {
+				out = append(out, rec)
+			}
+		}
+		file.Close()
+	}
+
+	sort.SliceStable(out, func(i, j int) bool { return out[i].StartedAt.Before(out[j].StartedAt) })
+
+	return out, nil
+}
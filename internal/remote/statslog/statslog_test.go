@@ -0,0 +1,245 @@
+package statslog
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/item"
+	"github.com/hectorgimenez/koolo/internal/config"
+	"github.com/hectorgimenez/koolo/internal/event"
+)
+
+var testLogger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+// at builds an event that happened at a fixed time, going through a record like the journal does
+func at(t *testing.T, e event.Event, occurred time.Time) event.Event {
+	t.Helper()
+
+	rec, err := event.NewRecord(e)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	rec.OccurredAt = occurred
+	out, err := rec.Event(e.Image())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	return out
+}
+
+// testHistory writes two games of sorc on different days, leaves a game of pally open and returns the writer
+func testHistory(t *testing.T, dir string) *Writer {
+	t.Helper()
+
+	characters := config.Characters
+	config.Characters = map[string]*config.CharacterCfg{"sorc": {CharacterName: "SorcChar", ConfigFolderName: "blizz"}}
+	t.Cleanup(func() { config.Characters = characters })
+
+	day1 := time.Date(2025, 1, 10, 10, 0, 0, 0, time.Local)
+	day2 := day1.AddDate(0, 0, 1)
+	ber := data.Drop{Item: data.Item{ID: item.GetIDByName("BerRune"), Name: "BerRune"}, RuleFile: "runes.nip:1"}
+	ring := data.Drop{Item: data.Item{ID: item.GetIDByName("Ring"), Name: "Ring", Quality: item.QualityMagic}}
+
+	w := NewWriter(dir, testLogger)
+	events := []event.Event{
+		at(t, event.GameCreated(event.Text("sorc", "New game created"), "game-1", "pass"), day1),
+		at(t, event.GameCreated(event.Text("pally", "New game created"), "game-2", "pass"), day1),
+		at(t, event.RunStarted(event.Text("sorc", "Starting run"), "pindleskin"), day1.Add(time.Minute)),
+		at(t, event.UsedPotion(event.Text("sorc", ""), data.HealingPotion, false), day1.Add(2*time.Minute)),
+		at(t, event.UsedPotion(event.Text("sorc", ""), data.HealingPotion, false), day1.Add(2*time.Minute)),
+		at(t, event.UsedPotion(event.Text("sorc", ""), data.RejuvenationPotion, true), day1.Add(2*time.Minute)),
+		at(t, event.RunStarted(event.Text("pally", "Starting run"), "cows"), day1.Add(2*time.Minute)),
+		at(t, event.RunFinished(event.Text("sorc", ""), "pindleskin", event.FinishedOK), day1.Add(5*time.Minute)),
+		// Stashed during PostRun, after the run finished
+		at(t, event.ItemStashed(event.Text("sorc", "Item stashed"), ber), day1.Add(6*time.Minute)),
+		at(t, event.RunStarted(event.Text("sorc", "Starting run"), "mephisto"), day1.Add(7*time.Minute)),
+		at(t, event.ItemStashed(event.Text("sorc", "Item stashed"), ring), day1.Add(8*time.Minute)),
+		at(t, event.RunFinished(event.Text("sorc", ""), "mephisto", event.FinishedChicken), day1.Add(10*time.Minute)),
+		at(t, event.GameFinished(event.Text("sorc", ""), event.FinishedChicken), day1.Add(11*time.Minute)),
+		// Events of a game that was never created, or without supervisor, are ignored
+		at(t, event.RunStarted(event.Text("mule", "Starting run"), "pit"), day1.Add(12*time.Minute)),
+		at(t, event.ItemStashed(event.Text("mule", "Item stashed"), ber), day1.Add(13*time.Minute)),
+		at(t, event.GameFinished(event.Text("mule", ""), event.FinishedOK), day1.Add(14*time.Minute)),
+		at(t, event.GameCreated(event.Text("", ""), "game-3", "pass"), day1),
+		at(t, event.GameCreated(event.Text("sorc", "New game created"), "game-4", "pass"), day2),
+		at(t, event.RunStarted(event.Text("sorc", "Starting run"), "mephisto"), day2.Add(time.Minute)),
+		at(t, event.RunFinished(event.Text("sorc", ""), "mephisto", event.FinishedOK), day2.Add(4*time.Minute)),
+		at(t, event.GameFinished(event.Text("sorc", ""), event.FinishedOK), day2.Add(5*time.Minute)),
+	}
+	for _, e := range events {
+		if err := w.Handle(context.Background(), e); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	return w
+}
+
+func TestWriterRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	testHistory(t, dir)
+
+	records, err := ReadAll(dir, Filter{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("Expected the 2 finished games, got %+v", records)
+	}
+
+	game := records[0]
+	if game.Supervisor != "sorc" || game.Character != "SorcChar" || game.Profile != "blizz" || game.Reason != event.FinishedChicken {
+		t.Errorf("Unexpected game %+v", game)
+	}
+	if game.Duration() != 11*time.Minute {
+		t.Errorf("Expected an 11 minutes game, got %v", game.Duration())
+	}
+	if len(game.Runs) != 2 {
+		t.Fatalf("Expected 2 runs, got %+v", game.Runs)
+	}
+
+	pindle, meph := game.Runs[0], game.Runs[1]
+	if pindle.Name != "pindleskin" || pindle.Reason != event.FinishedOK || pindle.Duration() != 4*time.Minute {
+		t.Errorf("Unexpected run %+v", pindle)
+	}
+	if pindle.UsedPotions[data.HealingPotion] != 2 || pindle.UsedPotions[data.RejuvenationPotion] != 0 || pindle.MercPotions != 1 {
+		t.Errorf("Expected 2 healing potions and 1 merc potion, got %v and %d", pindle.UsedPotions, pindle.MercPotions)
+	}
+	if len(pindle.Drops) != 1 || pindle.Drops[0].Item.Name != "BerRune" || pindle.Drops[0].RuleFile != "runes.nip:1" {
+		t.Errorf("Expected the rune stashed after the run, got %+v", pindle.Drops)
+	}
+	if meph.Name != "mephisto" || meph.Reason != event.FinishedChicken || len(meph.Drops) != 1 || meph.UsedPotions != nil {
+		t.Errorf("Unexpected run %+v", meph)
+	}
+
+	if _, err = os.Stat(filepath.Join(dir, "sorc", "stats-2025-01-11.jsonl")); err != nil {
+		t.Errorf("Expected a file per day: %v", err)
+	}
+	if _, err = os.Stat(filepath.Join(dir, "mule")); !os.IsNotExist(err) {
+		t.Errorf("Expected nothing written for a game never created, got %v", err)
+	}
+}
+
+func TestWriterOpenGames(t *testing.T) {
+	dir := t.TempDir()
+	w := testHistory(t, dir)
+
+	// Only the game of pally is still being played
+	if len(w.current) != 1 {
+		t.Fatalf("Expected a single open game, got %v", w.current)
+	}
+	open, found := w.current["pally"]
+	if !found || len(open.Runs) != 1 || open.Runs[0].Name != "cows" || !open.Runs[0].FinishedAt.IsZero() {
+		t.Fatalf("Unexpected open game %+v", open)
+	}
+	if run := w.lastRun("pally"); run == nil || run.Name != "cows" {
+		t.Errorf("Expected cows as the last run, got %+v", run)
+	}
+	if run := w.lastRun("sorc"); run != nil {
+		t.Errorf("Expected no run once the game is finished, got %+v", run)
+	}
+	if records, _ := ReadAll(dir, Filter{Supervisor: "pally"}); len(records) != 0 {
+		t.Errorf("Expected the open game not to be written, got %+v", records)
+	}
+
+	// A new game replaces the one that never finished, like after a crash
+	restarted := time.Date(2025, 1, 12, 10, 0, 0, 0, time.Local)
+	w.Handle(context.Background(), at(t, event.GameCreated(event.Text("pally", "New game created"), "game-5", "pass"), restarted))
+	w.Handle(context.Background(), at(t, event.GameFinished(event.Text("pally", ""), event.FinishedOK), restarted.Add(time.Minute)))
+	records, err := ReadAll(dir, Filter{Supervisor: "pally"})
+	if err != nil || len(records) != 1 || len(records[0].Runs) != 0 || !records[0].StartedAt.Equal(restarted) {
+		t.Errorf("Unexpected records %+v %v", records, err)
+	}
+	if len(w.current) != 0 {
+		t.Errorf("Expected no open games, got %v", w.current)
+	}
+}
+
+func TestReadAllFilters(t *testing.T) {
+	dir := t.TempDir()
+	testHistory(t, dir)
+
+	// Broken lines are skipped
+	f, err := os.OpenFile(filepath.Join(dir, "sorc", "stats-2025-01-10.jsonl"), os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	f.WriteString("{not json\n\n")
+	f.Close()
+
+	day2 := time.Date(2025, 1, 11, 0, 0, 0, 0, time.Local)
+	tests := []struct {
+		name     string
+		filter   Filter
+		expected int
+	}{
+		{"all", Filter{}, 2},
+		{"supervisor", Filter{Supervisor: "sorc"}, 2},
+		{"other supervisor", Filter{Supervisor: "pally"}, 0},
+		{"profile", Filter{Profile: "BLIZZ"}, 2},
+		{"run", Filter{Run: "Pindleskin"}, 1},
+		{"from", Filter{From: day2}, 1},
+		{"to", Filter{To: day2}, 1},
+		{"empty range", Filter{From: day2.AddDate(0, 0, 1)}, 0},
+	}
+
+	for _, tt := range tests {
+		records, err := ReadAll(dir, tt.filter)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.name, err)
+		}
+		if len(records) != tt.expected {
+			t.Errorf("%s: expected %d records, got %d", tt.name, tt.expected, len(records))
+		}
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	dir := t.TempDir()
+	testHistory(t, dir)
+	records, err := ReadAll(dir, Filter{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		groupBy  GroupBy
+		key      string
+		games    int
+		runs     int
+		chickens int
+		drops    int
+		potions  int
+		seconds  float64
+	}{
+		{GroupByDay, "2025-01-10", 1, 2, 1, 2, 3, 660},
+		{GroupByDay, "2025-01-11", 1, 1, 0, 0, 0, 300},
+		{GroupByProfile, "blizz", 2, 3, 1, 2, 3, 960},
+		// Grouping by run only counts the time of the runs
+		{GroupByRun, "mephisto", 2, 2, 1, 1, 0, 360},
+		{GroupByRun, "pindleskin", 1, 1, 0, 1, 3, 240},
+	}
+
+	for _, tt := range tests {
+		var s *Summary
+		for _, sum := range Summarize(records, tt.groupBy) {
+			if sum.Key == tt.key {
+				s = &sum
+			}
+		}
+		if s == nil {
+			t.Errorf("%s: expected a summary for %s", tt.groupBy, tt.key)
+			continue
+		}
+		if s.Games != tt.games || s.Runs != tt.runs || s.Chickens != tt.chickens || s.Drops != tt.drops || s.UsedPotions != tt.potions || s.TotalSeconds != tt.seconds {
+			t.Errorf("%s: unexpected summary for %s %+v", tt.groupBy, tt.key, *s)
+		}
+	}
+}
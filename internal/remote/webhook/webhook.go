@@ -0,0 +1,281 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/hectorgimenez/koolo/internal/config"
+	"github.com/hectorgimenez/koolo/internal/event"
+)
+
+const (
+	EventGameCreated  = "game_created"
+	EventGameFinished = "game_finished"
+	EventRunStarted   = "run_started"
+	EventRunFinished  = "run_finished"
+	EventItemStashed  = "item_stashed"
+	EventError        = "error" // Game finished due to an error
+
+	SignatureHeader = "X-Koolo-Signature"
+	EventHeader     = "X-Koolo-Event"
+
+	queueSize      = 100
+	defaultRetries = 3
+	defaultTimeout = 10 * time.Second
+	maxBackoff     = 30 * time.Second
+)
+
+// Payload is the data sent to the webhook, it's also the data available in custom templates.
+type Payload struct {
+	Event      string         `json:"event"`
+	Supervisor string         `json:"supervisor"`
+	Message    string         `json:"message"`
+	OccurredAt time.Time      `json:"occurredAt"`
+	Data       map[string]any `json:"data,omitempty"`
+}
+
+type delivery struct {
+	event string
+	body  []byte
+}
+
+// Notifier POSTs the selected events to a generic webhook, deliveries are queued and retried with exponential
+// backoff so a slow endpoint never blocks the event listener.
+type Notifier struct {
+	cfg      config.Webhook
+	logger   *slog.Logger
+	client   *http.Client
+	tpl      *template.Template
+	queue    chan delivery
+	backoff  time.Duration
+	maxTries int
+}
+
+func NewNotifier(cfg config.Webhook, logger *slog.Logger) (*Notifier, error) {
+	if cfg.URL == "" {
+		return nil, errors.New("webhook url is required")
+	}
+
+	n := &Notifier{
+		cfg:      cfg,
+		logger:   logger.With(slog.String("webhook", cfg.Name)),
+		queue:    make(chan delivery, queueSize),
+		backoff:  time.Second,
+		maxTries: defaultRetries + 1,
+	}
+	// Not set means the default, 0 is a valid value that disables the retries
+	if cfg.MaxRetries != nil && *cfg.MaxRetries >= 0 {
+		n.maxTries = *cfg.MaxRetries + 1
+	}
+
+	timeout := defaultTimeout
+	if cfg.TimeoutSeconds > 0 {
+		timeout = time.Duration(cfg.TimeoutSeconds) * time.Second
+	}
+	n.client = &http.Client{Timeout: timeout}
+
+	if cfg.Template != "" {
+		tpl, err := template.New(cfg.Name).Funcs(template.FuncMap{"json": toJSON}).Parse(cfg.Template)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing webhook template: %w", err)
+		}
+		n.tpl = tpl
+	}
+
+	for _, e := range cfg.Events {
+		switch e {
+		case EventGameCreated, EventGameFinished, EventRunStarted, EventRunFinished, EventItemStashed, EventError:
+		default:
+			return nil, fmt.Errorf("unknown webhook event: %s", e)
+		}
+	}
+
+	return n, nil
+}
+
+// Start delivers the queued events until the context is done.
+func (n *Notifier) Start(ctx context.Context) error {
+	for {
+		select {
+		case d := <-n.queue:
+			if err := n.deliver(ctx, d); err != nil {
+				n.logger.Error("Failed to deliver webhook", slog.String("event", d.event), slog.Any("error", err))
+			}
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// Handle subscribes to the event bus and queues the selected events.
+func (n *Notifier) Handle(_ context.Context, e event.Event) error {
+	p, ok := buildPayload(e)
+	if !ok || !n.selected(p.Event) {
+		return nil
+	}
+
+	body, err := n.render(p)
+	if err != nil {
+		return fmt.Errorf("error rendering webhook payload: %w", err)
+	}
+
+	select {
+	case n.queue <- delivery{event: p.Event, body: body}:
+	default:
+		n.logger.Warn("Webhook queue is full, dropping event", slog.String("event", p.Event))
+	}
+
+	return nil
+}
+
+func (n *Notifier) selected(name string) bool {
+	if len(n.cfg.Events) == 0 {
+		return true
+	}
+	if slices.Contains(n.cfg.Events, name) {
+		return true
+	}
+
+	// Errors are game finished events too
+	return name == EventError && slices.Contains(n.cfg.Events, EventGameFinished)
+}
+
+func (n *Notifier) render(p Payload) ([]byte, error) {
+	if n.tpl == nil {
+		return json.Marshal(p)
+	}
+
+	buf := new(bytes.Buffer)
+	if err := n.tpl.Execute(buf, p); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (n *Notifier) deliver(ctx context.Context, d delivery) error {
+	var err error
+	wait := n.backoff
+	for try := 1; try <= n.maxTries; try++ {
+		var retry bool
+		retry, err = n.post(ctx, d)
+		if err == nil || !retry || try == n.maxTries {
+			return err
+		}
+
+		n.logger.Debug("Webhook delivery failed, retrying", slog.Int("try", try), slog.Duration("wait", wait), slog.Any("error", err))
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		wait = min(wait*2, maxBackoff)
+	}
+
+	return err
+}
+
+// post sends the request, the returned bool tells if the request can be retried.
+func (n *Notifier) post(ctx context.Context, d delivery) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.cfg.URL, bytes.NewReader(d.body))
+	if err != nil {
+		return false, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Koolo/"+config.Version)
+	req.Header.Set(EventHeader, d.event)
+	for k, v := range n.cfg.Headers {
+		req.Header.Set(k, v)
+	}
+	if n.cfg.Secret != "" {
+		req.Header.Set(SignatureHeader, "sha256="+Sign(n.cfg.Secret, d.body))
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+
+	retry := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+	return retry, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+}
+
+// Sign returns the hex encoded HMAC-SHA256 of the body, receivers can compare it with the X-Koolo-Signature header.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func buildPayload(e event.Event) (Payload, bool) {
+	p := Payload{
+		Supervisor: e.Supervisor(),
+		Message:    e.Message(),
+		OccurredAt: e.OccurredAt(),
+	}
+
+	switch evt := e.(type) {
+	case event.GameCreatedEvent:
+		p.Event = EventGameCreated
+		p.Data = map[string]any{"game": evt.Name}
+	case event.GameFinishedEvent:
+		p.Event = EventGameFinished
+		if evt.Reason == event.FinishedError {
+			p.Event = EventError
+		}
+		p.Data = map[string]any{"reason": evt.Reason}
+	case event.RunStartedEvent:
+		p.Event = EventRunStarted
+		p.Data = map[string]any{"run": evt.RunName}
+	case event.RunFinishedEvent:
+		p.Event = EventRunFinished
+		p.Data = map[string]any{"run": evt.RunName, "reason": evt.Reason}
+	case event.ItemStashedEvent:
+		p.Event = EventItemStashed
+		itm := evt.Item.Item
+		stats := make([]string, 0, len(itm.Stats))
+		for _, s := range itm.Stats {
+			if str := strings.TrimSpace(s.String()); str != "" {
+				stats = append(stats, str)
+			}
+		}
+		p.Data = map[string]any{
+			"item":           string(itm.Name),
+			"identifiedName": itm.IdentifiedName,
+			"quality":        itm.Quality.ToString(),
+			"ethereal":       itm.Ethereal,
+			"stats":          stats,
+			"rule":           evt.Item.Rule,
+			"ruleFile":       evt.Item.RuleFile,
+			"location":       evt.Item.DropLocation,
+		}
+	default:
+		return p, false
+	}
+
+	return p, true
+}
+
+func toJSON(v any) (string, error) {
+	b, err := json.Marshal(v)
+	return string(b), err
+}
@@ -0,0 +1,185 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/item"
+	"github.com/hectorgimenez/koolo/internal/config"
+	"github.com/hectorgimenez/koolo/internal/event"
+)
+
+func newTestNotifier(t *testing.T, cfg config.Webhook) *Notifier {
+	t.Helper()
+
+	n, err := NewNotifier(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	n.backoff = time.Millisecond
+
+	return n
+}
+
+// send runs the event through the notifier and delivers it synchronously, returns false if it was not queued.
+func send(t *testing.T, n *Notifier, e event.Event) (bool, error) {
+	t.Helper()
+
+	if err := n.Handle(context.Background(), e); err != nil {
+		t.Fatalf("Unexpected error handling event: %v", err)
+	}
+
+	select {
+	case d := <-n.queue:
+		return true, n.deliver(context.Background(), d)
+	default:
+		return false, nil
+	}
+}
+
+func TestNotifierSignsDefaultPayload(t *testing.T) {
+	var body []byte
+	var signature, eventName string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		signature = r.Header.Get(SignatureHeader)
+		eventName = r.Header.Get(EventHeader)
+	}))
+	defer srv.Close()
+
+	n := newTestNotifier(t, config.Webhook{URL: srv.URL, Secret: "s3cr3t"})
+	drop := data.Drop{Item: data.Item{Name: "BerRune", Quality: item.QualityNormal}, Rule: "[name] == berrune", RuleFile: "runes.nip"}
+	if _, err := send(t, n, event.ItemStashed(event.Text("sorc", "Item stashed"), drop)); err != nil {
+		t.Fatalf("Unexpected delivery error: %v", err)
+	}
+
+	if signature != "sha256="+Sign("s3cr3t", body) {
+		t.Errorf("Invalid signature %q", signature)
+	}
+	if eventName != EventItemStashed {
+		t.Errorf("Expected %s event header, got %q", EventItemStashed, eventName)
+	}
+
+	var p Payload
+	if err := json.Unmarshal(body, &p); err != nil {
+		t.Fatalf("Invalid JSON payload: %v", err)
+	}
+	if p.Event != EventItemStashed || p.Supervisor != "sorc" || p.Data["item"] != "BerRune" || p.Data["rule"] != "[name] == berrune" {
+		t.Errorf("Unexpected payload %+v", p)
+	}
+}
+
+func TestNotifierRetriesServerErrors(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer srv.Close()
+
+	retries := 3
+	n := newTestNotifier(t, config.Webhook{URL: srv.URL, MaxRetries: &retries})
+	if _, err := send(t, n, event.RunFinished(event.Text("sorc", ""), "pit", event.FinishedOK)); err != nil {
+		t.Fatalf("Expected delivery to succeed after retries, got %v", err)
+	}
+	if calls.Load() != 3 {
+		t.Errorf("Expected 3 calls, got %d", calls.Load())
+	}
+}
+
+func TestNotifierGivesUp(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if r.Header.Get("Authorization") == "Bearer token" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	// Client errors are not retried
+	n := newTestNotifier(t, config.Webhook{URL: srv.URL})
+	if _, err := send(t, n, event.RunFinished(event.Text("sorc", ""), "pit", event.FinishedOK)); err == nil {
+		t.Errorf("Expected delivery error")
+	}
+	if calls.Load() != 1 {
+		t.Errorf("Expected a single call for client errors, got %d", calls.Load())
+	}
+
+	// Server errors are retried up to MaxRetries
+	calls.Store(0)
+	retries := 2
+	n = newTestNotifier(t, config.Webhook{URL: srv.URL, MaxRetries: &retries, Headers: map[string]string{"Authorization": "Bearer token"}})
+	if _, err := send(t, n, event.RunFinished(event.Text("sorc", ""), "pit", event.FinishedOK)); err == nil {
+		t.Errorf("Expected delivery error")
+	}
+	if calls.Load() != 3 {
+		t.Errorf("Expected 3 calls, got %d", calls.Load())
+	}
+
+	// Set to 0 the retries are disabled, not replaced by the default
+	calls.Store(0)
+	retries = 0
+	n = newTestNotifier(t, config.Webhook{URL: srv.URL, MaxRetries: &retries, Headers: map[string]string{"Authorization": "Bearer token"}})
+	if _, err := send(t, n, event.RunFinished(event.Text("sorc", ""), "pit", event.FinishedOK)); err == nil {
+		t.Errorf("Expected delivery error")
+	}
+	if calls.Load() != 1 {
+		t.Errorf("Expected a single call with retries disabled, got %d", calls.Load())
+	}
+}
+
+func TestNotifierTemplateAndEventFilter(t *testing.T) {
+	var body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+	}))
+	defer srv.Close()
+
+	n := newTestNotifier(t, config.Webhook{
+		URL:      srv.URL,
+		Events:   []string{EventRunFinished, EventGameFinished},
+		Template: `{"text": {{ json (printf "%s: %s" .Supervisor .Message) }}, "event": "{{ .Event }}"}`,
+	})
+
+	if queued, _ := send(t, n, event.RunStarted(event.Text("sorc", "Starting run"), "pit")); queued {
+		t.Errorf("Expected run_started to be filtered out")
+	}
+
+	if _, err := send(t, n, event.RunFinished(event.Text("sorc", `Finished "pit"`), "pit", event.FinishedOK)); err != nil {
+		t.Fatalf("Unexpected delivery error: %v", err)
+	}
+	if body != `{"text": "sorc: Finished \"pit\"", "event": "run_finished"}` {
+		t.Errorf("Unexpected body %s", body)
+	}
+
+	// Errors are game finished events, so they are sent when game_finished is selected
+	if queued, _ := send(t, n, event.GameFinished(event.Text("sorc", "boom"), event.FinishedError)); !queued {
+		t.Errorf("Expected error event to be sent")
+	}
+}
+
+func TestNewNotifierValidatesConfig(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	if _, err := NewNotifier(config.Webhook{}, logger); err == nil {
+		t.Errorf("Expected error for missing url")
+	}
+	if _, err := NewNotifier(config.Webhook{URL: "http://localhost", Events: []string{"unknown"}}, logger); err == nil {
+		t.Errorf("Expected error for unknown event")
+	}
+	if _, err := NewNotifier(config.Webhook{URL: "http://localhost", Template: "{{ .Missing"}, logger); err == nil {
+		t.Errorf("Expected error for invalid template")
+	}
+}
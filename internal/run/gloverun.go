@@ -18,7 +18,6 @@ import (
 	"github.com/hectorgimenez/koolo/internal/game"
 	"github.com/hectorgimenez/koolo/internal/town"
 	"github.com/hectorgimenez/koolo/internal/ui"
-	"github.com/lxn/win"
 )
 
 var anyaLocation = data.Position{
@@ -104,7 +103,7 @@ func (g GloveRun) Run() error {
 	for !found {
 		action.InteractNPC(vendorNPC)
 		if vendorNPC == npc.Drehya {
-			g.ctx.HID.KeySequence(win.VK_HOME, win.VK_DOWN, win.VK_RETURN)
+			g.ctx.HID.KeySequence(game.KeyHome, game.KeyArrowDown, game.KeyReturn)
 		}
 		if g.ctx.Data.OpenMenus.NPCShop {
 			for _, itm := range g.ctx.Data.Inventory.ByLocation(item.LocationVendor) {
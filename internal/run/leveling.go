@@ -135,7 +135,7 @@ func (a Leveling) AdjustGameDifficulty() error {
 	if difficultyChanged {
 		a.ctx.Logger.Info("Difficulty changed to %s. Saving character configuration...", a.ctx.CharacterCfg.Game.Difficulty)
 		// Use the new ConfigFolderName field here!
-		if err := config.SaveSupervisorConfig(a.ctx.CharacterCfg.ConfigFolderName, a.ctx.CharacterCfg); err != nil {
+		if err := config.SaveSupervisorConfig(a.ctx.CharacterCfg.ConfigFolderName, a.ctx.CharacterCfg, "leveling difficulty change"); err != nil {
 			a.ctx.Logger.Error("Failed to save character configuration: %s", err.Error())
 			return fmt.Errorf("failed to save character configuration: %w", err)
 		}
@@ -207,7 +207,7 @@ func (a Leveling) setupLevelOneConfig() {
 		levelingCharacter.InitialCharacterConfigSetup()
 	}
 
-	if err := config.SaveSupervisorConfig(a.ctx.CharacterCfg.ConfigFolderName, a.ctx.CharacterCfg); err != nil {
+	if err := config.SaveSupervisorConfig(a.ctx.CharacterCfg.ConfigFolderName, a.ctx.CharacterCfg, "leveling setup"); err != nil {
 		a.ctx.Logger.Error(fmt.Sprintf("Failed to save character configuration: %s", err.Error()))
 	}
 }
@@ -269,7 +269,7 @@ func (a Leveling) AdjustDifficultyConfig() {
 		levelingCharacter.AdjustCharacterConfig()
 	}
 
-	if err := config.SaveSupervisorConfig(a.ctx.CharacterCfg.ConfigFolderName, a.ctx.CharacterCfg); err != nil {
+	if err := config.SaveSupervisorConfig(a.ctx.CharacterCfg.ConfigFolderName, a.ctx.CharacterCfg, "leveling"); err != nil {
 		a.ctx.Logger.Error(fmt.Sprintf("Failed to save character configuration: %s", err.Error()))
 	}
 }
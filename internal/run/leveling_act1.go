@@ -19,7 +19,6 @@ import (
 	"github.com/hectorgimenez/koolo/internal/town"
 	"github.com/hectorgimenez/koolo/internal/ui"
 	"github.com/hectorgimenez/koolo/internal/utils"
-	"github.com/lxn/win"
 )
 
 // act1 is the main function for Act 1 leveling
@@ -130,7 +129,7 @@ func (a Leveling) act1() error {
 		a.ctx.CharacterCfg.Character.UseMerc = true
 
 		action.InteractNPC(npc.Kashya)
-		if err := config.SaveSupervisorConfig(a.ctx.CharacterCfg.ConfigFolderName, a.ctx.CharacterCfg); err != nil {
+		if err := config.SaveSupervisorConfig(a.ctx.CharacterCfg.ConfigFolderName, a.ctx.CharacterCfg, "leveling act 1"); err != nil {
 			a.ctx.Logger.Error(fmt.Sprintf("Failed to save character configuration: %s", err.Error()))
 
 		}
@@ -163,7 +162,7 @@ func (a Leveling) act1() error {
 			a.ctx.CharacterCfg.Character.ClearPathDist = 20
 		}
 
-		if err := config.SaveSupervisorConfig(a.ctx.CharacterCfg.ConfigFolderName, a.ctx.CharacterCfg); err != nil {
+		if err := config.SaveSupervisorConfig(a.ctx.CharacterCfg.ConfigFolderName, a.ctx.CharacterCfg, "leveling act 1"); err != nil {
 			a.ctx.Logger.Error(fmt.Sprintf("Failed to save character configuration: %s", err.Error()))
 		}
 
@@ -208,7 +207,7 @@ func (a Leveling) act1() error {
 			}
 
 			a.ctx.CharacterCfg.Inventory.BeltColumns = [4]string{"healing", "healing", "mana", "mana"}
-			if err := config.SaveSupervisorConfig(a.ctx.CharacterCfg.ConfigFolderName, a.ctx.CharacterCfg); err != nil {
+			if err := config.SaveSupervisorConfig(a.ctx.CharacterCfg.ConfigFolderName, a.ctx.CharacterCfg, "leveling act 1"); err != nil {
 				a.ctx.Logger.Error(fmt.Sprintf("Failed to save character configuration: %s", err.Error()))
 
 			}
@@ -236,9 +235,9 @@ func (a Leveling) goToAct2() error {
 	}
 
 	action.InteractNPC(npc.Warriv)
-	a.ctx.HID.KeySequence(win.VK_HOME, win.VK_DOWN, win.VK_RETURN)
+	a.ctx.HID.KeySequence(game.KeyHome, game.KeyArrowDown, game.KeyReturn)
 	utils.Sleep(1000)
-	a.HoldKey(win.VK_SPACE, 2000)
+	a.HoldKey(game.KeySpace, 2000)
 	utils.Sleep(1000)
 	return nil
 }
@@ -341,7 +340,7 @@ func gambleAct1Belt(ctx *context.Status) error {
 	}
 	defer step.CloseAllMenus()
 
-	ctx.HID.KeySequence(win.VK_HOME, win.VK_DOWN, win.VK_DOWN, win.VK_RETURN)
+	ctx.HID.KeySequence(game.KeyHome, game.KeyArrowDown, game.KeyArrowDown, game.KeyReturn)
 	utils.Sleep(1000)
 
 	// Check if the shop menu is open
@@ -16,8 +16,8 @@ import (
 	"github.com/hectorgimenez/koolo/internal/action"
 	"github.com/hectorgimenez/koolo/internal/config"
 	"github.com/hectorgimenez/koolo/internal/context"
+	"github.com/hectorgimenez/koolo/internal/game"
 	"github.com/hectorgimenez/koolo/internal/utils"
-	"github.com/lxn/win"
 )
 
 func ToKeyBinding(keyCode byte) data.KeyBinding {
@@ -65,7 +65,7 @@ func (a Leveling) act4() error {
 
 		harrogathPortal, found := a.ctx.Data.Objects.FindOne(object.LastLastPortal)
 		if !found { // portal was already opened before so we must talk to Tyrael to get to A5
-			a.ctx.HID.KeySequence(win.VK_HOME, win.VK_DOWN, win.VK_RETURN)
+			a.ctx.HID.KeySequence(game.KeyHome, game.KeyArrowDown, game.KeyReturn)
 			// After attempting to open it with key sequence, you should re-check if it's found
 			// If still not found, then it's an error.
 
@@ -79,9 +79,9 @@ func (a Leveling) act4() error {
 
 		// Skip Cinematic
 		utils.Sleep(2000)
-		a.HoldKey(win.VK_SPACE, 2000)
+		a.HoldKey(game.KeySpace, 2000)
 		utils.Sleep(2000)
-		a.HoldKey(win.VK_SPACE, 2000)
+		a.HoldKey(game.KeySpace, 2000)
 
 		return nil
 	}
@@ -106,7 +106,7 @@ func (a Leveling) act4() error {
 		if a.ctx.CharacterCfg.Game.InteractWithShrines {
 			a.ctx.CharacterCfg.Game.InteractWithShrines = false
 
-			if err := config.SaveSupervisorConfig(a.ctx.CharacterCfg.ConfigFolderName, a.ctx.CharacterCfg); err != nil {
+			if err := config.SaveSupervisorConfig(a.ctx.CharacterCfg.ConfigFolderName, a.ctx.CharacterCfg, "leveling act 4"); err != nil {
 				a.ctx.Logger.Error(fmt.Sprintf("Failed to save character configuration: %s", err.Error()))
 			}
 		}
@@ -150,7 +150,7 @@ func (a Leveling) act4() error {
 
 		harrogathPortal, found := a.ctx.Data.Objects.FindOne(object.LastLastPortal)
 		if !found { // portal was already opened before so we must talk to Tyrael to get to A5
-			a.ctx.HID.KeySequence(win.VK_HOME, win.VK_DOWN, win.VK_RETURN)
+			a.ctx.HID.KeySequence(game.KeyHome, game.KeyArrowDown, game.KeyReturn)
 			// After attempting to open it with key sequence, you should re-check if it's found
 			// If still not found, then it's an error.
 
@@ -164,9 +164,9 @@ func (a Leveling) act4() error {
 
 		// Skip Cinematic
 		utils.Sleep(2000)
-		a.HoldKey(win.VK_SPACE, 2000)
+		a.HoldKey(game.KeySpace, 2000)
 		utils.Sleep(2000)
-		a.HoldKey(win.VK_SPACE, 2000)
+		a.HoldKey(game.KeySpace, 2000)
 
 		return nil
 	}
@@ -205,9 +205,9 @@ func (a Leveling) act4() error {
 
 		// Skip Cinematic
 		utils.Sleep(2000)
-		a.HoldKey(win.VK_SPACE, 2000)
+		a.HoldKey(game.KeySpace, 2000)
 		utils.Sleep(2000)
-		a.HoldKey(win.VK_SPACE, 2000)
+		a.HoldKey(game.KeySpace, 2000)
 
 		return nil
 	}
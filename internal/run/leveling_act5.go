@@ -19,7 +19,6 @@ import (
 	"github.com/hectorgimenez/d2go/pkg/data/stat"
 	"github.com/hectorgimenez/koolo/internal/action"
 	"github.com/hectorgimenez/koolo/internal/config" // Make sure this import is present
-	"github.com/lxn/win"
 )
 
 func (a Leveling) act5() error {
@@ -189,7 +188,7 @@ func (a Leveling) act5() error {
 
 			action.InteractNPC(npc.Malah)
 			utils.Sleep(1000)
-			a.ctx.HID.KeySequence(win.VK_HOME, win.VK_DOWN, win.VK_DOWN, win.VK_RETURN)
+			a.ctx.HID.KeySequence(game.KeyHome, game.KeyArrowDown, game.KeyArrowDown, game.KeyReturn)
 			// Adding a longer delay to ensure the game state has time to update
 			utils.Sleep(2500)
 
@@ -231,7 +230,7 @@ func (a Leveling) act5() error {
 		if a.ctx.CharacterCfg.Game.InteractWithShrines {
 			a.ctx.CharacterCfg.Game.InteractWithShrines = false
 
-			if err := config.SaveSupervisorConfig(a.ctx.CharacterCfg.ConfigFolderName, a.ctx.CharacterCfg); err != nil {
+			if err := config.SaveSupervisorConfig(a.ctx.CharacterCfg.ConfigFolderName, a.ctx.CharacterCfg, "leveling act 5"); err != nil {
 				a.ctx.Logger.Error(fmt.Sprintf("Failed to save character configuration: %s", err.Error()))
 			}
 		}
@@ -18,7 +18,6 @@ import (
 	"github.com/hectorgimenez/koolo/internal/game"
 	"github.com/hectorgimenez/koolo/internal/ui"
 	"github.com/hectorgimenez/koolo/internal/utils"
-	"github.com/lxn/win"
 )
 
 type Quests struct {
@@ -95,7 +94,7 @@ func (a Quests) clearDenQuest() error {
 	}
 
 	a.ctx.CharacterCfg.Character.ClearPathDist = 20
-	if err := config.SaveSupervisorConfig(a.ctx.CharacterCfg.ConfigFolderName, a.ctx.CharacterCfg); err != nil {
+	if err := config.SaveSupervisorConfig(a.ctx.CharacterCfg.ConfigFolderName, a.ctx.CharacterCfg, "quests"); err != nil {
 		a.ctx.Logger.Error("Failed to save character configuration: %s", err.Error())
 	}
 
@@ -133,7 +132,7 @@ func (a Quests) rescueCainQuest() error {
 	}
 
 	a.ctx.CharacterCfg.Character.ClearPathDist = 20
-	if err := config.SaveSupervisorConfig(a.ctx.CharacterCfg.ConfigFolderName, a.ctx.CharacterCfg); err != nil {
+	if err := config.SaveSupervisorConfig(a.ctx.CharacterCfg.ConfigFolderName, a.ctx.CharacterCfg, "quests"); err != nil {
 		a.ctx.Logger.Error("Failed to save character configuration: %s", err.Error())
 	}
 
@@ -143,7 +142,7 @@ func (a Quests) rescueCainQuest() error {
 	}
 
 	a.ctx.CharacterCfg.Character.ClearPathDist = 30
-	if err := config.SaveSupervisorConfig(a.ctx.CharacterCfg.ConfigFolderName, a.ctx.CharacterCfg); err != nil {
+	if err := config.SaveSupervisorConfig(a.ctx.CharacterCfg.ConfigFolderName, a.ctx.CharacterCfg, "quests"); err != nil {
 		a.ctx.Logger.Error("Failed to save character configuration: %s", err.Error())
 	}
 
@@ -864,7 +863,7 @@ func (a Quests) killAncientsQuest() error {
 	utils.Sleep(1000)
 	a.ctx.HID.Click(game.LeftButton, 720, 260)
 	utils.Sleep(1000)
-	a.ctx.HID.PressKey(win.VK_RETURN)
+	a.ctx.HID.PressKey(game.KeyReturn)
 	utils.Sleep(2000)
 
 	// Modify the configuration for the Ancients fight
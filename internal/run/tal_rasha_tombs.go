@@ -47,7 +47,7 @@ func (a TalRashaTombs) Run() error {
 		action.Buff()
 
 								a.ctx.CharacterCfg.Character.ClearPathDist = 20
-	if err := config.SaveSupervisorConfig(a.ctx.CharacterCfg.ConfigFolderName, a.ctx.CharacterCfg); err != nil {
+	if err := config.SaveSupervisorConfig(a.ctx.CharacterCfg.ConfigFolderName, a.ctx.CharacterCfg, "tal rasha tombs"); err != nil {
 		a.ctx.Logger.Error("Failed to save character configuration: %s", err.Error())}
 
 		// Clear the Tomb
@@ -92,7 +92,12 @@ func (tz TerrorZone) Run() error {
 				}
 			}
 			if slices.Contains(availableTzs, tzArea) {
-				action.ClearCurrentLevel(tz.ctx.CharacterCfg.Game.TerrorZone.OpenChests, tz.customTZEnemyFilter())
+				// Finish the clear close to the next area of the group
+				if exit, found := tz.exitTo(tzAreaGroup, k+1); found {
+					action.ClearCurrentLevelTo(tz.ctx.CharacterCfg.Game.TerrorZone.OpenChests, tz.customTZEnemyFilter(), exit)
+				} else {
+					action.ClearCurrentLevel(tz.ctx.CharacterCfg.Game.TerrorZone.OpenChests, tz.customTZEnemyFilter())
+				}
 			} else {
 				tz.ctx.Logger.Debug("Skipping area %v", tzArea.Area().Name)
 			}
@@ -102,6 +107,20 @@ func (tz TerrorZone) Run() error {
 	return nil
 }
 
+// exitTo returns the entrance to the area at position next of the group, from the current area
+func (tz TerrorZone) exitTo(group []area.ID, next int) (data.Position, bool) {
+	if next >= len(group) {
+		return data.Position{}, false
+	}
+	for _, lvl := range tz.ctx.Data.AdjacentLevels {
+		if lvl.Area == group[next] && (lvl.Position.X != 0 || lvl.Position.Y != 0) {
+			return lvl.Position, true
+		}
+	}
+
+	return data.Position{}, false
+}
+
 func (tz TerrorZone) AvailableTZs() []area.ID {
 	tz.ctx.RefreshGameData()
 	var availableTZs []area.ID
@@ -715,7 +715,7 @@ function displayLoadedRules(rules, fileName) {
         return `
         <div style="background: #1e1e1e; padding: 12px; margin-bottom: 8px; border-radius: 4px; border-left: 3px solid #4CAF50;">
             <div style="display: flex; justify-content: space-between; align-items: start; margin-bottom: 6px;">
-                <span style="color: #888; font-size: 12px;">Rule ${index + 1}</span>
+                <span style="color: #888; font-size: 12px;">Rule ${index + 1} <span id="coverage-${index}"></span></span>
                 <div style="display: flex; gap: 8px;">
                     <button class="btn btn-secondary" style="padding: 4px 10px; font-size: 12px;" onclick="editRule('${rule.id}')">Edit</button>
                     <button class="btn btn-secondary" style="padding: 4px 10px; font-size: 12px;" onclick="deleteRule('${rule.id}')">Delete</button>
@@ -732,6 +732,50 @@ function displayLoadedRules(rules, fileName) {
 
     // Scroll to the loaded rules section
     section.scrollIntoView({ behavior: 'smooth', block: 'start' });
+
+    loadRuleCoverage(rules, fileName);
+}
+
+// Shows how many items every rule stashed in the last 30 days, based on the droplog
+async function loadRuleCoverage(rules, fileName) {
+    if (!currentPickitPath) {
+        return;
+    }
+
+    try {
+        const response = await fetch(`/api/pickit/coverage?path=${encodeURIComponent(currentPickitPath)}&file=${encodeURIComponent(fileName)}&days=30`);
+        if (!response.ok) {
+            return;
+        }
+        const report = await response.json();
+
+        const byLine = {};
+        (report.rules || []).forEach(cov => byLine[cov.line] = cov);
+
+        rules.forEach((rule, index) => {
+            // Rule IDs are file_lineIndex, lines start at 0
+            const line = parseInt(rule.id.substring(rule.id.lastIndexOf('_') + 1), 10) + 1;
+            const cov = byLine[line];
+            const badge = document.getElementById(`coverage-${index}`);
+            if (!cov || !badge) {
+                return;
+            }
+
+            const parts = [];
+            if (cov.stashed === 0) {
+                parts.push('<span style="color: #ff9800;">never fired</span>');
+            } else {
+                parts.push(`<span style="color: #4CAF50;">${cov.stashed} stashed</span>`);
+            }
+            if (cov.sold > 0 || cov.dropped > 0) {
+                parts.push(`<span style="color: #f44336;">${cov.sold} sold, ${cov.dropped} dropped</span>`);
+            }
+            badge.innerHTML = '&middot; ' + parts.join(' &middot; ');
+            badge.title = cov.lastStashed ? `Last stashed ${new Date(cov.lastStashed).toLocaleString()}` : 'Nothing stashed in the last 30 days';
+        });
+    } catch (error) {
+        console.error('Rule coverage error:', error);
+    }
 }
 
 function closeLoadedRules() {
@@ -0,0 +1,84 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/hectorgimenez/koolo/internal/config"
+)
+
+// configHistory lists the saved versions of a supervisor config, or the koolo config when no supervisor is given.
+// When an id is given the full snapshot, including the config content, is returned instead.
+func (s *HttpServer) configHistory(w http.ResponseWriter, r *http.Request) {
+	supervisor := strings.TrimSpace(r.URL.Query().Get("supervisor"))
+
+	var result any
+	var err error
+	if id := r.URL.Query().Get("id"); id != "" {
+		result, err = config.GetConfigSnapshot(supervisor, id)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+	} else {
+		result, err = config.ConfigHistory(supervisor)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// restoreConfig rolls back a config to a previous snapshot and applies it to the running supervisors.
+func (s *HttpServer) restoreConfig(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	supervisor := strings.TrimSpace(r.URL.Query().Get("supervisor"))
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		http.Error(w, "id is required", http.StatusBadRequest)
+		return
+	}
+
+	if err := config.RestoreConfigSnapshot(supervisor, id); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := s.manager.ReloadConfig(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.logger.Info("Config restored", "supervisor", supervisor, "snapshot", id)
+	w.WriteHeader(http.StatusOK)
+}
+
+// configDiff compares the current configs of two supervisors, field by field.
+func (s *HttpServer) configDiff(w http.ResponseWriter, r *http.Request) {
+	a := strings.TrimSpace(r.URL.Query().Get("a"))
+	b := strings.TrimSpace(r.URL.Query().Get("b"))
+	if a == "" || b == "" {
+		http.Error(w, "a and b supervisors are required", http.StatusBadRequest)
+		return
+	}
+
+	changes, err := config.DiffCharacterConfigs(a, b)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"a":       a,
+		"b":       b,
+		"changes": changes,
+	})
+}
@@ -0,0 +1,30 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/hectorgimenez/koolo/internal/config"
+)
+
+// validateConfig returns the validation errors and warnings of the loaded character configs, all of them unless a
+// supervisor is given. Errors are computed when the configs are loaded, use /api/reload-config to pick up manual changes.
+func (s *HttpServer) validateConfig(w http.ResponseWriter, r *http.Request) {
+	supervisor := strings.TrimSpace(r.URL.Query().Get("supervisor"))
+	result := make(map[string]config.ValidationErrors)
+	for name, cfg := range config.GetCharacters() {
+		if name == "template" || (supervisor != "" && name != supervisor) {
+			continue
+		}
+		result[name] = cfg.Runtime.ValidationErrors
+	}
+
+	if supervisor != "" && len(result) == 0 {
+		http.Error(w, "supervisor not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
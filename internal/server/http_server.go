@@ -36,6 +36,7 @@ import (
 	"github.com/hectorgimenez/koolo/internal/event"
 	"github.com/hectorgimenez/koolo/internal/game"
 	"github.com/hectorgimenez/koolo/internal/remote/droplog"
+	"github.com/hectorgimenez/koolo/internal/remote/metrics"
 	"github.com/hectorgimenez/koolo/internal/utils"
 	"github.com/hectorgimenez/koolo/internal/utils/winproc"
 	"github.com/lxn/win"
@@ -49,6 +50,7 @@ type HttpServer struct {
 	templates *template.Template
 	wsServer  *WebSocketServer
 	pickitAPI *PickitAPI
+	metrics   *metrics.Collector
 }
 
 var (
@@ -185,7 +187,7 @@ func (s *HttpServer) BroadcastStatus() {
 	}
 }
 
-func New(logger *slog.Logger, manager *bot.SupervisorManager) (*HttpServer, error) {
+func New(logger *slog.Logger, manager *bot.SupervisorManager, collector *metrics.Collector) (*HttpServer, error) {
 	var templates *template.Template
 	helperFuncs := template.FuncMap{
 		"isInSlice": func(slice []stat.Resist, value string) bool {
@@ -207,6 +209,9 @@ func New(logger *slog.Logger, manager *bot.SupervisorManager) (*HttpServer, erro
 		"qualityClass": qualityClass,
 		"statIDToText": statIDToText,
 		"contains":     containss,
+		"percent": func(v float64) string {
+			return fmt.Sprintf("%.1f%%", v*100)
+		},
 		"seq": func(start, end int) []int {
 			var result []int
 			for i := start; i <= end; i++ {
@@ -231,6 +236,7 @@ func New(logger *slog.Logger, manager *bot.SupervisorManager) (*HttpServer, erro
 		manager:   manager,
 		templates: templates,
 		pickitAPI: NewPickitAPI(),
+		metrics:   collector,
 	}, nil
 }
 
@@ -578,6 +584,7 @@ func (s *HttpServer) Listen(port int) error {
 	http.HandleFunc("/togglePause", s.togglePause)
 	http.HandleFunc("/debug", s.debugHandler)
 	http.HandleFunc("/debug-data", s.debugData)
+	http.HandleFunc("/api/debug/map", s.debugMap)
 	http.HandleFunc("/drops", s.drops)
 	http.HandleFunc("/all-drops", s.allDrops)
 	http.HandleFunc("/export-drops", s.exportDrops)
@@ -590,6 +597,17 @@ func (s *HttpServer) Listen(port int) error {
 	http.HandleFunc("/api/reload-config", s.reloadConfig)   // New handler
 	http.HandleFunc("/api/companion-join", s.companionJoin) // Companion join handler
 	http.HandleFunc("/reset-muling", s.resetMuling)
+	http.HandleFunc("/api/stats/history", s.statsHistory)
+	http.HandleFunc("/api/scheduler/preview", s.schedulerPreview)
+	http.HandleFunc("/metrics", s.prometheusMetrics)
+	http.HandleFunc("/api/config/history", s.configHistory)
+	http.HandleFunc("/api/config/history/restore", s.restoreConfig)
+	http.HandleFunc("/api/config/diff", s.configDiff)
+	http.HandleFunc("/api/config/validate", s.validateConfig)
+	http.HandleFunc("/analytics/runs", s.runAnalyticsPage)
+	http.HandleFunc("/api/analytics/runs", s.runAnalytics)
+	http.HandleFunc("/stash", s.stashSearchPage)
+	http.HandleFunc("/api/stash/search", s.stashSearch)
 
 	// Pickit Editor routes
 	http.HandleFunc("/pickit-editor", s.pickitEditorPage)
@@ -613,6 +631,9 @@ func (s *HttpServer) Listen(port int) error {
 	http.HandleFunc("/api/pickit/files/rules/append", s.pickitAPI.handleAppendNIPLine)
 	http.HandleFunc("/api/pickit/browse-folder", s.pickitAPI.handleBrowseFolder)
 	http.HandleFunc("/api/pickit/simulate", s.pickitAPI.handleSimulate)
+	http.HandleFunc("/api/pickit/replay", s.pickitAPI.handleReplayDrops)
+	http.HandleFunc("/api/pickit/conflicts", s.pickitAPI.handleDetectConflicts)
+	http.HandleFunc("/api/pickit/coverage", s.pickitAPI.handleRuleCoverage)
 
 	assets, _ := fs.Sub(assetsFS, "assets")
 	http.Handle("/assets/", http.StripPrefix("/assets/", http.FileServer(http.FS(assets))))
@@ -950,22 +971,33 @@ func validateSchedulerData(cfg *config.CharacterCfg) error {
 			return cfg.Scheduler.Days[day].TimeRanges[i].Start.Before(cfg.Scheduler.Days[day].TimeRanges[j].Start)
 		})
 
-		daysOfWeek := []string{"Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday", "Sunday"}
+		daysOfWeek := []string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"}
+
+		// Check for overlapping time ranges, a range ending before its start continues the next day, so it has to be the last one
+		ranges := cfg.Scheduler.Days[day].TimeRanges
+		for i := 0; i < len(ranges); i++ {
+			if ranges[i].End.Equal(ranges[i].Start) {
+				return fmt.Errorf("end time must be different from start time for day %s", daysOfWeek[day])
+			}
 
-		// Check for overlapping time ranges
-		for i := 0; i < len(cfg.Scheduler.Days[day].TimeRanges); i++ {
-			if !cfg.Scheduler.Days[day].TimeRanges[i].End.After(cfg.Scheduler.Days[day].TimeRanges[i].Start) {
-				return fmt.Errorf("end time must be after start time for day %s", daysOfWeek[day])
+			if ranges[i].End.Before(ranges[i].Start) && i < len(ranges)-1 {
+				return fmt.Errorf("overlapping time ranges for day %s", daysOfWeek[day])
 			}
 
 			if i > 0 {
-				if !cfg.Scheduler.Days[day].TimeRanges[i].Start.After(cfg.Scheduler.Days[day].TimeRanges[i-1].End) {
+				if !ranges[i].Start.After(ranges[i-1].End) {
 					return fmt.Errorf("overlapping time ranges for day %s", daysOfWeek[day])
 				}
 			}
 		}
 	}
 
+	for _, o := range cfg.Scheduler.Overrides {
+		if _, err := time.Parse("2006-01-02", o.Date); err != nil {
+			return fmt.Errorf("invalid scheduler override date %s, expected YYYY-MM-DD", o.Date)
+		}
+	}
+
 	return nil
 }
 
@@ -1018,7 +1050,7 @@ func (s *HttpServer) config(w http.ResponseWriter, r *http.Request) {
 		}
 		newConfig.Telegram.ChatID = telegramChatId
 
-		err = config.ValidateAndSaveConfig(newConfig)
+		err = config.ValidateAndSaveConfig(newConfig, "settings")
 		if err != nil {
 			s.templates.ExecuteTemplate(w, "config.gohtml", ConfigData{KooloCfg: &newConfig, ErrorMessage: err.Error()})
 			return
@@ -1055,8 +1087,13 @@ func (s *HttpServer) characterSettings(w http.ResponseWriter, r *http.Request) {
 
 				return
 			}
+			cfg, _ = config.GetCharacter(supervisorName)
 		}
 
+		// Edit a copy, the running supervisor keeps its config until the new one is valid
+		liveCfg := cfg
+		cfg = cfg.Clone()
+
 		cfg.MaxGameLength, _ = strconv.Atoi(r.Form.Get("maxGameLength"))
 		cfg.CharacterName = r.Form.Get("characterName")
 		cfg.CommandLineArgs = r.Form.Get("commandLineArgs")
@@ -1111,6 +1148,9 @@ func (s *HttpServer) characterSettings(w http.ResponseWriter, r *http.Request) {
 			}
 		}
 
+		cfg.Scheduler.DailyLimitMinutes = s.getIntFromForm(r, "schedulerDailyLimitMinutes", 0, 1440, 0)
+		cfg.Scheduler.WeeklyLimitMinutes = s.getIntFromForm(r, "schedulerWeeklyLimitMinutes", 0, 10080, 0)
+
 		// Validate scheduler data
 		err := validateSchedulerData(cfg)
 		if err != nil {
@@ -1155,6 +1195,7 @@ func (s *HttpServer) characterSettings(w http.ResponseWriter, r *http.Request) {
 		} else {
 			cfg.Character.ClearPathDist = 7
 		}
+		cfg.Character.AvoidDangerousMonsters = r.Form.Has("characterAvoidDangerousMonsters")
 
 		// Berserker Barb specific options
 		if cfg.Character.Class == "berserker" {
@@ -1245,6 +1286,10 @@ func (s *HttpServer) characterSettings(w http.ResponseWriter, r *http.Request) {
 		cfg.PacketCasting.UseForTpInteraction = r.Form.Has("packetCastingUseForTpInteraction")
 		cfg.Game.Difficulty = difficulty.Difficulty(r.Form.Get("gameDifficulty"))
 		cfg.Game.RandomizeRuns = r.Form.Has("gameRandomizeRuns")
+		cfg.Game.AdaptiveRuns.Enabled = r.Form.Has("gameAdaptiveRunsEnabled")
+		cfg.Game.AdaptiveRuns.LookbackDays = s.getIntFromForm(r, "gameAdaptiveRunsLookbackDays", 1, 90, 7)
+		cfg.Game.AdaptiveRuns.SkipAfterFailures = s.getIntFromForm(r, "gameAdaptiveRunsSkipAfterFailures", 0, 20, 3)
+		cfg.Game.AdaptiveRuns.SkipForMinutes = s.getIntFromForm(r, "gameAdaptiveRunsSkipForMinutes", 0, 1440, 60)
 
 		// Runs specific config
 		enabledRuns := make([]config.Run, 0)
@@ -1407,7 +1452,13 @@ func (s *HttpServer) characterSettings(w http.ResponseWriter, r *http.Request) {
 		cfg.Muling.MuleProfiles = r.Form["mulingMuleProfiles[]"]
 		cfg.Muling.ReturnTo = r.FormValue("mulingReturnTo")
 
-		config.SaveSupervisorConfig(supervisorName, cfg)
+		if errs := config.ValidateCharacter(supervisorName, cfg, config.GetCharacters()); len(errs.Errors()) > 0 {
+			s.renderCharacterSettings(w, supervisorName, cfg, "Configuration not saved, please fix the following errors", errs)
+			return
+		}
+
+		*liveCfg = *cfg
+		config.SaveSupervisorConfig(supervisorName, liveCfg, "settings")
 		http.Redirect(w, r, "/", http.StatusSeeOther)
 		return
 	}
@@ -1418,6 +1469,17 @@ func (s *HttpServer) characterSettings(w http.ResponseWriter, r *http.Request) {
 		cfg, _ = config.GetCharacter(supervisor)
 	}
 
+	errorMessage := ""
+	if len(cfg.Runtime.ValidationErrors.Errors()) > 0 {
+		errorMessage = "The saved configuration is not valid, the supervisor won't start until it's fixed"
+	} else if len(cfg.Runtime.ValidationErrors) > 0 {
+		errorMessage = "The saved configuration has warnings, the supervisor starts anyway"
+	}
+	s.renderCharacterSettings(w, supervisor, cfg, errorMessage, cfg.Runtime.ValidationErrors)
+}
+
+// renderCharacterSettings renders the settings page for cfg, validation errors are listed with their field path.
+func (s *HttpServer) renderCharacterSettings(w http.ResponseWriter, supervisor string, cfg *config.CharacterCfg, errorMessage string, validationErrors config.ValidationErrors) {
 	enabledRuns := make([]string, 0)
 	// Let's iterate cfg.Game.Runs to preserve current order
 	for _, run := range cfg.Game.Runs {
@@ -1463,6 +1525,8 @@ func (s *HttpServer) characterSettings(w http.ResponseWriter, r *http.Request) {
 	sort.Strings(farmerProfiles)
 
 	s.templates.ExecuteTemplate(w, "character_settings.gohtml", CharacterSettings{
+		ErrorMessage:       errorMessage,
+		ValidationErrors:   validationErrors,
 		Supervisor:         supervisor,
 		Config:             cfg,
 		DayNames:           dayNames,
@@ -1538,7 +1602,7 @@ func (s *HttpServer) resetMuling(w http.ResponseWriter, r *http.Request) {
 	s.logger.Info("Resetting muling index for character", "character", characterName)
 	cfg.MulingState.CurrentMuleIndex = 0
 
-	err := config.SaveSupervisorConfig(characterName, cfg)
+	err := config.SaveSupervisorConfig(characterName, cfg, "reset muling index")
 	if err != nil {
 		http.Error(w, "Failed to save updated config", http.StatusInternalServerError)
 		return
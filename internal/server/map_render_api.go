@@ -0,0 +1,122 @@
+package server
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/hectorgimenez/koolo/internal/config"
+	"github.com/hectorgimenez/koolo/internal/game"
+	"github.com/hectorgimenez/koolo/internal/pather/render"
+)
+
+// debugMap draws the current area of a running supervisor (characterName) with the last path it followed, or a frame
+// of a game recording (recording, the file name in the recordings folder, and frame). The format is png by default or
+// svg, scale, radius and to (x,y) work like in the koolo map render command.
+func (s *HttpServer) debugMap(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	format := q.Get("format")
+	if format == "" {
+		format = render.FormatPNG
+	}
+	if format != render.FormatPNG && format != render.FormatSVG {
+		http.Error(w, "format must be png or svg", http.StatusBadRequest)
+		return
+	}
+	opts := render.Options{Scale: 2}
+	if v := q.Get("scale"); v != "" {
+		scale, err := strconv.Atoi(v)
+		if err != nil || scale < 1 || scale > render.MaxScale {
+			http.Error(w, fmt.Sprintf("scale must be a number between 1 and %d", render.MaxScale), http.StatusBadRequest)
+			return
+		}
+		opts.Scale = scale
+	}
+	if v := q.Get("radius"); v != "" {
+		radius, err := strconv.Atoi(v)
+		if err != nil || radius < 0 {
+			http.Error(w, "radius must be a positive number", http.StatusBadRequest)
+			return
+		}
+		opts.Radius = radius
+	}
+
+	var scene render.Scene
+	var err error
+	if name := q.Get("recording"); name != "" {
+		// Only the files of the recordings folder can be read
+		if filepath.Base(name) != name || !strings.HasSuffix(name, ".rec.gz") {
+			http.Error(w, "invalid recording name", http.StatusBadRequest)
+			return
+		}
+		base := config.Koolo.LogSaveDirectory
+		if base == "" {
+			base = "logs"
+		}
+		rec, loadErr := game.LoadRecording(filepath.Join(base, "recordings", name))
+		if loadErr != nil {
+			http.Error(w, loadErr.Error(), http.StatusNotFound)
+			return
+		}
+		frame := -1
+		if f := q.Get("frame"); f != "" {
+			if frame, err = strconv.Atoi(f); err != nil {
+				http.Error(w, "frame must be a number", http.StatusBadRequest)
+				return
+			}
+		}
+		scene, err = render.FromRecording(rec, frame)
+	} else {
+		characterName := q.Get("characterName")
+		if characterName == "" {
+			http.Error(w, "characterName or recording is required", http.StatusBadRequest)
+			return
+		}
+		ctx := s.manager.GetContext(characterName)
+		if ctx == nil || ctx.Data == nil {
+			http.Error(w, "supervisor not running", http.StatusNotFound)
+			return
+		}
+		scene, err = render.FromData(*ctx.Data)
+		scene.Path = ctx.PathFinder.LastPath()
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	if to := q.Get("to"); to != "" {
+		dest, err := render.ParsePosition(to)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		scene.PlanPath(dest)
+	}
+
+	// The picture is drawn in memory first, so the size limit is reported as an error instead of a broken image
+	var buf bytes.Buffer
+	contentType := "image/png"
+	if format == render.FormatSVG {
+		contentType = "image/svg+xml"
+		err = render.SVG(&buf, scene, opts)
+	} else {
+		err = render.PNG(&buf, scene, opts)
+	}
+	if errors.Is(err, render.ErrTooLarge) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Write(buf.Bytes())
+}
@@ -0,0 +1,70 @@
+package server
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/hectorgimenez/koolo/internal/bot"
+	"github.com/hectorgimenez/koolo/internal/event"
+	"github.com/hectorgimenez/koolo/internal/remote/metrics"
+)
+
+var supervisorStatuses = []bot.SupervisorStatus{bot.NotStarted, bot.Starting, bot.InGame, bot.Paused, bot.Crashed}
+
+// prometheusMetrics exposes the event counters plus the live supervisor gauges in the Prometheus text format.
+func (s *HttpServer) prometheusMetrics(w http.ResponseWriter, r *http.Request) {
+	now := time.Now()
+	gauges := make([]metrics.Sample, 0)
+	for _, sup := range s.manager.AvailableSupervisors() {
+		stats := s.manager.GetSupervisorStats(sup)
+		label := metrics.Label{Name: "supervisor", Value: sup}
+
+		status := stats.SupervisorStatus
+		if status == "" {
+			status = bot.NotStarted
+		}
+		for _, st := range supervisorStatuses {
+			value := 0.0
+			if st == status {
+				value = 1
+			}
+			gauges = append(gauges, metrics.Sample{
+				Name:   "koolo_supervisor_status",
+				Help:   "Current supervisor status, 1 for the active one",
+				Labels: []metrics.Label{label, {Name: "status", Value: string(st)}},
+				Value:  value,
+			})
+		}
+
+		if stats.SupervisorStatus == bot.NotStarted || stats.SupervisorStatus == "" {
+			continue
+		}
+
+		gauges = append(gauges,
+			metrics.Sample{Name: "koolo_supervisor_uptime_seconds", Help: "Seconds since the supervisor was started", Labels: []metrics.Label{label}, Value: now.Sub(stats.StartedAt).Seconds()},
+			metrics.Sample{Name: "koolo_supervisor_games", Help: "Games played since the supervisor was started", Labels: []metrics.Label{label}, Value: float64(len(stats.Games))},
+		)
+		if stats.UI.Level > 0 {
+			gauges = append(gauges,
+				metrics.Sample{Name: "koolo_character_level", Help: "Current character level", Labels: []metrics.Label{label}, Value: float64(stats.UI.Level)},
+				metrics.Sample{Name: "koolo_character_experience", Help: "Current character experience", Labels: []metrics.Label{label}, Value: float64(stats.UI.Experience)},
+			)
+		}
+	}
+
+	for _, st := range event.Stats() {
+		label := metrics.Label{Name: "subscriber", Value: st.Name}
+		gauges = append(gauges,
+			metrics.Sample{Name: "koolo_event_queue_length", Help: "Events waiting to be handled by the subscriber", Labels: []metrics.Label{label}, Value: float64(st.Queued)},
+			metrics.Sample{Name: "koolo_event_delivered_total", Help: "Events handled by the subscriber", Labels: []metrics.Label{label}, Value: float64(st.Delivered), Counter: true},
+			metrics.Sample{Name: "koolo_event_dropped_total", Help: "Events dropped because the subscriber queue was full", Labels: []metrics.Label{label}, Value: float64(st.Dropped), Counter: true},
+			metrics.Sample{Name: "koolo_event_errors_total", Help: "Events the subscriber failed to handle", Labels: []metrics.Label{label}, Value: float64(st.Errors), Counter: true},
+			metrics.Sample{Name: "koolo_event_timeouts_total", Help: "Events the subscriber didn't handle in time", Labels: []metrics.Label{label}, Value: float64(st.Timeouts), Counter: true},
+		)
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	if err := s.metrics.Write(w, gauges); err != nil {
+		s.logger.Error("Error writing metrics", "error", err)
+	}
+}
@@ -8,9 +8,16 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/nip"
+	"github.com/hectorgimenez/koolo/internal/config"
 	"github.com/hectorgimenez/koolo/internal/pickit"
+	"github.com/hectorgimenez/koolo/internal/remote/droplog"
 	"github.com/hectorgimenez/koolo/internal/utils"
 )
 
@@ -57,8 +64,10 @@ func (api *PickitAPI) RegisterRoutes(mux *http.ServeMux) {
 	// Utility endpoints
 	mux.HandleFunc("/api/pickit/stats", api.handleGetStats)
 	mux.HandleFunc("/api/pickit/simulate", api.handleSimulate)
+	mux.HandleFunc("/api/pickit/replay", api.handleReplayDrops)
 	mux.HandleFunc("/api/pickit/suggestions", api.handleGetSuggestions)
 	mux.HandleFunc("/api/pickit/conflicts", api.handleDetectConflicts)
+	mux.HandleFunc("/api/pickit/coverage", api.handleRuleCoverage)
 }
 
 // handleGetItems returns all items from the database
@@ -437,70 +446,197 @@ func (api *PickitAPI) handleGetStats(w http.ResponseWriter, r *http.Request) {
 	api.sendJSON(w, response)
 }
 
-// handleSimulate simulates a rule against test data
+// handleSimulate evaluates synthetic items through the same NIP engine the bot uses. Rules come from a character
+// config, raw NIP lines or an editor rule. When items are given, the full evaluation of every rule is returned for
+// each one, otherwise the whole item database is checked and a SimulationResult is returned.
 func (api *PickitAPI) handleSimulate(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	var rule pickit.PickitRule
-	if err := json.NewDecoder(r.Body).Decode(&rule); err != nil {
+	var req struct {
+		Character string                 `json:"character"`
+		NIPLines  []string               `json:"nipLines"`
+		Rule      *pickit.PickitRule     `json:"rule"`
+		Items     []pickit.SimulatedItem `json:"items"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
 
-	// Generate simulation based on rule
-	result := pickit.SimulationResult{
-		RuleID:      rule.ID,
-		MatchCount:  0,
-		Matches:     []pickit.ItemMatch{},
-		Performance: "Good",
-		Suggestions: []string{},
+	rules := nip.Rules{}
+	ruleID := ""
+	if req.Character != "" {
+		cfg, found := config.GetCharacter(req.Character)
+		if !found || cfg == nil {
+			api.sendError(w, fmt.Sprintf("character %s not found", req.Character), http.StatusNotFound)
+			return
+		}
+		rules = append(rules, cfg.Runtime.Rules...)
 	}
 
-	// Simulate by finding matching items in database
-	_, err := api.builder.GenerateNIP(&rule)
-	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to generate NIP: %v", err), http.StatusBadRequest)
+	for i, line := range req.NIPLines {
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(strings.TrimSpace(line), "//") {
+			continue
+		}
+		rule, err := nip.NewRule(line, "simulation", i+1)
+		if err != nil {
+			api.sendError(w, fmt.Sprintf("line %d: %v", i+1, err), http.StatusBadRequest)
+			return
+		}
+		rules = append(rules, rule)
+	}
+
+	if req.Rule != nil {
+		nipLine, err := api.builder.GenerateNIP(req.Rule)
+		if err != nil {
+			api.sendError(w, fmt.Sprintf("Failed to generate NIP: %v", err), http.StatusBadRequest)
+			return
+		}
+		fileName := req.Rule.FileName
+		if fileName == "" {
+			fileName = "simulation"
+		}
+		rule, err := nip.NewRule(nipLine, fileName, 1)
+		if err != nil {
+			api.sendError(w, fmt.Sprintf("Invalid rule: %v", err), http.StatusBadRequest)
+			return
+		}
+		rules = append(rules, rule)
+		ruleID = req.Rule.ID
+	}
+
+	if len(rules) == 0 {
+		api.sendError(w, "No rules to simulate, provide a character, nipLines or a rule", http.StatusBadRequest)
 		return
 	}
 
-	// Find items that match the rule criteria
-	for _, item := range pickit.ItemDatabase {
-		// Check name match
-		nameMatch := false
-		for _, cond := range rule.LeftConditions {
-			if cond.Property == "name" && cond.Operator == "==" {
-				if item.ID == cond.Value || item.InternalName == cond.Value {
-					nameMatch = true
-					break
-				}
-			}
+	if len(req.Items) == 0 {
+		api.sendJSON(w, pickit.SimulateDatabase(ruleID, rules))
+		return
+	}
+
+	results := make([]pickit.ItemSimulation, 0, len(req.Items))
+	for _, itm := range req.Items {
+		sim, err := pickit.SimulateItem(rules, itm)
+		if err != nil {
+			api.sendError(w, err.Error(), http.StatusBadRequest)
+			return
 		}
+		results = append(results, sim)
+	}
 
-		if nameMatch {
-			result.MatchCount++
-			result.Matches = append(result.Matches, pickit.ItemMatch{
-				ItemName:  item.Name,
-				ImageIcon: item.ImageIcon,
-				Matched:   true,
-				Reason:    "Name matches rule criteria",
-				Stats:     make(map[string]interface{}),
-			})
+	api.sendJSON(w, map[string]interface{}{
+		"results": results,
+	})
+}
+
+// dropReplayEntry is a droplog item whose pickit result changes with the candidate rules
+type dropReplayEntry struct {
+	Time       time.Time `json:"time"`
+	Supervisor string    `json:"supervisor"`
+	ItemName   string    `json:"itemName"`
+	Quality    string    `json:"quality"`
+	Ethereal   bool      `json:"ethereal"`
+	Stats      []string  `json:"stats"`
+	StashedBy  string    `json:"stashedBy"` // Rule recorded when the item was stashed
+	Before     string    `json:"before"`    // file:line of the current rule keeping the item, if any
+	After      string    `json:"after"`     // file:line of the candidate rule keeping the item, if any
+}
+
+// dropReplayDiff is the difference between the current and the candidate rules over the droplog
+type dropReplayDiff struct {
+	Total      int               `json:"total"`
+	Unchanged  int               `json:"unchanged"`
+	NowDropped []dropReplayEntry `json:"nowDropped"` // Kept by the current rules, not by the candidate ones
+	NowKept    []dropReplayEntry `json:"nowKept"`    // Kept by the candidate rules, not by the current ones
+}
+
+// handleReplayDrops runs the historical droplog items of a character through a candidate set of NIP rules and
+// compares the result with the rules currently loaded, so a pickit change can be checked before being saved.
+// Candidate rules are the current ones with the given files replaced, or only the given files when replaceAll is set.
+func (api *PickitAPI) handleReplayDrops(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		api.sendError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		Character     string            `json:"character"`
+		Files         map[string]string `json:"files"` // File name => NIP content
+		ReplaceAll    bool              `json:"replaceAll"`
+		AllCharacters bool              `json:"allCharacters"` // Replay drops from every character, not only this one
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.sendError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	cfg, found := config.GetCharacter(req.Character)
+	if !found || cfg == nil {
+		api.sendError(w, fmt.Sprintf("character %s not found", req.Character), http.StatusNotFound)
+		return
+	}
+	current := cfg.Runtime.Rules
+
+	candidate, err := pickit.CandidateRules(current, req.Files, req.ReplaceAll)
+	if err != nil {
+		api.sendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	records, err := droplog.ReadAll(droplogDir())
+	if err != nil {
+		api.sendError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var replayed []droplog.Record
+	var items []data.Item
+	for _, rec := range records {
+		if req.AllCharacters || strings.EqualFold(rec.Supervisor, req.Character) {
+			replayed = append(replayed, rec)
+			items = append(items, rec.Drop.Item)
 		}
 	}
+	replay := pickit.ReplayItems(current, candidate, items)
 
-	// Add suggestions based on match count
-	if result.MatchCount == 0 {
-		result.Suggestions = append(result.Suggestions, "No items matched. Check your item name and conditions.")
-	} else if result.MatchCount > 10 {
-		result.Suggestions = append(result.Suggestions, "Rule matches many items. Consider adding quality or stat filters.")
+	diff := dropReplayDiff{
+		Total:      replay.Total,
+		Unchanged:  replay.Unchanged,
+		NowDropped: make([]dropReplayEntry, 0, len(replay.NowDropped)),
+		NowKept:    make([]dropReplayEntry, 0, len(replay.NowKept)),
+	}
+	for _, c := range replay.NowDropped {
+		diff.NowDropped = append(diff.NowDropped, newDropReplayEntry(replayed[c.Index], c))
+	}
+	for _, c := range replay.NowKept {
+		diff.NowKept = append(diff.NowKept, newDropReplayEntry(replayed[c.Index], c))
 	}
 
-	api.sendJSON(w, result)
+	api.sendJSON(w, diff)
+}
 
-	api.sendJSON(w, result)
+func newDropReplayEntry(rec droplog.Record, c pickit.ReplayChange) dropReplayEntry {
+	itm := rec.Drop.Item
+	entry := dropReplayEntry{
+		Time:       rec.Time,
+		Supervisor: rec.Supervisor,
+		ItemName:   string(itm.Name),
+		Quality:    itm.Quality.ToString(),
+		Ethereal:   itm.Ethereal,
+		Stats:      statsToStrings(itm.Stats),
+		StashedBy:  rec.Drop.Rule,
+		Before:     c.Before,
+		After:      c.After,
+	}
+	if itm.IdentifiedName != "" {
+		entry.ItemName = itm.IdentifiedName
+	}
+
+	return entry
 }
 
 // handleGetSuggestions returns auto-suggestions for a rule
@@ -520,21 +656,192 @@ func (api *PickitAPI) handleGetSuggestions(w http.ResponseWriter, r *http.Reques
 	api.sendJSON(w, suggestions)
 }
 
-// handleDetectConflicts detects conflicts between rules
+// handleRuleCoverage reports how many items every NIP rule kept over the last days (30 by default, 0 for the whole
+// history), which rules never fired and which ones keep items that are sold or dropped later. Rules are the ones loaded
+// for a character, or the files of a pickit folder (path) matched with the drops of every character. The report can be
+// limited to a single file.
+func (api *PickitAPI) handleRuleCoverage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		api.sendError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	q := r.URL.Query()
+	days := 30
+	if d := q.Get("days"); d != "" {
+		n, err := strconv.Atoi(d)
+		if err != nil || n < 0 {
+			api.sendError(w, "days must be a positive number", http.StatusBadRequest)
+			return
+		}
+		days = n
+	}
+
+	character := q.Get("character")
+	var rules nip.Rules
+	switch {
+	case character != "":
+		cfg, found := config.GetCharacter(character)
+		if !found || cfg == nil {
+			api.sendError(w, fmt.Sprintf("character %s not found", character), http.StatusNotFound)
+			return
+		}
+		rules = cfg.Runtime.Rules
+	case q.Get("path") != "":
+		var err error
+		if rules, err = readPickitDir(q.Get("path")); err != nil {
+			api.sendError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	default:
+		api.sendError(w, "Either 'path' or 'character' parameter required", http.StatusBadRequest)
+		return
+	}
+
+	stashed, err := droplog.ReadAll(droplogDir())
+	if err != nil {
+		api.sendError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	disposed, err := droplog.ReadDisposed(droplogDir())
+	if err != nil {
+		api.sendError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if character != "" {
+		stashed = recordsOf(stashed, character)
+		disposed = recordsOf(disposed, character)
+	}
+
+	from := time.Time{}
+	if days > 0 {
+		from = time.Now().AddDate(0, 0, -days)
+	}
+	report := droplog.Coverage(rules, stashed, disposed, from, time.Time{})
+
+	if file := q.Get("file"); file != "" {
+		inFile := func(location string) bool {
+			return strings.HasPrefix(strings.ToLower(location), strings.ToLower(file)+":")
+		}
+		filtered := make([]droplog.RuleCoverage, 0)
+		for _, cov := range report.Rules {
+			if inFile(cov.Location()) {
+				filtered = append(filtered, cov)
+			}
+		}
+		report.Rules = filtered
+		report.NeverFired = slices.DeleteFunc(report.NeverFired, func(l string) bool { return !inFile(l) })
+		report.DisposedRules = slices.DeleteFunc(report.DisposedRules, func(l string) bool { return !inFile(l) })
+	}
+
+	api.sendJSON(w, report)
+}
+
+func recordsOf(records []droplog.Record, supervisor string) []droplog.Record {
+	out := make([]droplog.Record, 0, len(records))
+	for _, rec := range records {
+		if strings.EqualFold(rec.Supervisor, supervisor) {
+			out = append(out, rec)
+		}
+	}
+
+	return out
+}
+
+// readPickitDir parses every .nip file in the folder, in the same order nip.ReadDir loads them
+func readPickitDir(dir string) (nip.Rules, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	rules := nip.Rules{}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(strings.ToLower(entry.Name()), ".nip") {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		fileRules, err := pickit.ParseNIPContent(string(content), entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, fileRules...)
+	}
+
+	return rules, nil
+}
+
+func droplogDir() string {
+	base := config.Koolo.LogSaveDirectory
+	if base == "" {
+		base = "logs"
+	}
+
+	return filepath.Join(base, "droplogs")
+}
+
+// handleDetectConflicts detects conflicts between rules. A JSON array of editor rules is only checked for duplicated
+// items, otherwise the NIP rules of a character (?character= or {"character": ...}) are analyzed, optionally with
+// edited files replacing the ones loaded, and every finding is reported with its file:line.
 func (api *PickitAPI) handleDetectConflicts(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
+	var req struct {
+		Character string            `json:"character"`
+		Files     map[string]string `json:"files"` // File name => NIP content
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		req.Character = r.URL.Query().Get("character")
+	case http.MethodPost:
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if trimmed := strings.TrimSpace(string(body)); strings.HasPrefix(trimmed, "[") {
+			var rules []pickit.PickitRule
+			if err := json.Unmarshal(body, &rules); err != nil {
+				http.Error(w, "Invalid request body", http.StatusBadRequest)
+				return
+			}
+			api.sendJSON(w, pickit.DetectConflicts(rules))
+			return
+		}
+		if err := json.Unmarshal(body, &req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+	default:
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	var rules []pickit.PickitRule
-	if err := json.NewDecoder(r.Body).Decode(&rules); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+	current := nip.Rules{}
+	if req.Character != "" {
+		cfg, found := config.GetCharacter(req.Character)
+		if !found || cfg == nil {
+			api.sendError(w, fmt.Sprintf("character %s not found", req.Character), http.StatusNotFound)
+			return
+		}
+		current = cfg.Runtime.Rules
+	} else if len(req.Files) == 0 {
+		api.sendError(w, "Provide a character or the NIP files to analyze", http.StatusBadRequest)
 		return
 	}
 
-	conflicts := pickit.DetectConflicts(rules)
-	api.sendJSON(w, conflicts)
+	rules, err := pickit.CandidateRules(current, req.Files, false)
+	if err != nil {
+		api.sendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	api.sendJSON(w, map[string]interface{}{
+		"rules":    len(rules),
+		"findings": pickit.AnalyzeRules(rules),
+	})
 }
 
 // Helper functions
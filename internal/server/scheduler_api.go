@@ -0,0 +1,42 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hectorgimenez/koolo/internal/bot"
+)
+
+// schedulerPreview returns the computed schedule for the next days (7 by default), with date overrides, groups and
+// play time budgets already applied. It can be filtered by supervisor.
+func (s *HttpServer) schedulerPreview(w http.ResponseWriter, r *http.Request) {
+	days := 7
+	if d := r.URL.Query().Get("days"); d != "" {
+		parsed, err := strconv.Atoi(d)
+		if err != nil || parsed < 1 || parsed > 31 {
+			http.Error(w, "days must be a number between 1 and 31", http.StatusBadRequest)
+			return
+		}
+		days = parsed
+	}
+
+	timelines := bot.PreviewSchedules(time.Now(), days, bot.HistoryPlayTime)
+	if sup := strings.TrimSpace(r.URL.Query().Get("supervisor")); sup != "" {
+		filtered := make([]bot.SupervisorTimeline, 0, 1)
+		for _, tl := range timelines {
+			if strings.EqualFold(tl.Supervisor, sup) {
+				filtered = append(filtered, tl)
+			}
+		}
+		timelines = filtered
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"days":      days,
+		"timelines": timelines,
+	})
+}
@@ -0,0 +1,76 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/hectorgimenez/koolo/internal/remote/stashindex"
+)
+
+func stashQueryFromRequest(r *http.Request) stashindex.Query {
+	q := r.URL.Query()
+	return stashindex.Query{
+		Supervisor: strings.TrimSpace(q.Get("supervisor")),
+		Name:       strings.TrimSpace(q.Get("name")),
+		Quality:    strings.TrimSpace(q.Get("quality")),
+		Stat:       strings.TrimSpace(q.Get("stat")),
+		NIP:        strings.TrimSpace(q.Get("nip")),
+	}
+}
+
+// stashSearch looks for items in the last stash snapshot of every character.
+func (s *HttpServer) stashSearch(w http.ResponseWriter, r *http.Request) {
+	snapshots, err := stashindex.ReadAll(stashindex.Dir())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	results, err := stashindex.Search(snapshots, stashQueryFromRequest(r))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"snapshots": stashSnapshotInfos(snapshots),
+		"total":     len(results),
+		"results":   results,
+	})
+}
+
+func (s *HttpServer) stashSearchPage(w http.ResponseWriter, r *http.Request) {
+	q := stashQueryFromRequest(r)
+	data := StashSearchData{Query: q}
+
+	snapshots, err := stashindex.ReadAll(stashindex.Dir())
+	if err != nil {
+		data.ErrorMessage = err.Error()
+		s.templates.ExecuteTemplate(w, "stash_search.gohtml", data)
+		return
+	}
+	data.Snapshots = stashSnapshotInfos(snapshots)
+
+	data.Results, err = stashindex.Search(snapshots, q)
+	if err != nil {
+		data.ErrorMessage = err.Error()
+	}
+
+	s.templates.ExecuteTemplate(w, "stash_search.gohtml", data)
+}
+
+func stashSnapshotInfos(snapshots []stashindex.Snapshot) []StashSnapshotInfo {
+	infos := make([]StashSnapshotInfo, 0, len(snapshots))
+	for _, snap := range snapshots {
+		infos = append(infos, StashSnapshotInfo{
+			Supervisor: snap.Supervisor,
+			Character:  snap.Character,
+			TakenAt:    snap.TakenAt,
+			Items:      len(snap.Items),
+		})
+	}
+
+	return infos
+}
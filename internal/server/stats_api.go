@@ -0,0 +1,117 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hectorgimenez/koolo/internal/remote/statslog"
+)
+
+// statsFilterFromRequest builds a statslog.Filter from the supervisor, profile, run, from and to query params.
+// Dates are expected as YYYY-MM-DD and "to" is inclusive.
+func statsFilterFromRequest(r *http.Request) (statslog.Filter, error) {
+	q := r.URL.Query()
+	f := statslog.Filter{
+		Supervisor: strings.TrimSpace(q.Get("supervisor")),
+		Profile:    strings.TrimSpace(q.Get("profile")),
+		Run:        strings.TrimSpace(q.Get("run")),
+	}
+
+	if from := q.Get("from"); from != "" {
+		t, err := time.ParseInLocation("2006-01-02", from, time.Local)
+		if err != nil {
+			return f, fmt.Errorf("invalid from date: %w", err)
+		}
+		f.From = t
+	}
+	if to := q.Get("to"); to != "" {
+		t, err := time.ParseInLocation("2006-01-02", to, time.Local)
+		if err != nil {
+			return f, fmt.Errorf("invalid to date: %w", err)
+		}
+		f.To = t.AddDate(0, 0, 1)
+	}
+
+	return f, nil
+}
+
+// statsHistory returns persisted game history. When groupBy is day, run or profile an aggregated summary is
+// returned, otherwise the raw game records.
+func (s *HttpServer) statsHistory(w http.ResponseWriter, r *http.Request) {
+	f, err := statsFilterFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	records, err := statslog.ReadAll(statslog.Dir(), f)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	groupBy := statslog.GroupBy(r.URL.Query().Get("groupBy"))
+	switch groupBy {
+	case statslog.GroupByDay, statslog.GroupByRun, statslog.GroupByProfile:
+		json.NewEncoder(w).Encode(map[string]any{
+			"groupBy":   groupBy,
+			"summaries": statslog.Summarize(records, groupBy),
+		})
+	case "":
+		json.NewEncoder(w).Encode(map[string]any{
+			"total":   len(records),
+			"records": records,
+		})
+	default:
+		http.Error(w, "groupBy must be one of: day, run, profile", http.StatusBadRequest)
+	}
+}
+
+// runAnalytics returns per run efficiency metrics ranked from the most to the least productive run.
+func (s *HttpServer) runAnalytics(w http.ResponseWriter, r *http.Request) {
+	f, err := statsFilterFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	records, err := statslog.ReadAll(statslog.Dir(), f)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"games": len(records),
+		"runs":  statslog.AnalyzeRuns(records),
+	})
+}
+
+func (s *HttpServer) runAnalyticsPage(w http.ResponseWriter, r *http.Request) {
+	f, err := statsFilterFromRequest(r)
+	if err != nil {
+		s.templates.ExecuteTemplate(w, "run_analytics.gohtml", RunAnalyticsData{ErrorMessage: err.Error()})
+		return
+	}
+
+	records, err := statslog.ReadAll(statslog.Dir(), f)
+	if err != nil {
+		s.templates.ExecuteTemplate(w, "run_analytics.gohtml", RunAnalyticsData{ErrorMessage: err.Error()})
+		return
+	}
+
+	q := r.URL.Query()
+	s.templates.ExecuteTemplate(w, "run_analytics.gohtml", RunAnalyticsData{
+		Supervisor: q.Get("supervisor"),
+		From:       q.Get("from"),
+		To:         q.Get("to"),
+		Games:      len(records),
+		Runs:       statslog.AnalyzeRuns(records),
+	})
+}
@@ -1,9 +1,13 @@
 package server
 
 import (
+	"time"
+
 	"github.com/hectorgimenez/d2go/pkg/data"
 	"github.com/hectorgimenez/koolo/internal/bot"
 	"github.com/hectorgimenez/koolo/internal/config"
+	"github.com/hectorgimenez/koolo/internal/remote/stashindex"
+	"github.com/hectorgimenez/koolo/internal/remote/statslog"
 )
 
 type IndexData struct {
@@ -37,6 +41,7 @@ type AllDropRecord struct {
 
 type CharacterSettings struct {
 	ErrorMessage       string
+	ValidationErrors   config.ValidationErrors
 	Supervisor         string
 	Config             *config.CharacterCfg
 	DayNames           []string
@@ -57,3 +62,28 @@ type ConfigData struct {
 type AutoSettings struct {
 	ErrorMessage string
 }
+
+// RunAnalyticsData is used by the run analytics page.
+type RunAnalyticsData struct {
+	ErrorMessage string
+	Supervisor   string
+	From         string
+	To           string
+	Games        int
+	Runs         []statslog.RunAnalytics
+}
+
+// StashSearchData is used by the stash search page.
+type StashSearchData struct {
+	ErrorMessage string
+	Query        stashindex.Query
+	Snapshots    []StashSnapshotInfo
+	Results      []stashindex.Result
+}
+
+type StashSnapshotInfo struct {
+	Supervisor string    `json:"supervisor"`
+	Character  string    `json:"character"`
+	TakenAt    time.Time `json:"takenAt"`
+	Items      int       `json:"items"`
+}
@@ -3,6 +3,7 @@ package town
 import (
 	"fmt"
 	"slices"
+	"strconv"
 	"time"
 
 	"github.com/hectorgimenez/d2go/pkg/data"
@@ -10,6 +11,7 @@ import (
 	"github.com/hectorgimenez/d2go/pkg/data/stat"
 	"github.com/hectorgimenez/d2go/pkg/nip"
 	"github.com/hectorgimenez/koolo/internal/context"
+	"github.com/hectorgimenez/koolo/internal/event"
 	"github.com/hectorgimenez/koolo/internal/game"
 	"github.com/hectorgimenez/koolo/internal/ui"
 )
@@ -334,9 +336,39 @@ func SellJunk(lockConfig ...[][]int) {
 	// Existing logic to sell other junk items, now with lockConfig support
 	for _, i := range ItemsToBeSold(lockConfig...) {
 		SellItem(i)
+		notifyPickedUpItemSold(i)
 	}
 }
 
+// notifyPickedUpItemSold sends an ItemDisposedEvent when the sold item was picked up because of a pickit rule and it's
+// no longer in the inventory. Rules with stats match any unidentified item with the right base properties, so the item
+// is evaluated as unidentified to find the rule that made the bot pick it up.
+func notifyPickedUpItemSold(i data.Item) {
+	if i.IsPotion() {
+		return
+	}
+
+	ctx := context.Get()
+	unidentified := i
+	unidentified.Identified = false
+	rule, res := ctx.Data.CharacterCfg.Runtime.Rules.EvaluateAllIgnoreTiers(unidentified)
+	if res == nip.RuleResultNoMatch {
+		return
+	}
+
+	// The sale is only reported once the item is gone, the ctrl-click doesn't always sell it
+	ctx.RefreshGameData()
+	for _, it := range ctx.Data.Inventory.ByLocation(item.LocationInventory) {
+		if it.UnitID == i.UnitID {
+			ctx.Logger.Debug(fmt.Sprintf("Failed to sell item %s (UnitID: %d), still in inventory.", i.Name, i.UnitID))
+			return
+		}
+	}
+
+	drop := data.Drop{Item: i, Rule: rule.RawLine, RuleFile: rule.Filename + ":" + strconv.Itoa(rule.LineNumber)}
+	event.Send(event.ItemDisposed(event.Text(ctx.Name, ""), drop, event.DisposalSold))
+}
+
 // SellItem sells a single item by Control-Clicking it.
 func SellItem(i data.Item) {
 	ctx := context.Get()
@@ -0,0 +1,23 @@
+//go:build !windows
+
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// Stubs for the Windows helpers, so the offline tools (config and pickit checks) can be built on other platforms.
+
+func HasAdminPermission() bool {
+	return os.Geteuid() == 0
+}
+
+func ShowDialog(title, message string) {
+	fmt.Fprintf(os.Stderr, "%s: %s\n", title, message)
+}
+
+func BrowseForFolder(title string) (string, error) {
+	return "", errors.New("folder selection is only supported on Windows")
+}